@@ -0,0 +1,45 @@
+package db
+
+import (
+	"embed"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/config"
+
+	migrate "github.com/rubenv/sql-migrate"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+//go:embed migrations/sqlite/*.sql
+var sqliteMigrations embed.FS
+
+// NewDatabase connects to the database of the configured driver.
+func NewDatabase() *gorm.DB {
+	switch driver := config.DatabaseDriver(); driver {
+	case DriverPostgres:
+		return NewPostgres()
+	case DriverSQLite:
+		return NewSQLite()
+	default:
+		logrus.Fatalf("Unknown database driver: %s", driver)
+		return nil
+	}
+}
+
+// Migrations returns the migrations of the configured driver together with
+// the sql-migrate dialect to run them with.
+func Migrations() (migrate.MigrationSource, string) {
+	if config.DatabaseDriver() == DriverSQLite {
+		return migrate.EmbedFileSystemMigrationSource{
+			FileSystem: sqliteMigrations,
+			Root:       "migrations/sqlite",
+		}, "sqlite3"
+	}
+
+	return &migrate.FileMigrationSource{Dir: "./db/migrations"}, "postgres"
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+const slowQueryThreshold = 200 * time.Millisecond
+
+// queryLogger writes gorm's logs through the shared logrus logger, so
+// queries run for a request carry its request_id. Bind values are left out
+// of the SQL because they include password hashes and tokens.
+type queryLogger struct {
+	level gormlogger.LogLevel
+}
+
+func newQueryLogger() gormlogger.Interface {
+	return queryLogger{level: gormlogger.Warn}
+}
+
+func (l queryLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	l.level = level
+	return l
+}
+
+func (l queryLogger) Info(ctx context.Context, msg string, data ...any) {
+	if l.level >= gormlogger.Info {
+		logrus.WithContext(ctx).Infof(msg, data...)
+	}
+}
+
+func (l queryLogger) Warn(ctx context.Context, msg string, data ...any) {
+	if l.level >= gormlogger.Warn {
+		logrus.WithContext(ctx).Warnf(msg, data...)
+	}
+}
+
+func (l queryLogger) Error(ctx context.Context, msg string, data ...any) {
+	if l.level >= gormlogger.Error {
+		logrus.WithContext(ctx).Errorf(msg, data...)
+	}
+}
+
+func (l queryLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	log := func() *logrus.Entry {
+		sql, rows := fc()
+		return logrus.WithContext(ctx).WithFields(logrus.Fields{
+			"sql":         sql,
+			"rows":        rows,
+			"duration_ms": float64(elapsed.Microseconds()) / 1000,
+		})
+	}
+
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= gormlogger.Error:
+		log().Error("Query failed: ", err)
+	case elapsed > slowQueryThreshold && l.level >= gormlogger.Warn:
+		log().Warn("Slow query")
+	case logrus.IsLevelEnabled(logrus.TraceLevel):
+		log().Trace("Query")
+	}
+}
+
+func (queryLogger) ParamsFilter(ctx context.Context, sql string, params ...any) (string, []any) {
+	return sql, nil
+}
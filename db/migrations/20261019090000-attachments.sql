@@ -0,0 +1,19 @@
+-- +migrate Up
+CREATE TABLE attachments (
+    id SERIAL PRIMARY KEY,
+    troubleshoot_log_id INT NOT NULL REFERENCES troubleshoot_logs(id),
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    thumbnail_key VARCHAR(255),
+    source VARCHAR(20) NOT NULL DEFAULT 'upload',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE INDEX idx_attachments_troubleshoot_log_id ON attachments(troubleshoot_log_id);
+
+-- +migrate Down
+DROP TABLE IF EXISTS attachments;
@@ -0,0 +1,24 @@
+-- +migrate Up
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Indonesian stemming for free text, the simple config for part names and
+-- device codes so they are matched as written.
+ALTER TABLE troubleshoot_logs ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('indonesian', coalesce(issue, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(part, '')), 'A') ||
+        setweight(to_tsvector('indonesian', coalesce(solution, '')), 'B') ||
+        setweight(to_tsvector('indonesian', coalesce(whatsapp_message, '')), 'C')
+    ) STORED;
+
+CREATE INDEX idx_troubleshoot_logs_search_vector ON troubleshoot_logs USING GIN (search_vector);
+CREATE INDEX idx_troubleshoot_logs_issue_trgm ON troubleshoot_logs USING GIN (issue gin_trgm_ops);
+CREATE INDEX idx_troubleshoot_logs_solution_trgm ON troubleshoot_logs USING GIN (solution gin_trgm_ops);
+CREATE INDEX idx_troubleshoot_logs_part_trgm ON troubleshoot_logs USING GIN (part gin_trgm_ops);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_troubleshoot_logs_part_trgm;
+DROP INDEX IF EXISTS idx_troubleshoot_logs_solution_trgm;
+DROP INDEX IF EXISTS idx_troubleshoot_logs_issue_trgm;
+DROP INDEX IF EXISTS idx_troubleshoot_logs_search_vector;
+ALTER TABLE troubleshoot_logs DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,15 @@
+-- +migrate Up
+CREATE INDEX idx_troubleshoot_logs_status ON troubleshoot_logs(status) WHERE deleted_at IS NULL;
+CREATE INDEX idx_troubleshoot_logs_project_id ON troubleshoot_logs(project_id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_troubleshoot_logs_location_id ON troubleshoot_logs(location_id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_troubleshoot_logs_user_id ON troubleshoot_logs(user_id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_troubleshoot_logs_trouble_date ON troubleshoot_logs(trouble_date, trouble_time) WHERE deleted_at IS NULL;
+CREATE INDEX idx_troubleshoot_logs_created_at ON troubleshoot_logs(created_at) WHERE deleted_at IS NULL;
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_troubleshoot_logs_created_at;
+DROP INDEX IF EXISTS idx_troubleshoot_logs_trouble_date;
+DROP INDEX IF EXISTS idx_troubleshoot_logs_user_id;
+DROP INDEX IF EXISTS idx_troubleshoot_logs_location_id;
+DROP INDEX IF EXISTS idx_troubleshoot_logs_project_id;
+DROP INDEX IF EXISTS idx_troubleshoot_logs_status;
@@ -0,0 +1,16 @@
+-- +migrate Up
+ALTER TABLE troubleshoot_logs
+    ADD COLUMN is_recurring BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN recurrence_of_id INT REFERENCES troubleshoot_logs(id),
+    ADD COLUMN recurrence_count INT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_troubleshoot_logs_device_part ON troubleshoot_logs(LOWER(device_number), LOWER(part)) WHERE deleted_at IS NULL;
+CREATE INDEX idx_troubleshoot_logs_device_id_part ON troubleshoot_logs(device_id, LOWER(part)) WHERE deleted_at IS NULL;
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_troubleshoot_logs_device_id_part;
+DROP INDEX IF EXISTS idx_troubleshoot_logs_device_part;
+ALTER TABLE troubleshoot_logs
+    DROP COLUMN IF EXISTS recurrence_count,
+    DROP COLUMN IF EXISTS recurrence_of_id,
+    DROP COLUMN IF EXISTS is_recurring;
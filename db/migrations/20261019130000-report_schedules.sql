@@ -0,0 +1,36 @@
+-- +migrate Up
+CREATE TABLE report_schedules (
+    id SERIAL PRIMARY KEY,
+    project_id INT NOT NULL REFERENCES projects(id),
+    name VARCHAR(100) NOT NULL,
+    cron_expression VARCHAR(100) NOT NULL,
+    timezone VARCHAR(50) NOT NULL DEFAULT 'Asia/Jakarta',
+    period VARCHAR(10) NOT NULL DEFAULT 'day',
+    target VARCHAR(150) NOT NULL,
+    template TEXT,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE TABLE report_deliveries (
+    id SERIAL PRIMARY KEY,
+    report_schedule_id INT NOT NULL REFERENCES report_schedules(id),
+    period_start TIMESTAMP NOT NULL,
+    period_end TIMESTAMP NOT NULL,
+    target VARCHAR(150) NOT NULL,
+    message TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    error TEXT,
+    attempts INT NOT NULL DEFAULT 1,
+    sent_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_report_deliveries_schedule_id ON report_deliveries(report_schedule_id, created_at);
+
+-- +migrate Down
+DROP TABLE IF EXISTS report_deliveries;
+DROP TABLE IF EXISTS report_schedules;
@@ -0,0 +1,58 @@
+-- +migrate Up
+ALTER TABLE users ALTER COLUMN role TYPE VARCHAR(50) USING role::text;
+DROP TYPE IF EXISTS roles;
+
+CREATE TABLE roles (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL UNIQUE,
+    description VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE permissions (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(50) NOT NULL UNIQUE,
+    description VARCHAR(255)
+);
+
+CREATE TABLE role_permissions (
+    role_id INT NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    permission_id INT NOT NULL REFERENCES permissions(id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+INSERT INTO roles (name, description) VALUES
+    ('admin', 'Full access'),
+    ('it_support', 'Technician handling troubleshooting tickets');
+
+INSERT INTO permissions (code, description) VALUES
+    ('ticket:read', 'View troubleshooting tickets and attachments'),
+    ('ticket:write', 'Create and edit troubleshooting tickets'),
+    ('ticket:close', 'Resolve and close troubleshooting tickets'),
+    ('ticket:delete', 'Delete troubleshooting tickets'),
+    ('masterdata:read', 'View projects, locations, devices and work types'),
+    ('masterdata:write', 'Manage projects, locations, devices and work types'),
+    ('user:read', 'View users'),
+    ('user:write', 'Manage users'),
+    ('stats:read', 'View statistics'),
+    ('report:manage', 'Manage scheduled reports'),
+    ('role:manage', 'Manage roles and permissions');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p WHERE r.name = 'admin';
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p
+    ON p.code IN ('ticket:read', 'ticket:write', 'ticket:close', 'masterdata:read', 'stats:read')
+WHERE r.name = 'it_support';
+
+ALTER TABLE users ADD CONSTRAINT fk_users_role FOREIGN KEY (role) REFERENCES roles(name) ON UPDATE CASCADE;
+
+-- +migrate Down
+ALTER TABLE users DROP CONSTRAINT IF EXISTS fk_users_role;
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS roles;
+CREATE TYPE roles AS ENUM ('admin', 'it_support');
+ALTER TABLE users ALTER COLUMN role TYPE roles USING role::roles;
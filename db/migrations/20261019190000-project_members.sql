@@ -0,0 +1,23 @@
+-- +migrate Up
+CREATE TABLE project_members (
+    project_id INT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (project_id, user_id)
+);
+
+CREATE INDEX idx_project_members_user_id ON project_members (user_id);
+
+-- Roles with project:all see every project; everyone else only sees the
+-- projects they are a member of. Only admin keeps the global view, other
+-- roles have to be added to their projects.
+INSERT INTO permissions (code, description) VALUES
+    ('project:all', 'See tickets of every project regardless of membership');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code = 'project:all'
+WHERE r.name = 'admin';
+
+-- +migrate Down
+DELETE FROM permissions WHERE code = 'project:all';
+DROP TABLE IF EXISTS project_members;
@@ -0,0 +1,22 @@
+-- +migrate Up
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_code_name_key;
+CREATE UNIQUE INDEX users_code_name_active_key ON users (code_name) WHERE deleted_at IS NULL;
+
+ALTER TABLE locations DROP CONSTRAINT IF EXISTS locations_code_name_key;
+CREATE UNIQUE INDEX locations_code_name_active_key ON locations (code_name) WHERE deleted_at IS NULL;
+
+INSERT INTO permissions (code, description) VALUES
+    ('trash:purge', 'Permanently delete records from the trash');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code = 'trash:purge'
+WHERE r.name = 'admin';
+
+-- +migrate Down
+DELETE FROM permissions WHERE code = 'trash:purge';
+
+DROP INDEX IF EXISTS locations_code_name_active_key;
+ALTER TABLE locations ADD CONSTRAINT locations_code_name_key UNIQUE (code_name);
+
+DROP INDEX IF EXISTS users_code_name_active_key;
+ALTER TABLE users ADD CONSTRAINT users_code_name_key UNIQUE (code_name);
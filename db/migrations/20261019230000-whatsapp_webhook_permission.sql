@@ -0,0 +1,10 @@
+-- +migrate Up
+INSERT INTO permissions (code, description) VALUES
+    ('webhook:whatsapp', 'Post WhatsApp group messages to the webhook, granted to the gateway''s API key');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code = 'webhook:whatsapp'
+WHERE r.name = 'admin';
+
+-- +migrate Down
+DELETE FROM permissions WHERE code = 'webhook:whatsapp';
@@ -0,0 +1,14 @@
+-- +migrate Up
+-- WhatsApp tickets are queued for the spreadsheet when they are created and
+-- appended by a background worker. Tickets created before this migration
+-- stay out of the queue. The claim keeps two instances from appending the
+-- same ticket, a claim older than its lease is taken over.
+ALTER TABLE troubleshoot_logs ADD COLUMN sheet_sync_pending BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE troubleshoot_logs ADD COLUMN sheet_sync_claimed_at TIMESTAMP DEFAULT NULL;
+
+CREATE INDEX idx_troubleshoot_logs_sheet_sync_pending ON troubleshoot_logs(created_at) WHERE sheet_sync_pending;
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_troubleshoot_logs_sheet_sync_pending;
+ALTER TABLE troubleshoot_logs DROP COLUMN sheet_sync_claimed_at;
+ALTER TABLE troubleshoot_logs DROP COLUMN sheet_sync_pending;
@@ -0,0 +1,284 @@
+-- SQLite schema for local development and tests. It matches the Postgres
+-- migrations up to 20261019200000-soft_delete_unique_indexes.sql, later
+-- Postgres migrations need a SQLite counterpart here.
+--
+-- Dates and times are stored as text. The TIME columns are declared
+-- TIMESTAMP, the driver only parses declared DATE and TIMESTAMP columns
+-- back into times. Full-text search and trigram
+-- similarity have no SQLite equivalent, the repositories fall back to LIKE
+-- and an in-process similarity there.
+
+-- +migrate Up
+CREATE TABLE roles (
+    id INTEGER PRIMARY KEY,
+    name VARCHAR(50) NOT NULL UNIQUE,
+    description VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE permissions (
+    id INTEGER PRIMARY KEY,
+    code VARCHAR(50) NOT NULL UNIQUE,
+    description VARCHAR(255)
+);
+
+CREATE TABLE role_permissions (
+    role_id INT NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    permission_id INT NOT NULL REFERENCES permissions(id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+CREATE TABLE users (
+    id INTEGER PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    code_name VARCHAR(10) NOT NULL,
+    username VARCHAR(100) NOT NULL,
+    password VARCHAR(255) NOT NULL,
+    role VARCHAR(50) NOT NULL REFERENCES roles(name) ON UPDATE CASCADE,
+    password_changed_at TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE UNIQUE INDEX users_code_name_active_key ON users (code_name) WHERE deleted_at IS NULL;
+
+CREATE TABLE projects (
+    id INTEGER PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE TABLE locations (
+    id INTEGER PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    code_name VARCHAR(10) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE UNIQUE INDEX locations_code_name_active_key ON locations (code_name) WHERE deleted_at IS NULL;
+
+CREATE TABLE devices (
+    id INTEGER PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE TABLE work_types (
+    id INTEGER PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE TABLE troubleshoot_logs (
+    id INTEGER PRIMARY KEY,
+    ticket_number VARCHAR(50) UNIQUE,
+    trouble_date DATE NOT NULL,
+    trouble_time TIMESTAMP NOT NULL,
+    done_date DATE,
+    done_time TIMESTAMP,
+    duration TEXT,
+    user_id INT REFERENCES users(id),
+    project_id INT REFERENCES projects(id),
+    location_id INT REFERENCES locations(id),
+    device_id INT REFERENCES devices(id),
+    work_type_id INT REFERENCES work_types(id),
+    device_number VARCHAR(20),
+    part VARCHAR(100),
+    issue TEXT,
+    solution TEXT,
+    status VARCHAR(20) DEFAULT 'OPEN',
+    whatsapp_sender VARCHAR(50),
+    whatsapp_message TEXT,
+    sheet_id VARCHAR(120),
+    sheet_row INT,
+    is_recurring BOOLEAN NOT NULL DEFAULT FALSE,
+    recurrence_of_id INT REFERENCES troubleshoot_logs(id),
+    recurrence_count INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE INDEX idx_troubleshoot_logs_status ON troubleshoot_logs(status) WHERE deleted_at IS NULL;
+CREATE INDEX idx_troubleshoot_logs_project_id ON troubleshoot_logs(project_id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_troubleshoot_logs_location_id ON troubleshoot_logs(location_id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_troubleshoot_logs_user_id ON troubleshoot_logs(user_id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_troubleshoot_logs_trouble_date ON troubleshoot_logs(trouble_date, trouble_time) WHERE deleted_at IS NULL;
+CREATE INDEX idx_troubleshoot_logs_created_at ON troubleshoot_logs(created_at) WHERE deleted_at IS NULL;
+CREATE INDEX idx_troubleshoot_logs_device_part ON troubleshoot_logs(LOWER(device_number), LOWER(part)) WHERE deleted_at IS NULL;
+CREATE INDEX idx_troubleshoot_logs_device_id_part ON troubleshoot_logs(device_id, LOWER(part)) WHERE deleted_at IS NULL;
+
+CREATE TABLE attachments (
+    id INTEGER PRIMARY KEY,
+    troubleshoot_log_id INT NOT NULL REFERENCES troubleshoot_logs(id),
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    thumbnail_key VARCHAR(255),
+    source VARCHAR(20) NOT NULL DEFAULT 'upload',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE INDEX idx_attachments_troubleshoot_log_id ON attachments(troubleshoot_log_id);
+
+CREATE TABLE report_schedules (
+    id INTEGER PRIMARY KEY,
+    project_id INT NOT NULL REFERENCES projects(id),
+    name VARCHAR(100) NOT NULL,
+    cron_expression VARCHAR(100) NOT NULL,
+    timezone VARCHAR(50) NOT NULL DEFAULT 'Asia/Jakarta',
+    period VARCHAR(10) NOT NULL DEFAULT 'day',
+    target VARCHAR(150) NOT NULL,
+    template TEXT,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE TABLE report_deliveries (
+    id INTEGER PRIMARY KEY,
+    report_schedule_id INT NOT NULL REFERENCES report_schedules(id),
+    period_start TIMESTAMP NOT NULL,
+    period_end TIMESTAMP NOT NULL,
+    target VARCHAR(150) NOT NULL,
+    message TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    error TEXT,
+    attempts INT NOT NULL DEFAULT 1,
+    sent_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_report_deliveries_schedule_id ON report_deliveries(report_schedule_id, created_at);
+
+CREATE TABLE refresh_tokens (
+    id INTEGER PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    family_id VARCHAR(64) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    replaced_by_id INT REFERENCES refresh_tokens(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens (family_id);
+CREATE INDEX idx_refresh_tokens_user_id ON refresh_tokens (user_id);
+CREATE INDEX idx_refresh_tokens_expires_at ON refresh_tokens (expires_at);
+
+CREATE TABLE revoked_access_tokens (
+    jti VARCHAR(64) PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_revoked_access_tokens_expires_at ON revoked_access_tokens (expires_at);
+
+CREATE TABLE password_reset_tokens (
+    id INTEGER PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    created_by INT REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_password_reset_tokens_user_id ON password_reset_tokens (user_id);
+
+CREATE TABLE login_attempts (
+    scope VARCHAR(20) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    failures INT NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP NOT NULL,
+    locked_until TIMESTAMP DEFAULT NULL,
+    PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX idx_login_attempts_last_failure_at ON login_attempts (last_failure_at);
+
+CREATE TABLE api_keys (
+    id INTEGER PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(20) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL UNIQUE,
+    scopes TEXT NOT NULL DEFAULT '[]',
+    created_by INT REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP DEFAULT NULL,
+    last_used_at TIMESTAMP DEFAULT NULL,
+    revoked_at TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE project_members (
+    project_id INT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (project_id, user_id)
+);
+
+CREATE INDEX idx_project_members_user_id ON project_members (user_id);
+
+INSERT INTO roles (name, description) VALUES
+    ('admin', 'Full access'),
+    ('it_support', 'Technician handling troubleshooting tickets');
+
+INSERT INTO permissions (code, description) VALUES
+    ('ticket:read', 'View troubleshooting tickets and attachments'),
+    ('ticket:write', 'Create and edit troubleshooting tickets'),
+    ('ticket:close', 'Resolve and close troubleshooting tickets'),
+    ('ticket:delete', 'Delete troubleshooting tickets'),
+    ('masterdata:read', 'View projects, locations, devices and work types'),
+    ('masterdata:write', 'Manage projects, locations, devices and work types'),
+    ('user:read', 'View users'),
+    ('user:write', 'Manage users'),
+    ('stats:read', 'View statistics'),
+    ('report:manage', 'Manage scheduled reports'),
+    ('role:manage', 'Manage roles and permissions'),
+    ('apikey:manage', 'Issue and revoke API keys'),
+    ('project:all', 'See tickets of every project regardless of membership'),
+    ('trash:purge', 'Permanently delete records from the trash');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p WHERE r.name = 'admin';
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p
+    ON p.code IN ('ticket:read', 'ticket:write', 'ticket:close', 'masterdata:read', 'stats:read')
+WHERE r.name = 'it_support';
+
+-- +migrate Down
+DROP TABLE IF EXISTS project_members;
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS login_attempts;
+DROP TABLE IF EXISTS password_reset_tokens;
+DROP TABLE IF EXISTS revoked_access_tokens;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS report_deliveries;
+DROP TABLE IF EXISTS report_schedules;
+DROP TABLE IF EXISTS attachments;
+DROP TABLE IF EXISTS troubleshoot_logs;
+DROP TABLE IF EXISTS work_types;
+DROP TABLE IF EXISTS devices;
+DROP TABLE IF EXISTS locations;
+DROP TABLE IF EXISTS projects;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS roles;
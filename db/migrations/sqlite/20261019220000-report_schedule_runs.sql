@@ -0,0 +1,12 @@
+-- +migrate Up
+-- One row per scheduled slot of a report. Every instance runs the scheduler,
+-- the one that inserts the row sends the report.
+CREATE TABLE report_schedule_runs (
+    report_schedule_id INT NOT NULL REFERENCES report_schedules(id) ON DELETE CASCADE,
+    scheduled_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (report_schedule_id, scheduled_at)
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS report_schedule_runs;
@@ -0,0 +1,24 @@
+-- +migrate Up
+-- Media sent with a WhatsApp report is fetched and attached by a background
+-- worker, one row per media item. A row is claimed before it is processed
+-- so only one instance works on it, a claim older than its lease is taken
+-- over. Finished rows are deleted, rows that failed for good keep the error.
+CREATE TABLE whatsapp_media_jobs (
+    id INTEGER PRIMARY KEY,
+    troubleshoot_log_id INT NOT NULL REFERENCES troubleshoot_logs(id) ON DELETE CASCADE,
+    media_index INT NOT NULL,
+    url TEXT,
+    base64 TEXT,
+    file_name VARCHAR(255),
+    mime_type VARCHAR(100),
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    claimed_at TIMESTAMP DEFAULT NULL,
+    failed_at TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_whatsapp_media_jobs_pending ON whatsapp_media_jobs(created_at) WHERE failed_at IS NULL;
+
+-- +migrate Down
+DROP TABLE IF EXISTS whatsapp_media_jobs;
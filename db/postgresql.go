@@ -1,10 +1,9 @@
 package db
 
 import (
-	"log"
-
 	"github.com/tubagusmf/log-troubleshoot-be/internal/helper"
 
+	"github.com/sirupsen/logrus"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -12,9 +11,9 @@ import (
 func NewPostgres() *gorm.DB {
 	dsn := helper.GetConnectionString()
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true, Logger: newQueryLogger()})
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		logrus.Fatalf("Failed to connect to database: %v", err)
 	}
 
 	return db
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/config"
+
+	"github.com/glebarez/sqlite"
+	migrate "github.com/rubenv/sql-migrate"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// NewSQLite opens the embedded SQLite database and applies its pending
+// migrations, so the service runs without a database server. A path of
+// :memory: keeps the database in memory for the life of the process.
+//
+// SQLite allows one writer at a time, so all queries share one connection
+// instead of failing with "database is locked" under concurrent requests.
+func NewSQLite() *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(SQLiteDSN()), &gorm.Config{TranslateError: true, Logger: newQueryLogger()})
+	if err != nil {
+		logrus.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		logrus.Fatalf("Failed to get SQL DB from Gorm: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	migrations, dialect := Migrations()
+	if _, err := migrate.Exec(sqlDB, dialect, migrations, migrate.Up); err != nil {
+		logrus.Fatalf("Failed to apply migrations: %v", err)
+	}
+
+	return db
+}
+
+// SQLiteDSN returns the data source name of the configured SQLite file,
+// creating its directory when needed. Foreign keys are off in SQLite unless
+// enabled per connection.
+func SQLiteDSN() string {
+	const pragmas = "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+
+	path := config.DatabaseSQLitePath()
+	if path == ":memory:" {
+		return "file::memory:?cache=shared&" + pragmas
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		logrus.Fatalf("Failed to create database directory: %v", err)
+	}
+
+	return "file:" + path + "?" + pragmas
+}
@@ -14,6 +14,90 @@ func GetDbPort() string {
 	return viper.GetString("port")
 }
 
+func HTTPAddress() string {
+	if address := viper.GetString("http.address"); address != "" {
+		return address
+	}
+	return ":3000"
+}
+
+// HTTPShutdownTimeout is how long a shutdown waits for in-flight requests
+// and background jobs to finish.
+func HTTPShutdownTimeout() time.Duration {
+	if timeout := viper.GetDuration("http.shutdown_timeout"); timeout > 0 {
+		return timeout
+	}
+	return 30 * time.Second
+}
+
+// HTTPTrustedProxies lists the reverse proxies (IPs or CIDRs) whose
+// X-Forwarded-For header is trusted for the client IP. Empty means the
+// service is reached directly and the peer address is used.
+func HTTPTrustedProxies() []string {
+	return viper.GetStringSlice("http.trusted_proxies")
+}
+
+// MetricsToken is the bearer token scrapers send to /metrics.
+func MetricsToken() string {
+	return viper.GetString("metrics.token")
+}
+
+// MetricsPublic serves /metrics without a token when none is configured,
+// for deployments that only expose it on an internal network. The open
+// ticket gauges carry project and location names.
+func MetricsPublic() bool {
+	return viper.GetBool("metrics.public")
+}
+
+func LogLevel() string {
+	if level := viper.GetString("log.level"); level != "" {
+		return level
+	}
+	return "info"
+}
+
+// LogSenderHashKey is the HMAC key WhatsApp senders are hashed with in logs.
+// Without one a random key is used, so the hashes only match within one
+// process.
+func LogSenderHashKey() string {
+	return viper.GetString("log.sender_hash_key")
+}
+
+// LogFormat is json or text. Development defaults to text, everything else
+// to json for log shippers.
+func LogFormat() string {
+	if format := viper.GetString("log.format"); format != "" {
+		return format
+	}
+	if ENV() == "development" {
+		return "text"
+	}
+	return "json"
+}
+
+// LogOutput is stdout, stderr or a file path to append to.
+func LogOutput() string {
+	if output := viper.GetString("log.output"); output != "" {
+		return output
+	}
+	return "stdout"
+}
+
+// DatabaseDriver is postgres or sqlite.
+func DatabaseDriver() string {
+	if driver := viper.GetString("database.driver"); driver != "" {
+		return driver
+	}
+	return "postgres"
+}
+
+func DatabaseSQLitePath() string {
+	if path := viper.GetString("database.sqlite.path"); path != "" {
+		return path
+	}
+	return "./storage/troubleshoot.db"
+}
+
 func GetDbHost() string {
 	return viper.GetString("postgres.dbhost")
 }
@@ -35,9 +119,345 @@ func JWTSigningKey() string {
 }
 
 func JWTExp() time.Duration {
-	return viper.GetDuration("jwt.exp")
+	if exp := viper.GetDuration("jwt.exp"); exp > 0 {
+		return exp
+	}
+	return 15 * time.Minute
+}
+
+func JWTRefreshExp() time.Duration {
+	if exp := viper.GetDuration("jwt.refresh_exp"); exp > 0 {
+		return exp
+	}
+	return 30 * 24 * time.Hour
 }
 
 func GetString(key string) string {
 	return viper.GetString(key)
 }
+
+func StorageDriver() string {
+	if driver := viper.GetString("storage.driver"); driver != "" {
+		return driver
+	}
+	return "local"
+}
+
+func StorageLocalPath() string {
+	if path := viper.GetString("storage.local.path"); path != "" {
+		return path
+	}
+	return "./storage"
+}
+
+func StorageS3Endpoint() string {
+	return viper.GetString("storage.s3.endpoint")
+}
+
+func StorageS3AccessKey() string {
+	return viper.GetString("storage.s3.access_key")
+}
+
+func StorageS3SecretKey() string {
+	return viper.GetString("storage.s3.secret_key")
+}
+
+func StorageS3Bucket() string {
+	return viper.GetString("storage.s3.bucket")
+}
+
+func StorageS3Region() string {
+	return viper.GetString("storage.s3.region")
+}
+
+func StorageS3UseSSL() bool {
+	return viper.GetBool("storage.s3.use_ssl")
+}
+
+func AttachmentMaxSize() int64 {
+	if size := viper.GetInt64("attachment.max_size"); size > 0 {
+		return size
+	}
+	return 10 << 20
+}
+
+// AttachmentMaxPixels caps the width times height of uploaded images, which
+// are decoded for the thumbnail.
+func AttachmentMaxPixels() int64 {
+	if pixels := viper.GetInt64("attachment.max_pixels"); pixels > 0 {
+		return pixels
+	}
+	return 40_000_000
+}
+
+func AttachmentAllowedTypes() []string {
+	if types := viper.GetStringSlice("attachment.allowed_types"); len(types) > 0 {
+		return types
+	}
+	return []string{"image/jpeg", "image/png", "image/gif", "image/webp", "application/pdf"}
+}
+
+// WhatsAppMediaAllowedHosts lists the hosts WhatsApp media URLs may be
+// downloaded from, e.g. the gateway's media host. "*.example.com" allows
+// subdomains. Empty means media is only accepted as base64.
+func WhatsAppMediaAllowedHosts() []string {
+	return viper.GetStringSlice("whatsapp.media.allowed_hosts")
+}
+
+// WhatsAppMediaMaxItems caps the media of one WhatsApp message. A message
+// with more is rejected.
+func WhatsAppMediaMaxItems() int {
+	if items := viper.GetInt("whatsapp.media.max_items"); items > 0 {
+		return items
+	}
+	return 10
+}
+
+// WhatsAppMediaFetchInterval is how often queued WhatsApp media is fetched
+// and attached to its ticket.
+func WhatsAppMediaFetchInterval() time.Duration {
+	if interval := viper.GetDuration("whatsapp.media.fetch_interval"); interval > 0 {
+		return interval
+	}
+	return 5 * time.Second
+}
+
+// WhatsAppWebhookBodyLimit caps the webhook request body, base64 media
+// included, e.g. "32M".
+func WhatsAppWebhookBodyLimit() string {
+	if limit := viper.GetString("whatsapp.webhook.body_limit"); limit != "" {
+		return limit
+	}
+	return "32M"
+}
+
+// SpreadsheetDriver is google or file. It defaults to google when Google
+// credentials are configured, so existing deployments keep syncing.
+func SpreadsheetDriver() string {
+	if driver := viper.GetString("spreadsheet.driver"); driver != "" {
+		return driver
+	}
+	if viper.GetString("GOOGLE_CREDENTIAL") != "" {
+		return "google"
+	}
+	return "file"
+}
+
+func SpreadsheetFilePath() string {
+	if path := viper.GetString("spreadsheet.file.path"); path != "" {
+		return path
+	}
+	return "./storage/spreadsheet.log"
+}
+
+// SheetSyncInterval is how often queued WhatsApp tickets are appended to
+// the spreadsheet.
+func SheetSyncInterval() time.Duration {
+	if interval := viper.GetDuration("sheet.sync_interval"); interval > 0 {
+		return interval
+	}
+	return 10 * time.Second
+}
+
+// ReportScheduleReloadInterval is how often the report scheduler checks the
+// database for schedules changed through another instance.
+func ReportScheduleReloadInterval() time.Duration {
+	if interval := viper.GetDuration("report.reload_interval"); interval > 0 {
+		return interval
+	}
+	return time.Minute
+}
+
+func ImportMaxSize() int64 {
+	if size := viper.GetInt64("import.max_size"); size > 0 {
+		return size
+	}
+	return 10 << 20
+}
+
+func ImportMaxRows() int {
+	if rows := viper.GetInt("import.max_rows"); rows > 0 {
+		return rows
+	}
+	return 5000
+}
+
+// ExportPDFMaxRows caps PDF exports, which are rendered in memory. CSV and
+// XLSX exports stream and have no cap.
+func ExportPDFMaxRows() int {
+	if rows := viper.GetInt("export.pdf_max_rows"); rows > 0 {
+		return rows
+	}
+	return 5000
+}
+
+func StatsCacheTTL() time.Duration {
+	if ttl := viper.GetDuration("stats.cache_ttl"); ttl > 0 {
+		return ttl
+	}
+	return time.Minute
+}
+
+func RecurrenceWindow() time.Duration {
+	if window := viper.GetDuration("recurrence.window"); window > 0 {
+		return window
+	}
+	return 30 * 24 * time.Hour
+}
+
+func RecurrenceMinSimilarity() float64 {
+	if similarity := viper.GetFloat64("recurrence.min_similarity"); similarity > 0 {
+		return similarity
+	}
+	return 0.3
+}
+
+func MessagingDriver() string {
+	if driver := viper.GetString("messaging.driver"); driver != "" {
+		return driver
+	}
+	return "file"
+}
+
+func MessagingFilePath() string {
+	if path := viper.GetString("messaging.file.path"); path != "" {
+		return path
+	}
+	return "./storage/outbox.log"
+}
+
+func MessagingHTTPURL() string {
+	return viper.GetString("messaging.http.url")
+}
+
+func MessagingHTTPToken() string {
+	return viper.GetString("messaging.http.token")
+}
+
+// JWTKey describes one token signing key. Secret is used by HS256; RS256
+// and EdDSA keys are PEM encoded, either inline or read from a file. A key
+// that only has a public key can still verify tokens it signed earlier.
+// ExpiresAt (RFC 3339) stops the key from verifying once it has passed.
+type JWTKey struct {
+	KID            string `mapstructure:"kid"`
+	Algorithm      string `mapstructure:"algorithm"`
+	Secret         string `mapstructure:"secret"`
+	PrivateKey     string `mapstructure:"private_key"`
+	PrivateKeyFile string `mapstructure:"private_key_file"`
+	PublicKey      string `mapstructure:"public_key"`
+	PublicKeyFile  string `mapstructure:"public_key_file"`
+	ExpiresAt      string `mapstructure:"expires_at"`
+}
+
+// JWTKeys returns the configured jwt.keys. When none are configured the
+// legacy jwt.signing_key (or JWT_SECRET) is used as a single HS256 key.
+func JWTKeys() ([]JWTKey, error) {
+	var keys []JWTKey
+	if err := viper.UnmarshalKey("jwt.keys", &keys); err != nil {
+		return nil, err
+	}
+
+	secret := JWTSigningKey()
+	if secret == "" {
+		secret = viper.GetString("JWT_SECRET")
+	}
+
+	if len(keys) == 0 && secret != "" {
+		keys = append(keys, JWTKey{
+			KID:       "default",
+			Algorithm: "HS256",
+			Secret:    secret,
+		})
+	}
+
+	return keys, nil
+}
+
+func JWTActiveKID() string {
+	if kid := viper.GetString("jwt.active_kid"); kid != "" {
+		return kid
+	}
+	return "default"
+}
+
+func PasswordBcryptCost() int {
+	if cost := viper.GetInt("password.bcrypt_cost"); cost > 0 {
+		return cost
+	}
+	return 12
+}
+
+func PasswordMinLength() int {
+	if length := viper.GetInt("password.min_length"); length > 0 {
+		return length
+	}
+	return 8
+}
+
+func PasswordRequireUpper() bool {
+	return viper.GetBool("password.require_upper")
+}
+
+func PasswordRequireLower() bool {
+	return viper.GetBool("password.require_lower")
+}
+
+func PasswordRequireDigit() bool {
+	if !viper.IsSet("password.require_digit") {
+		return true
+	}
+	return viper.GetBool("password.require_digit")
+}
+
+func PasswordRequireSymbol() bool {
+	return viper.GetBool("password.require_symbol")
+}
+
+func PasswordResetTokenTTL() time.Duration {
+	if ttl := viper.GetDuration("password.reset_token_ttl"); ttl > 0 {
+		return ttl
+	}
+	return 24 * time.Hour
+}
+
+func LoginMaxFailures() int {
+	if n := viper.GetInt("login.max_failures"); n > 0 {
+		return n
+	}
+	return 5
+}
+
+func LoginIPMaxFailures() int {
+	if n := viper.GetInt("login.ip_max_failures"); n > 0 {
+		return n
+	}
+	return 50
+}
+
+func LoginFailureWindow() time.Duration {
+	if window := viper.GetDuration("login.failure_window"); window > 0 {
+		return window
+	}
+	return 15 * time.Minute
+}
+
+func LoginLockout() time.Duration {
+	if lockout := viper.GetDuration("login.lockout"); lockout > 0 {
+		return lockout
+	}
+	return 15 * time.Minute
+}
+
+func LoginDelayBase() time.Duration {
+	if delay := viper.GetDuration("login.delay_base"); delay > 0 {
+		return delay
+	}
+	return time.Second
+}
+
+func LoginDelayMax() time.Duration {
+	if delay := viper.GetDuration("login.delay_max"); delay > 0 {
+		return delay
+	}
+	return 30 * time.Second
+}
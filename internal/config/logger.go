@@ -1,12 +1,185 @@
 package config
 
-import "github.com/sirupsen/logrus"
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+
+	"github.com/sirupsen/logrus"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are matched against lowercased field names, including the
+// keys of structs and maps logged as a field.
+var sensitiveKeys = []string{"password", "secret", "token", "authorization", "api_key", "credential"}
+
+// phoneKeys keep the last digits so a reporter can still be told apart.
+// Keys ending in _hash hold a hashed number and are logged as they are.
+var phoneKeys = []string{"phone", "sender"}
+
+// SetupLogger configures the shared logrus logger from log.level,
+// log.format and log.output. Entries logged with a request context carry
+// its request_id, and sensitive fields are redacted.
 func SetupLogger() {
-	log := logrus.New()
+	logger := logrus.StandardLogger()
+
+	level, err := logrus.ParseLevel(LogLevel())
+	if err != nil {
+		logrus.Warnf("Unknown log level %q, using info", LogLevel())
+		level = logrus.InfoLevel
+	}
+	logger.SetLevel(level)
+
+	if LogFormat() == "text" {
+		logger.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	} else {
+		logger.SetFormatter(&logrus.JSONFormatter{})
+	}
+
+	output, err := logOutput(LogOutput())
+	if err != nil {
+		logrus.Fatalf("Failed to open log output: %v", err)
+	}
+	logger.SetOutput(output)
+
+	logger.ReplaceHooks(make(logrus.LevelHooks))
+	logger.AddHook(requestIDHook{})
+	logger.AddHook(redactHook{})
+}
+
+func logOutput(output string) (io.Writer, error) {
+	switch output {
+	case "stdout":
+		return os.Stdout, nil
+	case "stderr":
+		return os.Stderr, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(output), 0o755); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+}
+
+// requestIDHook adds the request_id of entries logged with WithContext.
+type requestIDHook struct{}
+
+func (requestIDHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (requestIDHook) Fire(entry *logrus.Entry) error {
+	if id := model.RequestIDFromContext(entry.Context); id != "" {
+		entry.Data["request_id"] = id
+	}
+	return nil
+}
+
+// redactHook masks sensitive fields. Entries are copied before hooks run,
+// so the caller's fields are left as they were.
+type redactHook struct{}
+
+func (redactHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (redactHook) Fire(entry *logrus.Entry) error {
+	for key, value := range entry.Data {
+		if masked, ok := redactField(key, value); ok {
+			entry.Data[key] = masked
+			continue
+		}
+		if _, isErr := value.(error); isErr {
+			continue
+		}
+		if nested, ok := redactNested(value); ok {
+			entry.Data[key] = nested
+		}
+	}
+	return nil
+}
+
+// redactField masks the value when the key names a sensitive field.
+func redactField(key string, value any) (any, bool) {
+	key = strings.ToLower(key)
+
+	for _, phone := range phoneKeys {
+		if strings.Contains(key, phone) && !strings.HasSuffix(key, "_hash") {
+			if s, ok := value.(string); ok {
+				return maskPhone(s), true
+			}
+			return redacted, true
+		}
+	}
+
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return redacted, true
+		}
+	}
+
+	return nil, false
+}
+
+func maskPhone(phone string) string {
+	const visible = 4
+	if len(phone) <= visible {
+		return strings.Repeat("*", len(phone))
+	}
+	return strings.Repeat("*", len(phone)-visible) + phone[len(phone)-visible:]
+}
+
+// redactNested redacts structs, maps and slices through their JSON form, the
+// same keys a JSON log line would show. ok is false when nothing needed
+// masking, so those values are logged untouched.
+func redactNested(value any) (any, bool) {
+	switch reflect.Indirect(reflect.ValueOf(value)).Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+	default:
+		return nil, false
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, false
+	}
+
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, false
+	}
+
+	return redactDecoded(decoded)
+}
+
+func redactDecoded(value any) (any, bool) {
+	changed := false
+
+	switch v := value.(type) {
+	case map[string]any:
+		for key, inner := range v {
+			if masked, ok := redactField(key, inner); ok {
+				v[key] = masked
+				changed = true
+			} else if nested, ok := redactDecoded(inner); ok {
+				v[key] = nested
+				changed = true
+			}
+		}
+	case []any:
+		for i, inner := range v {
+			if nested, ok := redactDecoded(inner); ok {
+				v[i] = nested
+				changed = true
+			}
+		}
+	}
 
-	log.SetFormatter(&logrus.TextFormatter{
-		FullTimestamp: true,
-		ForceColors:   true,
-	})
+	return value, changed
 }
@@ -1,8 +1,7 @@
 package config
 
 import (
-	"log"
-
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 )
 
@@ -12,14 +11,14 @@ func LoadWithViper() {
 	viper.AddConfigPath(".")
 
 	if err := viper.ReadInConfig(); err != nil {
-		log.Fatalf("Error reading config.yml: %v", err)
+		logrus.Fatalf("Error reading config.yml: %v", err)
 	}
 
 	viper.SetConfigFile(".env")
 	viper.SetConfigType("env")
 
 	if err := viper.MergeInConfig(); err != nil {
-		log.Fatalf("Error reading .env: %v", err)
+		logrus.Fatalf("Error reading .env: %v", err)
 	}
 
 	viper.AutomaticEnv()
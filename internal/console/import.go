@@ -0,0 +1,93 @@
+package console
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/tubagusmf/log-troubleshoot-be/db"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/config"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/repository"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/usecase"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var importDryRun bool
+
+func init() {
+	rootCmd.AddCommand(importCMD)
+
+	importCMD.Flags().BoolVar(&importDryRun, "dry-run", false, "Report what the import would do without saving")
+}
+
+var importCMD = &cobra.Command{
+	Use:   "import <project|location|device|work_type|user> <file>",
+	Short: "Import master data or users from a CSV or XLSX file",
+	Long:  `Upsert the rows of a CSV or XLSX file in one transaction. Nothing is saved when a row fails or with --dry-run.`,
+	Args:  cobra.ExactArgs(2),
+	Run:   importFile,
+}
+
+func importFile(cmd *cobra.Command, args []string) {
+	config.LoadWithViper()
+
+	entity, fileName := args[0], args[1]
+
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		logrus.Fatalf("Failed to read %s: %v", fileName, err)
+	}
+
+	gormDB := db.NewDatabase()
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		logrus.Fatalf("Failed to get SQL DB from Gorm: %v", err)
+	}
+	defer sqlDB.Close()
+
+	importUsecase := usecase.NewImportUsecase(repository.NewImportRepo(gormDB), repository.NewRoleRepo(gormDB), repository.NewUserRepo(gormDB))
+
+	// The command is run by an operator with database access, who may
+	// import anything.
+	ctx := context.WithValue(context.Background(), model.BearerAuthKey, &model.CustomClaims{
+		Permissions: []string{model.PermissionMasterDataWrite, model.PermissionUserWrite, model.PermissionRoleManage},
+	})
+
+	report, err := importUsecase.Import(ctx, model.ImportInput{
+		Entity:   entity,
+		FileName: filepath.Base(fileName),
+		Data:     data,
+		DryRun:   importDryRun,
+	})
+	if err != nil {
+		logrus.Fatalf("Import failed: %v", err)
+	}
+
+	for _, row := range report.Rows {
+		fmt.Printf("row %d\t%s\t%s\n", row.Row, row.Action, row.Key)
+		for _, rowErr := range row.Errors {
+			if rowErr.Field != "" {
+				fmt.Printf("\t%s: %s\n", rowErr.Field, rowErr.Message)
+			} else {
+				fmt.Printf("\t%s\n", rowErr.Message)
+			}
+		}
+	}
+
+	fmt.Printf("%d rows: %d created, %d updated, %d unchanged, %d failed\n",
+		report.Total, report.Created, report.Updated, report.Unchanged, report.Failed)
+
+	switch {
+	case report.Failed > 0:
+		fmt.Println("Nothing was saved because some rows failed")
+		os.Exit(1)
+	case report.DryRun:
+		fmt.Println("Dry run, nothing was saved")
+	default:
+		fmt.Println("Import committed")
+	}
+}
@@ -2,14 +2,16 @@ package console
 
 import (
 	"database/sql"
-	"log"
 
+	"github.com/tubagusmf/log-troubleshoot-be/db"
 	"github.com/tubagusmf/log-troubleshoot-be/internal/config"
 	"github.com/tubagusmf/log-troubleshoot-be/internal/helper"
 
+	_ "github.com/glebarez/go-sqlite"
 	_ "github.com/lib/pq"
 
 	migrate "github.com/rubenv/sql-migrate"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
@@ -35,26 +37,33 @@ var migrationCMD = &cobra.Command{
 func migrateDB(cmd *cobra.Command, args []string) {
 	config.LoadWithViper()
 
-	connDB, err := sql.Open("postgres", helper.GetConnectionString())
-	log.Printf("Connection String: %s", helper.GetConnectionString())
+	var (
+		connDB *sql.DB
+		err    error
+	)
+	if config.DatabaseDriver() == db.DriverSQLite {
+		connDB, err = sql.Open("sqlite", db.SQLiteDSN())
+	} else {
+		connDB, err = sql.Open("postgres", helper.GetConnectionString())
+	}
 
 	if err != nil {
-		log.Fatalf("Error connecting to database: %v", err)
+		logrus.Fatalf("Error connecting to database: %v", err)
 	}
 	defer connDB.Close()
 
-	migrations := &migrate.FileMigrationSource{Dir: "./db/migrations"}
+	migrations, dialect := db.Migrations()
 
 	var n int
 	if direction == "down" {
-		n, err = migrate.ExecMax(connDB, "postgres", migrations, migrate.Down, step)
+		n, err = migrate.ExecMax(connDB, dialect, migrations, migrate.Down, step)
 	} else {
-		n, err = migrate.ExecMax(connDB, "postgres", migrations, migrate.Up, step)
+		n, err = migrate.ExecMax(connDB, dialect, migrations, migrate.Up, step)
 	}
 
 	if err != nil {
-		log.Fatalf("Error applying migrations: %v", err)
+		logrus.Fatalf("Error applying migrations: %v", err)
 	}
 
-	log.Printf("Successfully applied %d migrations", n)
+	logrus.Infof("Successfully applied %d migrations", n)
 }
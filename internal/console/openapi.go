@@ -0,0 +1,55 @@
+package console
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/labstack/echo/v4"
+	handlerHttp "github.com/tubagusmf/log-troubleshoot-be/internal/delivery/http"
+
+	"github.com/spf13/cobra"
+)
+
+var openAPICheck bool
+
+func init() {
+	rootCmd.AddCommand(openAPICMD)
+
+	openAPICMD.Flags().BoolVar(&openAPICheck, "check", false, "Only verify that every registered route is documented")
+}
+
+var openAPICMD = &cobra.Command{
+	Use:   "openapi",
+	Short: "Print the OpenAPI document",
+	Long:  `Print the OpenAPI document, or with --check fail when it is out of sync with the registered routes.`,
+	Run:   openAPI,
+}
+
+func openAPI(cmd *cobra.Command, args []string) {
+	// Handlers only keep their usecases, so routes can be registered
+	// without a database.
+	e := echo.New()
+	registerHandlers(e, httpHandlers{auth: handlerHttp.NewAuth(nil, nil, nil)})
+
+	if openAPICheck {
+		problems := handlerHttp.CheckOpenAPIRoutes(e.Routes())
+		for _, problem := range problems {
+			fmt.Fprintln(os.Stderr, problem)
+		}
+
+		if len(problems) > 0 {
+			os.Exit(1)
+		}
+
+		fmt.Println("OpenAPI document covers every route")
+		return
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(handlerHttp.BuildOpenAPI()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,20 @@
+package console
+
+import (
+	"testing"
+
+	handlerHttp "github.com/tubagusmf/log-troubleshoot-be/internal/delivery/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// TestOpenAPICoversRoutes is `openapi --check`: every registered route is
+// documented and every documented route is registered.
+func TestOpenAPICoversRoutes(t *testing.T) {
+	e := echo.New()
+	registerHandlers(e, httpHandlers{auth: handlerHttp.NewAuth(nil, nil, nil)})
+
+	for _, problem := range handlerHttp.CheckOpenAPIRoutes(e.Routes()) {
+		t.Error(problem)
+	}
+}
@@ -22,7 +22,8 @@ func Execute() {
 }
 
 func init() {
-	config.LoadWithViper()
+	// The configuration is read when a command runs rather than on import,
+	// so the package's tests do not need a config.yml.
+	cobra.OnInitialize(config.LoadWithViper, config.SetupLogger)
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
-	config.SetupLogger()
 }
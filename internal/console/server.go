@@ -1,12 +1,21 @@
 package console
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
-	"sync"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/tubagusmf/log-troubleshoot-be/db"
 	"github.com/tubagusmf/log-troubleshoot-be/internal/config"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/helper"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/metrics"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
 	"github.com/tubagusmf/log-troubleshoot-be/internal/repository"
 	"github.com/tubagusmf/log-troubleshoot-be/internal/usecase"
 
@@ -32,81 +41,301 @@ var serverCMD = &cobra.Command{
 func httpServer(cmd *cobra.Command, args []string) {
 	config.LoadWithViper()
 
-	postgresDB := db.NewPostgres()
-	sqlDB, err := postgresDB.DB()
+	keyManager, err := helper.LoadKeyManager()
 	if err != nil {
-		log.Fatalf("Failed to get SQL DB from Gorm: %v", err)
+		logrus.Fatalf("Failed to load JWT signing keys: %v", err)
+	}
+
+	gormDB := db.NewDatabase()
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		logrus.Fatalf("Failed to get SQL DB from Gorm: %v", err)
 	}
 	defer sqlDB.Close()
 
-	userRepo := repository.NewUserRepo(postgresDB)
-	projectRepo := repository.NewProjectRepo(postgresDB)
-	deviceRepo := repository.NewDeviceRepo(postgresDB)
-	locationRepo := repository.NewLocationRepo(postgresDB)
-	workTypeRepo := repository.NewWorkTypeRepo(postgresDB)
-	troubleshootLogRepo := repository.NewTroubleshootLogRepo(postgresDB)
-
-	sheetRepo, err := repository.NewGoogleSheetRepository(
-		config.GetString("GOOGLE_CREDENTIAL"),
-		config.GetString("GOOGLE_SPREADSHEET_ID"),
-		config.GetString("GOOGLE_SHEET_NAME"),
-	)
+	userRepo := repository.NewUserRepo(gormDB)
+	projectRepo := repository.NewProjectRepo(gormDB)
+	deviceRepo := repository.NewDeviceRepo(gormDB)
+	locationRepo := repository.NewLocationRepo(gormDB)
+	workTypeRepo := repository.NewWorkTypeRepo(gormDB)
+	troubleshootLogRepo := repository.NewTroubleshootLogRepo(gormDB)
+	attachmentRepo := repository.NewAttachmentRepo(gormDB)
+	statsRepo := repository.NewStatsRepo(gormDB)
+	reportScheduleRepo := repository.NewReportScheduleRepo(gormDB)
+
+	fileStorage, err := newFileStorage()
+	if err != nil {
+		logrus.Fatalf("Failed to init file storage: %v", err)
+	}
+
+	messageSender, err := newMessageSender()
+	if err != nil {
+		logrus.Fatalf("Failed to init message sender: %v", err)
+	}
+
+	sheetRepo, err := newSpreadsheet()
 	if err != nil {
-		log.Fatalf("Failed to init Google Sheet: %v", err)
+		logrus.Fatalf("Failed to init spreadsheet: %v", err)
 	}
 
-	//debug
-	log.Println("Credential:", config.GetString("GOOGLE_CREDENTIAL"))
-	log.Println("Spreadsheet:", config.GetString("GOOGLE_SPREADSHEET_ID"))
-	log.Println("Sheet:", config.GetString("GOOGLE_SHEET_NAME"))
+	attachmentUsecase := usecase.NewAttachmentUsecase(attachmentRepo, troubleshootLogRepo, fileStorage)
+	troubleshootLogUsecase := usecase.NewTroubleshootLogUsecase(troubleshootLogRepo, userRepo, projectRepo, locationRepo, deviceRepo, workTypeRepo, attachmentRepo, fileStorage)
+
+	whatsappMediaJobRepo := repository.NewWhatsAppMediaJobRepo(gormDB)
 
 	whatsappConsumerUsecase := usecase.NewWhatsAppConsumerUsecase(
 		userRepo,
+		projectRepo,
+		locationRepo,
 		troubleshootLogRepo,
-		sheetRepo,
+		troubleshootLogUsecase,
+		whatsappMediaJobRepo,
 	)
 
-	userUsecase := usecase.NewUserUsecase(userRepo)
+	roleRepo := repository.NewRoleRepo(gormDB)
+	tokenRepo := repository.NewTokenRepo(gormDB)
+	passwordResetRepo := repository.NewPasswordResetRepo(gormDB)
+	loginAttemptRepo := repository.NewLoginAttemptRepo(gormDB)
+
+	apiKeyRepo := repository.NewAPIKeyRepo(gormDB)
+
+	userUsecase := usecase.NewUserUsecase(userRepo, roleRepo, tokenRepo, passwordResetRepo, loginAttemptRepo, apiKeyRepo)
+	roleUsecase := usecase.NewRoleUsecase(roleRepo)
+	apiKeyUsecase := usecase.NewAPIKeyUsecase(apiKeyRepo, roleRepo, userRepo)
 	projectUsecase := usecase.NewProjectUsecase(projectRepo)
+	projectMemberUsecase := usecase.NewProjectMemberUsecase(repository.NewProjectMemberRepo(gormDB), projectRepo, userRepo)
 	deviceUsecase := usecase.NewDeviceUsecase(deviceRepo)
 	locationUsecase := usecase.NewLocationUsecase(locationRepo)
 	workTypeUsecase := usecase.NewWorkTypeUsecase(workTypeRepo)
-	troubleshootLogUsecase := usecase.NewTroubleshootLogUsecase(troubleshootLogRepo)
+	statsUsecase := usecase.NewStatsUsecase(statsRepo, config.StatsCacheTTL())
+	importUsecase := usecase.NewImportUsecase(repository.NewImportRepo(gormDB), roleRepo, userRepo)
+	reportScheduleUsecase := usecase.NewReportScheduleUsecase(reportScheduleRepo, projectRepo, messageSender)
+
+	if err := reportScheduleUsecase.Start(context.Background()); err != nil {
+		logrus.Fatalf("Failed to start report scheduler: %v", err)
+	}
+
+	sheetSyncUsecase := usecase.NewSheetSyncUsecase(repository.NewSheetSyncRepo(gormDB), sheetRepo)
+	sheetSyncUsecase.Start()
+
+	whatsappMediaUsecase := usecase.NewWhatsAppMediaUsecase(whatsappMediaJobRepo, attachmentUsecase)
+	whatsappMediaUsecase.Start()
+
+	metrics.RegisterDB(sqlDB)
+	metrics.RegisterOpenTickets(troubleshootLogRepo.CountOpen)
+
+	ipExtractor, err := newIPExtractor()
+	if err != nil {
+		logrus.Fatalf("Failed to init client IP extractor: %v", err)
+	}
 
 	e := echo.New()
+	e.IPExtractor = ipExtractor
+	e.HTTPErrorHandler = handlerHttp.ErrorHandler
+	e.Use(handlerHttp.RequestIDMiddleware)
+	e.Use(handlerHttp.AccessLogMiddleware)
+	e.Use(handlerHttp.MetricsMiddleware)
+
+	registerHandlers(e, httpHandlers{
+		auth:             handlerHttp.NewAuth(userUsecase, apiKeyUsecase, projectMemberUsecase),
+		user:             userUsecase,
+		role:             roleUsecase,
+		apiKey:           apiKeyUsecase,
+		project:          projectUsecase,
+		projectMember:    projectMemberUsecase,
+		device:           deviceUsecase,
+		location:         locationUsecase,
+		workType:         workTypeUsecase,
+		imports:          importUsecase,
+		troubleshootLog:  troubleshootLogUsecase,
+		attachment:       attachmentUsecase,
+		stats:            statsUsecase,
+		reportSchedule:   reportScheduleUsecase,
+		whatsappConsumer: whatsappConsumerUsecase,
+		keyManager:       keyManager,
+		healthChecks: []model.HealthCheck{
+			{Name: "database", Check: sqlDB.PingContext},
+			// Only the sheet sync uses the spreadsheet and it retries, the
+			// API keeps serving without it.
+			{Name: "spreadsheet", Check: sheetRepo.Ping, Optional: true},
+		},
+	})
 
-	handlerHttp.NewUserHandler(e, userUsecase)
-	handlerHttp.NewProjectHandler(e, projectUsecase)
-	handlerHttp.NewDeviceHandler(e, deviceUsecase)
-	handlerHttp.NewLocationHandler(e, locationUsecase)
-	handlerHttp.NewWorkTypeHandler(e, workTypeUsecase)
-	handlerHttp.NewTroubleshootLogHandler(e, troubleshootLogUsecase)
-	handlerHttp.NewWhatsAppWebhookHandler(e, whatsappConsumerUsecase)
+	if config.LogSenderHashKey() == "" {
+		logrus.Warn("log.sender_hash_key is not set, sender hashes in logs will not match across restarts or instances")
+	}
+
+	for _, problem := range handlerHttp.CheckOpenAPIRoutes(e.Routes()) {
+		logrus.Warn("OpenAPI document out of sync: ", problem)
+	}
 
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: []string{"http://localhost:5173"},
-		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
+		AllowOrigins:  []string{"http://localhost:5173"},
+		AllowHeaders:  []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, "X-API-Key", echo.HeaderXRequestID},
+		ExposeHeaders: []string{echo.HeaderXRequestID},
 	}))
 
-	var wg sync.WaitGroup
-	errCh := make(chan error, 2)
-	wg.Add(2)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
+	errCh := make(chan error, 1)
 	go func() {
-		defer wg.Done()
-		errCh <- e.Start(":3000")
+		errCh <- e.Start(config.HTTPAddress())
 	}()
 
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			logrus.Errorf("HTTP server error: %v", err)
+		}
+		reportScheduleUsecase.Stop()
+		sheetSyncUsecase.Stop()
+		whatsappMediaUsecase.Stop()
+		return
+	case <-ctx.Done():
+	}
+
+	logrus.Info("Shutting down, draining requests and background jobs")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.HTTPShutdownTimeout())
+	defer cancel()
+
+	if err := e.Shutdown(shutdownCtx); err != nil {
+		logrus.Error("Failed to drain HTTP requests: ", err)
+	}
+
+	stopped := make(chan struct{})
 	go func() {
-		defer wg.Done()
-		<-errCh
+		reportScheduleUsecase.Stop()
+		sheetSyncUsecase.Stop()
+		whatsappMediaUsecase.Stop()
+		close(stopped)
 	}()
 
-	wg.Wait()
+	select {
+	case <-stopped:
+		logrus.Info("Shutdown complete")
+	case <-shutdownCtx.Done():
+		logrus.Warn("Shutdown timed out before the background jobs stopped")
+	}
+}
+
+// httpHandlers holds what the HTTP handlers are built from. The openapi
+// command registers the routes with an empty set to inspect them.
+type httpHandlers struct {
+	auth             *handlerHttp.Auth
+	user             model.IUserUsecase
+	role             model.IRoleUsecase
+	apiKey           model.IAPIKeyUsecase
+	project          model.IProjectUsecase
+	projectMember    model.IProjectMemberUsecase
+	device           model.IDeviceUsecase
+	location         model.ILocationUsecase
+	workType         model.IWorkTypeUsecase
+	imports          model.IImportUsecase
+	troubleshootLog  model.ITroubleshootLogUsecase
+	attachment       model.IAttachmentUsecase
+	stats            model.IStatsUsecase
+	reportSchedule   model.IReportScheduleUsecase
+	whatsappConsumer *usecase.WhatsAppConsumerUsecase
+	keyManager       *helper.KeyManager
+	healthChecks     []model.HealthCheck
+}
 
-	if err := <-errCh; err != nil {
-		if err != http.ErrServerClosed {
-			logrus.Errorf("HTTP server error: %v", err)
+func registerHandlers(e *echo.Echo, h httpHandlers) {
+	handlerHttp.NewUserHandler(e, h.auth, h.user)
+	handlerHttp.NewRoleHandler(e, h.auth, h.role)
+	handlerHttp.NewAPIKeyHandler(e, h.auth, h.apiKey)
+	handlerHttp.NewProjectHandler(e, h.auth, h.project, h.projectMember)
+	handlerHttp.NewDeviceHandler(e, h.auth, h.device)
+	handlerHttp.NewLocationHandler(e, h.auth, h.location)
+	handlerHttp.NewWorkTypeHandler(e, h.auth, h.workType)
+	handlerHttp.NewImportHandler(e, h.auth, h.imports)
+	handlerHttp.NewTroubleshootLogHandler(e, h.auth, h.troubleshootLog)
+	handlerHttp.NewAttachmentHandler(e, h.auth, h.attachment)
+	handlerHttp.NewStatsHandler(e, h.auth, h.stats)
+	handlerHttp.NewReportScheduleHandler(e, h.auth, h.reportSchedule)
+	handlerHttp.NewWhatsAppWebhookHandler(e, h.auth, h.whatsappConsumer)
+	handlerHttp.NewJWKSHandler(e, h.keyManager)
+	handlerHttp.NewDocsHandler(e)
+	handlerHttp.NewHealthHandler(e, h.healthChecks)
+	handlerHttp.NewMetricsHandler(e)
+}
+
+func newFileStorage() (model.IFileStorage, error) {
+	switch config.StorageDriver() {
+	case "local":
+		return repository.NewLocalFileStorage(config.StorageLocalPath())
+	case "s3":
+		return repository.NewS3FileStorage(
+			config.StorageS3Endpoint(),
+			config.StorageS3AccessKey(),
+			config.StorageS3SecretKey(),
+			config.StorageS3Bucket(),
+			config.StorageS3Region(),
+			config.StorageS3UseSSL(),
+		)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", config.StorageDriver())
+	}
+}
+
+func newMessageSender() (model.IMessageSender, error) {
+	switch config.MessagingDriver() {
+	case "file":
+		return repository.NewFileMessageSender(config.MessagingFilePath())
+	case "http":
+		return repository.NewHTTPMessageSender(config.MessagingHTTPURL(), config.MessagingHTTPToken()), nil
+	default:
+		return nil, fmt.Errorf("unknown messaging driver %q", config.MessagingDriver())
+	}
+}
+
+func newSpreadsheet() (model.ISpreadsheetRepository, error) {
+	switch config.SpreadsheetDriver() {
+	case "google":
+		return repository.NewGoogleSheetRepository(
+			config.GetString("GOOGLE_CREDENTIAL"),
+			config.GetString("GOOGLE_SPREADSHEET_ID"),
+			config.GetString("GOOGLE_SHEET_NAME"),
+		)
+	case "file":
+		return repository.NewFileSheetRepository(config.SpreadsheetFilePath())
+	default:
+		return nil, fmt.Errorf("unknown spreadsheet driver %q", config.SpreadsheetDriver())
+	}
+}
+
+// newIPExtractor tells echo where the client IP, which the login throttling
+// and the access log use, comes from. Without trusted proxies it is the peer
+// address, X-Forwarded-For is only read from the configured proxies so a
+// client cannot pick its own IP.
+func newIPExtractor() (echo.IPExtractor, error) {
+	proxies := config.HTTPTrustedProxies()
+	if len(proxies) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
 		}
+
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		options = append(options, echo.TrustIPRange(ipNet))
 	}
+
+	return echo.ExtractIPFromXFFHeader(options...), nil
 }
@@ -0,0 +1,81 @@
+package console
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/config"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/helper"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(storageCheckCMD)
+}
+
+var storageCheckCMD = &cobra.Command{
+	Use:   "storage-check",
+	Short: "Check the configured attachment storage",
+	Long: `Write, read back and delete a probe object through the configured attachment storage.
+Point storage.driver at s3 and run docker-compose.minio.yml to check the S3 storage against a local MinIO.`,
+	Run: storageCheck,
+}
+
+func storageCheck(cmd *cobra.Command, args []string) {
+	config.LoadWithViper()
+
+	storage, err := newFileStorage()
+	if err != nil {
+		logrus.Fatalf("Failed to init file storage: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	suffix, err := helper.RandomToken(8)
+	if err != nil {
+		logrus.Fatalf("Failed to generate probe key: %v", err)
+	}
+	key := "storage-check/" + suffix + ".txt"
+	data := []byte("storage check " + time.Now().Format(time.RFC3339))
+
+	step := func(name string, err error) {
+		if err != nil {
+			fmt.Printf("%s\tFAILED\t%v\n", name, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s\tok\n", name)
+	}
+
+	step("put", storage.Put(ctx, key, "text/plain", data))
+
+	reader, err := storage.Get(ctx, key)
+	if err == nil {
+		var got []byte
+		got, err = io.ReadAll(reader)
+		reader.Close()
+		if err == nil && !bytes.Equal(got, data) {
+			err = fmt.Errorf("read back %d bytes that differ from the %d written", len(got), len(data))
+		}
+	}
+	step("get", err)
+
+	step("delete", storage.Delete(ctx, key))
+
+	reader, err = storage.Get(ctx, key)
+	if err == nil {
+		reader.Close()
+		err = fmt.Errorf("%s is still readable after delete", key)
+	} else {
+		err = nil
+	}
+	step("gone", err)
+
+	fmt.Printf("Storage driver %q works\n", config.StorageDriver())
+}
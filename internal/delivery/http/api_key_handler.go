@@ -0,0 +1,72 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+)
+
+type APIKeyHandler struct {
+	apiKeyUsecase model.IAPIKeyUsecase
+}
+
+func NewAPIKeyHandler(e *echo.Echo, auth *Auth, apiKeyUsecase model.IAPIKeyUsecase) {
+	handler := &APIKeyHandler{
+		apiKeyUsecase: apiKeyUsecase,
+	}
+
+	manage := RequirePermission(model.PermissionAPIKeyManage)
+
+	route := e.Group("v1/api-key")
+	route.POST("/create", handler.Create, auth.Middleware, manage)
+	route.GET("/", handler.FindAll, auth.Middleware, manage)
+	route.DELETE("/revoke/:id", handler.Revoke, auth.Middleware, manage)
+}
+
+func (handler *APIKeyHandler) Create(c echo.Context) error {
+	var body model.CreateAPIKeyInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	key, err := handler.apiKeyUsecase.Create(c.Request().Context(), body)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "API key created, store it now as it will not be shown again",
+		Data:    key,
+	})
+}
+
+func (handler *APIKeyHandler) FindAll(c echo.Context) error {
+	keys, err := handler.apiKeyUsecase.FindAll(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   keys,
+	})
+}
+
+func (handler *APIKeyHandler) Revoke(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	if err := handler.apiKeyUsecase.Revoke(c.Request().Context(), id); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "API key revoked successfully",
+	})
+}
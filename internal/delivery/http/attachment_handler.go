@@ -0,0 +1,138 @@
+package http
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/config"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AttachmentHandler struct {
+	attachmentUsecase model.IAttachmentUsecase
+}
+
+func NewAttachmentHandler(e *echo.Echo, auth *Auth, attachmentUsecase model.IAttachmentUsecase) {
+	handler := &AttachmentHandler{
+		attachmentUsecase: attachmentUsecase,
+	}
+
+	route := e.Group("v1/troubleshoot-log/:id/attachments")
+	route.POST("", handler.Upload, auth.Middleware, RequirePermission(model.PermissionTicketWrite))
+	route.GET("", handler.FindAll, auth.Middleware, RequirePermission(model.PermissionTicketRead))
+	route.GET("/:attachment_id", handler.Download, auth.Middleware, RequirePermission(model.PermissionTicketRead))
+	route.DELETE("/:attachment_id", handler.Delete, auth.Middleware, RequirePermission(model.PermissionTicketWrite))
+}
+
+func (h *AttachmentHandler) Upload(c echo.Context) error {
+	logID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "file is required")
+	}
+
+	if fileHeader.Size > config.AttachmentMaxSize() {
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "file is too large")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, config.AttachmentMaxSize()+1))
+	if err != nil {
+		return err
+	}
+
+	attachment, err := h.attachmentUsecase.Upload(c.Request().Context(), model.UploadAttachmentInput{
+		TroubleshootLogID: logID,
+		FileName:          fileHeader.Filename,
+		Source:            model.AttachmentSourceUpload,
+		Data:              data,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, Response{
+		Status:  http.StatusCreated,
+		Message: "Attachment uploaded successfully",
+		Data:    attachment,
+	})
+}
+
+func (h *AttachmentHandler) FindAll(c echo.Context) error {
+	logID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	attachments, err := h.attachmentUsecase.FindByTroubleshootLogID(c.Request().Context(), logID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   attachments,
+	})
+}
+
+func (h *AttachmentHandler) Download(c echo.Context) error {
+	logID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	id, err := strconv.ParseInt(c.Param("attachment_id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	thumbnail := c.QueryParam("thumbnail") == "true"
+
+	attachment, file, err := h.attachmentUsecase.Open(c.Request().Context(), logID, id, thumbnail)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	contentType := attachment.ContentType
+	if thumbnail {
+		contentType = "image/jpeg"
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("inline; filename=%q", attachment.FileName))
+	return c.Stream(http.StatusOK, contentType, file)
+}
+
+func (h *AttachmentHandler) Delete(c echo.Context) error {
+	logID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	id, err := strconv.ParseInt(c.Param("attachment_id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	if err := h.attachmentUsecase.Delete(c.Request().Context(), logID, id); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Attachment deleted successfully",
+	})
+}
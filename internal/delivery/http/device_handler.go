@@ -12,17 +12,20 @@ type DeviceHandler struct {
 	deviceUsecase model.IDeviceUsecase
 }
 
-func NewDeviceHandler(e *echo.Echo, deviceUsecase model.IDeviceUsecase) {
+func NewDeviceHandler(e *echo.Echo, auth *Auth, deviceUsecase model.IDeviceUsecase) {
 	handler := &DeviceHandler{
 		deviceUsecase: deviceUsecase,
 	}
 
 	route := e.Group("v1/device")
-	route.POST("/create", handler.Create, AuthMiddleware)
-	route.GET("/", handler.FindAll, AuthMiddleware)
-	route.GET("/:id", handler.FindByID, AuthMiddleware)
-	route.PUT("/update/:id", handler.Update, AuthMiddleware)
-	route.DELETE("/delete/:id", handler.Delete, AuthMiddleware)
+	route.POST("/create", handler.Create, auth.Middleware, RequirePermission(model.PermissionMasterDataWrite))
+	route.GET("/", handler.FindAll, auth.Middleware, RequirePermission(model.PermissionMasterDataRead))
+	route.GET("/:id", handler.FindByID, auth.Middleware, RequirePermission(model.PermissionMasterDataRead))
+	route.PUT("/update/:id", handler.Update, auth.Middleware, RequirePermission(model.PermissionMasterDataWrite))
+	route.DELETE("/delete/:id", handler.Delete, auth.Middleware, RequirePermission(model.PermissionMasterDataWrite))
+	route.GET("/trash", handler.FindTrash, auth.Middleware, RequirePermission(model.PermissionMasterDataWrite))
+	route.POST("/restore/:id", handler.Restore, auth.Middleware, RequirePermission(model.PermissionMasterDataWrite))
+	route.DELETE("/purge/:id", handler.Purge, auth.Middleware, RequirePermission(model.PermissionTrashPurge))
 }
 
 func (h *DeviceHandler) Create(c echo.Context) error {
@@ -33,7 +36,7 @@ func (h *DeviceHandler) Create(c echo.Context) error {
 
 	device, err := h.deviceUsecase.Create(c.Request().Context(), body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return c.JSON(http.StatusOK, Response{
@@ -44,14 +47,24 @@ func (h *DeviceHandler) Create(c echo.Context) error {
 }
 
 func (h *DeviceHandler) FindAll(c echo.Context) error {
-	devices, err := h.deviceUsecase.FindAll(c.Request().Context(), model.Device{})
+	page, err := parsePageRequest(c)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	filter := model.Device{
+		Name: c.QueryParam("name"),
+	}
+
+	devices, meta, err := h.deviceUsecase.FindAll(c.Request().Context(), filter, page)
+	if err != nil {
+		return err
 	}
 
 	return c.JSON(http.StatusOK, Response{
 		Status: http.StatusOK,
 		Data:   devices,
+		Meta:   meta,
 	})
 }
 
@@ -63,7 +76,7 @@ func (h *DeviceHandler) FindByID(c echo.Context) error {
 
 	device, err := h.deviceUsecase.FindByID(c.Request().Context(), id)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return c.JSON(http.StatusOK, Response{
@@ -90,7 +103,7 @@ func (h *DeviceHandler) Update(c echo.Context) error {
 
 	err = h.deviceUsecase.Update(c.Request().Context(), id, body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return c.JSON(http.StatusOK, Response{
@@ -111,9 +124,14 @@ func (h *DeviceHandler) Delete(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusUnauthorized)
 	}
 
-	err = h.deviceUsecase.Delete(c.Request().Context(), id)
+	replaceWith, err := queryInt64Ptr(c, "replace_with")
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	err = h.deviceUsecase.Delete(c.Request().Context(), id, replaceWith)
+	if err != nil {
+		return err
 	}
 
 	return c.JSON(http.StatusOK, Response{
@@ -121,3 +139,53 @@ func (h *DeviceHandler) Delete(c echo.Context) error {
 		Message: "Device deleted successfully",
 	})
 }
+
+func (h *DeviceHandler) FindTrash(c echo.Context) error {
+	page, err := parsePageRequest(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	devices, meta, err := h.deviceUsecase.FindTrash(c.Request().Context(), page)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   devices,
+		Meta:   meta,
+	})
+}
+
+func (h *DeviceHandler) Restore(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	if err := h.deviceUsecase.Restore(c.Request().Context(), id); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Device restored successfully",
+	})
+}
+
+func (h *DeviceHandler) Purge(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	if err := h.deviceUsecase.Purge(c.Request().Context(), id); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Device purged successfully",
+	})
+}
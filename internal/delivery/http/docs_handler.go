@@ -0,0 +1,53 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Log Troubleshoot API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "/docs/openapi.json", dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>
+`
+
+type DocsHandler struct {
+	spec []byte
+}
+
+// NewDocsHandler serves the OpenAPI document and a Swagger UI page that
+// renders it. The document is built once at startup.
+func NewDocsHandler(e *echo.Echo) {
+	spec, err := json.Marshal(BuildOpenAPI())
+	if err != nil {
+		panic(err)
+	}
+
+	handler := &DocsHandler{
+		spec: spec,
+	}
+
+	e.GET("docs", handler.UI)
+	e.GET("docs/openapi.json", handler.Spec)
+}
+
+func (handler *DocsHandler) UI(c echo.Context) error {
+	return c.HTML(http.StatusOK, swaggerUIPage)
+}
+
+func (handler *DocsHandler) Spec(c echo.Context) error {
+	return c.JSONBlob(http.StatusOK, handler.spec)
+}
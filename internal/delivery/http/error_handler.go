@@ -0,0 +1,119 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+)
+
+// ErrorResponse is the body of every failed request.
+type ErrorResponse struct {
+	Status  int                `json:"status"`
+	Code    string             `json:"code"`
+	Message string             `json:"message"`
+	Errors  []model.FieldError `json:"errors,omitempty"`
+	Details interface{}        `json:"details,omitempty"`
+}
+
+const codeValidationFailed = "validation_failed"
+
+// ErrorHandler is the Echo HTTPErrorHandler. Handlers return usecase errors
+// as they are and the status code is picked here from the error kind.
+// Unexpected errors are logged and answered with a generic 500 so internal
+// details do not leak.
+func ErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	body := errorResponse(err)
+
+	if body.Status >= http.StatusInternalServerError {
+		logrus.WithContext(c.Request().Context()).WithFields(logrus.Fields{
+			"method": c.Request().Method,
+			"path":   c.Path(),
+		}).Error("Request failed: ", err)
+	}
+
+	var throttled *model.LoginThrottledError
+	if errors.As(err, &throttled) {
+		c.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+	}
+
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(body.Status)
+	} else {
+		err = c.JSON(body.Status, body)
+	}
+	if err != nil {
+		logrus.WithContext(c.Request().Context()).Error("Failed to write error response: ", err)
+	}
+}
+
+func errorResponse(err error) ErrorResponse {
+	var (
+		httpErr   *echo.HTTPError
+		domainErr *model.DomainError
+		fieldErrs validator.ValidationErrors
+		throttled *model.LoginThrottledError
+	)
+
+	switch {
+	case errors.As(err, &fieldErrs):
+		body := newErrorResponse(http.StatusBadRequest, "validation failed")
+		body.Code = codeValidationFailed
+		body.Errors = model.ValidationFieldErrors(fieldErrs)
+		return body
+	case errors.As(err, &throttled):
+		return newErrorResponse(http.StatusTooManyRequests, throttled.Error())
+	case errors.As(err, &domainErr):
+		body := newErrorResponse(domainStatus(domainErr.Kind), err.Error())
+		if errors.Is(domainErr.Kind, model.ErrValidation) {
+			body.Code = codeValidationFailed
+		}
+		body.Errors = domainErr.Fields
+		body.Details = domainErr.Details
+		return body
+	case errors.As(err, &httpErr):
+		message := http.StatusText(httpErr.Code)
+		if httpErr.Message != nil {
+			message = fmt.Sprint(httpErr.Message)
+		}
+		return newErrorResponse(httpErr.Code, message)
+	default:
+		return newErrorResponse(http.StatusInternalServerError, "internal server error")
+	}
+}
+
+func newErrorResponse(status int, message string) ErrorResponse {
+	return ErrorResponse{
+		Status:  status,
+		Code:    strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_"),
+		Message: message,
+	}
+}
+
+func domainStatus(kind error) int {
+	switch {
+	case errors.Is(kind, model.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(kind, model.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(kind, model.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(kind, model.ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(kind, model.ErrValidation):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
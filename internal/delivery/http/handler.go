@@ -1,8 +1,13 @@
 package http
 
+import "github.com/tubagusmf/log-troubleshoot-be/internal/model"
+
 type Response struct {
-	Status      any         `json:"status,omitempty"`
-	Message     string      `json:"message,omitempty"`
-	Data        interface{} `json:"data,omitempty"`
-	AccessToken string      `json:"access_token,omitempty"`
+	Status       any             `json:"status,omitempty"`
+	Message      string          `json:"message,omitempty"`
+	Data         interface{}     `json:"data,omitempty"`
+	Meta         *model.PageMeta `json:"meta,omitempty"`
+	AccessToken  string          `json:"access_token,omitempty"`
+	RefreshToken string          `json:"refresh_token,omitempty"`
+	ExpiresIn    int64           `json:"expires_in,omitempty"`
 }
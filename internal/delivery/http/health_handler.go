@@ -0,0 +1,85 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// healthCheckTimeout bounds every readiness check so a hanging dependency
+// fails the probe instead of stalling it.
+const healthCheckTimeout = 3 * time.Second
+
+type HealthHandler struct {
+	checks []model.HealthCheck
+}
+
+// NewHealthHandler serves the probes of the process supervisor. /healthz
+// only tells the process is up, /readyz also checks the dependencies.
+func NewHealthHandler(e *echo.Echo, checks []model.HealthCheck) {
+	handler := &HealthHandler{
+		checks: checks,
+	}
+
+	e.GET("healthz", handler.Healthz)
+	e.GET("readyz", handler.Readyz)
+}
+
+func (handler *HealthHandler) Healthz(c echo.Context) error {
+	return c.JSON(http.StatusOK, model.HealthReport{Status: model.HealthStatusOK})
+}
+
+// Readyz runs the checks in parallel and answers 503 when a required one
+// fails. A failed optional check only marks the report degraded. The probes
+// are unauthenticated, so errors are only logged.
+func (handler *HealthHandler) Readyz(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(c.Request().Context(), healthCheckTimeout)
+	defer cancel()
+
+	report := model.HealthReport{
+		Status: model.HealthStatusOK,
+		Checks: make(map[string]string, len(handler.checks)),
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, check := range handler.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			result := model.HealthStatusOK
+			if err := check.Check(ctx); err != nil {
+				logrus.WithContext(ctx).WithField("check", check.Name).Warn("Readiness check failed: ", err)
+				result = model.HealthStatusUnavailable
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[check.Name] = result
+			switch {
+			case result == model.HealthStatusOK:
+			case !check.Optional:
+				report.Status = model.HealthStatusUnavailable
+			case report.Status == model.HealthStatusOK:
+				report.Status = model.HealthStatusDegraded
+			}
+		}()
+	}
+	wg.Wait()
+
+	status := http.StatusOK
+	if report.Status == model.HealthStatusUnavailable {
+		status = http.StatusServiceUnavailable
+	}
+
+	return c.JSON(status, report)
+}
@@ -0,0 +1,87 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/config"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ImportHandler struct {
+	importUsecase model.IImportUsecase
+}
+
+func NewImportHandler(e *echo.Echo, auth *Auth, importUsecase model.IImportUsecase) {
+	handler := &ImportHandler{
+		importUsecase: importUsecase,
+	}
+
+	e.POST("v1/project/import", handler.Import(model.ImportEntityProject), auth.Middleware, RequirePermission(model.PermissionMasterDataWrite))
+	e.POST("v1/location/import", handler.Import(model.ImportEntityLocation), auth.Middleware, RequirePermission(model.PermissionMasterDataWrite))
+	e.POST("v1/device/import", handler.Import(model.ImportEntityDevice), auth.Middleware, RequirePermission(model.PermissionMasterDataWrite))
+	e.POST("v1/work-type/import", handler.Import(model.ImportEntityWorkType), auth.Middleware, RequirePermission(model.PermissionMasterDataWrite))
+	e.POST("v1/auth/users/import", handler.Import(model.ImportEntityUser), auth.Middleware, RequirePermission(model.PermissionUserWrite))
+}
+
+// Import reads the CSV or XLSX file in the form field file. With
+// dry_run=true the report is returned without saving anything. A report
+// with failed rows is answered with 422.
+func (h *ImportHandler) Import(entity string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		dryRun := false
+		if value := c.QueryParam("dry_run"); value != "" {
+			var err error
+			if dryRun, err = strconv.ParseBool(value); err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, "invalid dry_run")
+			}
+		}
+
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "file is required")
+		}
+
+		if fileHeader.Size > config.ImportMaxSize() {
+			return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "file is too large")
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		defer file.Close()
+
+		data, err := io.ReadAll(io.LimitReader(file, config.ImportMaxSize()+1))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+
+		report, err := h.importUsecase.Import(c.Request().Context(), model.ImportInput{
+			Entity:   entity,
+			FileName: fileHeader.Filename,
+			Data:     data,
+			DryRun:   dryRun,
+		})
+		if err != nil {
+			return err
+		}
+
+		status, message := http.StatusOK, "Import committed"
+		switch {
+		case report.Failed > 0:
+			status, message = http.StatusUnprocessableEntity, "Import has failed rows, nothing was saved"
+		case report.DryRun:
+			message = "Dry run, nothing was saved"
+		}
+
+		return c.JSON(status, Response{
+			Status:  status,
+			Message: message,
+			Data:    report,
+		})
+	}
+}
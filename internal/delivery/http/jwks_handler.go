@@ -0,0 +1,27 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/helper"
+)
+
+type JWKSHandler struct {
+	keys *helper.KeyManager
+}
+
+// NewJWKSHandler publishes the public signing keys so other services can
+// verify access tokens without sharing a secret.
+func NewJWKSHandler(e *echo.Echo, keys *helper.KeyManager) {
+	handler := &JWKSHandler{
+		keys: keys,
+	}
+
+	e.GET(".well-known/jwks.json", handler.JWKS)
+}
+
+func (handler *JWKSHandler) JWKS(c echo.Context) error {
+	c.Response().Header().Set(echo.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(http.StatusOK, handler.keys.JWKS())
+}
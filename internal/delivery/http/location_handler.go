@@ -12,17 +12,20 @@ type LocationHandler struct {
 	locationUsecase model.ILocationUsecase
 }
 
-func NewLocationHandler(e *echo.Echo, locationUsecase model.ILocationUsecase) {
+func NewLocationHandler(e *echo.Echo, auth *Auth, locationUsecase model.ILocationUsecase) {
 	handler := &LocationHandler{
 		locationUsecase: locationUsecase,
 	}
 
 	route := e.Group("v1/location")
-	route.POST("/create", handler.Create, AuthMiddleware)
-	route.GET("/", handler.FindAll, AuthMiddleware)
-	route.GET("/:id", handler.FindByID, AuthMiddleware)
-	route.PUT("/update/:id", handler.Update, AuthMiddleware)
-	route.DELETE("/delete/:id", handler.Delete, AuthMiddleware)
+	route.POST("/create", handler.Create, auth.Middleware, RequirePermission(model.PermissionMasterDataWrite))
+	route.GET("/", handler.FindAll, auth.Middleware, RequirePermission(model.PermissionMasterDataRead))
+	route.GET("/:id", handler.FindByID, auth.Middleware, RequirePermission(model.PermissionMasterDataRead))
+	route.PUT("/update/:id", handler.Update, auth.Middleware, RequirePermission(model.PermissionMasterDataWrite))
+	route.DELETE("/delete/:id", handler.Delete, auth.Middleware, RequirePermission(model.PermissionMasterDataWrite))
+	route.GET("/trash", handler.FindTrash, auth.Middleware, RequirePermission(model.PermissionMasterDataWrite))
+	route.POST("/restore/:id", handler.Restore, auth.Middleware, RequirePermission(model.PermissionMasterDataWrite))
+	route.DELETE("/purge/:id", handler.Purge, auth.Middleware, RequirePermission(model.PermissionTrashPurge))
 }
 
 func (h *LocationHandler) Create(c echo.Context) error {
@@ -33,7 +36,7 @@ func (h *LocationHandler) Create(c echo.Context) error {
 
 	location, err := h.locationUsecase.Create(c.Request().Context(), body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return c.JSON(http.StatusOK, Response{
@@ -44,14 +47,25 @@ func (h *LocationHandler) Create(c echo.Context) error {
 }
 
 func (h *LocationHandler) FindAll(c echo.Context) error {
-	locations, err := h.locationUsecase.FindAll(c.Request().Context(), model.Location{})
+	page, err := parsePageRequest(c)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	filter := model.Location{
+		Name:     c.QueryParam("name"),
+		CodeName: c.QueryParam("code_name"),
+	}
+
+	locations, meta, err := h.locationUsecase.FindAll(c.Request().Context(), filter, page)
+	if err != nil {
+		return err
 	}
 
 	return c.JSON(http.StatusOK, Response{
 		Status: http.StatusOK,
 		Data:   locations,
+		Meta:   meta,
 	})
 }
 
@@ -63,7 +77,7 @@ func (h *LocationHandler) FindByID(c echo.Context) error {
 
 	location, err := h.locationUsecase.FindByID(c.Request().Context(), id)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return c.JSON(http.StatusOK, Response{
@@ -90,7 +104,7 @@ func (h *LocationHandler) Update(c echo.Context) error {
 
 	err = h.locationUsecase.Update(c.Request().Context(), id, body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return c.JSON(http.StatusOK, Response{
@@ -111,9 +125,14 @@ func (h *LocationHandler) Delete(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusUnauthorized)
 	}
 
-	err = h.locationUsecase.Delete(c.Request().Context(), id)
+	replaceWith, err := queryInt64Ptr(c, "replace_with")
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	err = h.locationUsecase.Delete(c.Request().Context(), id, replaceWith)
+	if err != nil {
+		return err
 	}
 
 	return c.JSON(http.StatusOK, Response{
@@ -121,3 +140,53 @@ func (h *LocationHandler) Delete(c echo.Context) error {
 		Message: "Location deleted successfully",
 	})
 }
+
+func (h *LocationHandler) FindTrash(c echo.Context) error {
+	page, err := parsePageRequest(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	locations, meta, err := h.locationUsecase.FindTrash(c.Request().Context(), page)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   locations,
+		Meta:   meta,
+	})
+}
+
+func (h *LocationHandler) Restore(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	if err := h.locationUsecase.Restore(c.Request().Context(), id); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Location restored successfully",
+	})
+}
+
+func (h *LocationHandler) Purge(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	if err := h.locationUsecase.Purge(c.Request().Context(), id); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Location purged successfully",
+	})
+}
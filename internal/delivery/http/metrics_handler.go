@@ -0,0 +1,43 @@
+package http
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/config"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/metrics"
+
+	"github.com/labstack/echo/v4"
+)
+
+type MetricsHandler struct {
+	handler http.Handler
+}
+
+// NewMetricsHandler serves the Prometheus metrics. The scraper must send
+// metrics.token as a bearer token, without one the metrics are only served
+// when metrics.public is set.
+func NewMetricsHandler(e *echo.Echo) {
+	handler := &MetricsHandler{
+		handler: metrics.Handler(),
+	}
+
+	e.GET("metrics", handler.Metrics)
+}
+
+func (h *MetricsHandler) Metrics(c echo.Context) error {
+	token := config.MetricsToken()
+	if token == "" && !config.MetricsPublic() {
+		return echo.NewHTTPError(http.StatusNotFound, "metrics are disabled, configure metrics.token")
+	}
+
+	if token != "" {
+		given := c.Request().Header.Get(echo.HeaderAuthorization)
+		if subtle.ConstantTimeCompare([]byte(given), []byte("Bearer "+token)) != 1 {
+			return echo.NewHTTPError(http.StatusUnauthorized, "invalid metrics token")
+		}
+	}
+
+	h.handler.ServeHTTP(c.Response(), c.Request())
+	return nil
+}
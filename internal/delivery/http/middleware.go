@@ -3,36 +3,244 @@ package http
 import (
 	"context"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/tubagusmf/log-troubleshoot-be/internal/helper"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/metrics"
 	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
 
 	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
 )
 
-func AuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+// TokenRevocationChecker reports whether an access token was revoked by
+// logout before it expired.
+type TokenRevocationChecker interface {
+	IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+// APIKeyAuthenticator resolves an API key into the claims it grants.
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, key string) (*model.CustomClaims, error)
+}
+
+// ProjectScopeResolver works out which projects the caller may see.
+type ProjectScopeResolver interface {
+	ResolveScope(ctx context.Context, claims *model.CustomClaims) (*model.ProjectScope, error)
+}
+
+// Auth authenticates requests and puts the caller's claims, and project
+// scope, on the request context. Handlers get it from their constructor and
+// put its middleware on the routes that need a caller.
+type Auth struct {
+	revocation TokenRevocationChecker
+	apiKeys    APIKeyAuthenticator
+	scopes     ProjectScopeResolver
+}
+
+// NewAuth builds the auth middleware. Without an APIKeyAuthenticator API
+// keys are refused, without a TokenRevocationChecker logged out tokens are
+// accepted until they expire and without a ProjectScopeResolver no project
+// scope is attached.
+func NewAuth(revocation TokenRevocationChecker, apiKeys APIKeyAuthenticator, scopes ProjectScopeResolver) *Auth {
+	return &Auth{
+		revocation: revocation,
+		apiKeys:    apiKeys,
+		scopes:     scopes,
+	}
+}
+
+// Middleware accepts an access token or an API key, sent either as a
+// Bearer token or in the X-API-Key header.
+func (a *Auth) Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return a.authenticate(next, false)
+}
+
+// APIKeyMiddleware only accepts API keys, for machine callers such as the
+// WhatsApp gateway.
+func (a *Auth) APIKeyMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return a.authenticate(next, true)
+}
+
+func (a *Auth) authenticate(next echo.HandlerFunc, apiKeyOnly bool) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		authHeader := c.Request().Header.Get(echo.HeaderAuthorization)
-		splitAuth := strings.Split(authHeader, " ")
-		if len(splitAuth) != 2 || splitAuth[0] != "Bearer" {
-			return echo.NewHTTPError(http.StatusUnauthorized, "invalid token")
+		accessToken := c.Request().Header.Get("X-API-Key")
+		if accessToken == "" {
+			authHeader := c.Request().Header.Get(echo.HeaderAuthorization)
+			splitAuth := strings.Split(authHeader, " ")
+			if len(splitAuth) != 2 || splitAuth[0] != "Bearer" {
+				return echo.NewHTTPError(http.StatusUnauthorized, "invalid token")
+			}
+
+			accessToken = splitAuth[1]
 		}
 
-		accessToken := splitAuth[1]
+		var claim *model.CustomClaims
+		if strings.HasPrefix(accessToken, model.APIKeyPrefix) {
+			if a.apiKeys == nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, "invalid token")
+			}
 
-		var claim model.CustomClaims
-		if err := helper.DecodeToken(accessToken, &claim); err != nil {
-			return echo.NewHTTPError(http.StatusUnauthorized, "invalid token")
+			var err error
+			claim, err = a.apiKeys.Authenticate(c.Request().Context(), accessToken)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, "invalid api key")
+			}
+		} else {
+			if apiKeyOnly {
+				return echo.NewHTTPError(http.StatusUnauthorized, "api key required")
+			}
+
+			claim = &model.CustomClaims{}
+			if err := helper.DecodeToken(accessToken, claim); err != nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, "invalid token")
+			}
+
+			if a.revocation != nil {
+				revoked, err := a.revocation.IsAccessTokenRevoked(c.Request().Context(), claim.ID)
+				if err != nil {
+					return echo.NewHTTPError(http.StatusInternalServerError, "failed to verify token")
+				}
+				if revoked {
+					return echo.NewHTTPError(http.StatusUnauthorized, "token has been revoked")
+				}
+			}
 		}
 
 		ctx := context.WithValue(
 			c.Request().Context(),
 			model.BearerAuthKey,
-			&claim,
+			claim,
 		)
 
+		if a.scopes != nil {
+			scope, err := a.scopes.ResolveScope(ctx, claim)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, "failed to resolve project access")
+			}
+			ctx = context.WithValue(ctx, model.ProjectScopeKey, scope)
+		}
+
 		c.SetRequest(c.Request().WithContext(ctx))
 		return next(c)
 	}
 }
+
+// RequirePermission rejects the request unless the authenticated user's role
+// grants the permission. It must run after the Auth middleware.
+func RequirePermission(permission string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claim, ok := c.Request().Context().Value(model.BearerAuthKey).(*model.CustomClaims)
+			if !ok || claim == nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, "invalid token")
+			}
+
+			if !claim.HasPermission(permission) {
+				return echo.NewHTTPError(http.StatusForbidden, "forbidden")
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// MetricsMiddleware records the latency and status of every request by its
+// route pattern. Errors are handed to the error handler here so the status
+// it writes is the one recorded.
+func MetricsMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+
+		if err := next(c); err != nil {
+			c.Error(err)
+		}
+
+		route := c.Path()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		metrics.HTTPRequestDuration.
+			WithLabelValues(c.Request().Method, route, strconv.Itoa(c.Response().Status)).
+			Observe(time.Since(start).Seconds())
+
+		return nil
+	}
+}
+
+// maxRequestIDLength bounds a caller supplied X-Request-ID, which ends up in
+// every log line of the request.
+const maxRequestIDLength = 64
+
+// RequestIDMiddleware tags the request with a correlation ID. The caller's
+// X-Request-ID is kept when it looks sane, otherwise one is generated. The
+// ID is echoed in the response and put on the request context, where the
+// logger picks it up in usecases and repositories.
+func RequestIDMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		id := c.Request().Header.Get(echo.HeaderXRequestID)
+		if !validRequestID(id) {
+			var err error
+			id, err = helper.RandomToken(12)
+			if err != nil {
+				return err
+			}
+		}
+
+		c.Response().Header().Set(echo.HeaderXRequestID, id)
+		ctx := context.WithValue(c.Request().Context(), model.RequestIDKey, id)
+		c.SetRequest(c.Request().WithContext(ctx))
+
+		return next(c)
+	}
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// AccessLogMiddleware logs one line per request. Probes and scrapes are
+// logged at debug level so they do not drown the rest.
+func AccessLogMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+
+		if err := next(c); err != nil {
+			c.Error(err)
+		}
+
+		route := c.Path()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		req := c.Request()
+		log := logrus.WithContext(req.Context()).WithFields(logrus.Fields{
+			"method":     req.Method,
+			"route":      route,
+			"status":     c.Response().Status,
+			"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+			"remote_ip":  c.RealIP(),
+		})
+
+		switch route {
+		case "/healthz", "/readyz", "/metrics":
+			log.Debug("Request handled")
+		default:
+			log.Info("Request handled")
+		}
+
+		return nil
+	}
+}
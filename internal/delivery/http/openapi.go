@@ -0,0 +1,458 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/helper"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+)
+
+// apiParam is a query parameter of an operation. Path parameters are taken
+// from the route itself.
+type apiParam struct {
+	Name        string
+	Type        string
+	Format      string
+	Description string
+	Required    bool
+}
+
+// apiOperation documents one registered route. Data is the payload of the
+// Response envelope; Raw replaces the envelope for handlers that return a
+// bare JSON value, and Files lists content types of download responses.
+// APIKeyOnly marks routes that refuse access tokens.
+type apiOperation struct {
+	Method     string
+	Path       string
+	Tag        string
+	Summary    string
+	Auth       bool
+	APIKeyOnly bool
+	Permission string
+	Query      []apiParam
+	Body       interface{}
+	Multipart  bool
+	Status     int
+	Data       interface{}
+	Meta       bool
+	Raw        interface{}
+	Files      []string
+}
+
+var pageParams = []apiParam{
+	{Name: "page", Type: "integer", Description: "Page number, starting at 1"},
+	{Name: "limit", Type: "integer", Description: "Page size, at most 100"},
+	{Name: "cursor", Type: "string", Description: "Switch to cursor paging; pass next_cursor of the previous page, empty for the first"},
+	{Name: "sort_by", Type: "string"},
+	{Name: "sort_dir", Type: "string", Description: "asc or desc"},
+}
+
+func withPage(params ...apiParam) []apiParam {
+	return append(params, pageParams...)
+}
+
+func dateRange(prefix string) []apiParam {
+	return []apiParam{
+		{Name: prefix + "_from", Type: "string", Description: "Inclusive, YYYY-MM-DD or RFC 3339"},
+		{Name: prefix + "_to", Type: "string", Description: "Exclusive, YYYY-MM-DD or RFC 3339"},
+	}
+}
+
+var troubleshootLogFilterParams = append([]apiParam{
+	{Name: "ticket_number", Type: "string"},
+	{Name: "status", Type: "string", Description: "Repeat or comma separate for several statuses"},
+	{Name: "project_id", Type: "integer"},
+	{Name: "location_id", Type: "integer"},
+	{Name: "device_id", Type: "integer"},
+	{Name: "work_type_id", Type: "integer"},
+	{Name: "assignee_id", Type: "integer"},
+	{Name: "reporter", Type: "string", Description: "WhatsApp sender"},
+	{Name: "part", Type: "string"},
+}, append(append(dateRange("trouble_date"), dateRange("done_date")...), dateRange("created")...)...)
+
+var statsParams = append(dateRange("date"),
+	apiParam{Name: "project_id", Type: "integer"},
+	apiParam{Name: "location_id", Type: "integer"},
+)
+
+type messageBody struct {
+	Message string `json:"message"`
+}
+
+// masterDataOperations documents the CRUD routes shared by the master-data
+// handlers.
+func masterDataOperations(path, tag string, create, update, item interface{}, filters ...apiParam) []apiOperation {
+	ops := []apiOperation{
+		{Method: http.MethodPost, Path: path + "/create", Tag: tag, Summary: "Create", Auth: true, Permission: model.PermissionMasterDataWrite, Body: create, Data: item},
+		{Method: http.MethodGet, Path: path + "/", Tag: tag, Summary: "List", Auth: true, Permission: model.PermissionMasterDataRead, Query: withPage(filters...), Data: []interface{}{item}, Meta: true},
+		{Method: http.MethodGet, Path: path + "/:id", Tag: tag, Summary: "Get by ID", Auth: true, Permission: model.PermissionMasterDataRead, Data: item},
+		{Method: http.MethodPut, Path: path + "/update/:id", Tag: tag, Summary: "Update", Auth: true, Permission: model.PermissionMasterDataWrite, Body: update, Data: update},
+		{Method: http.MethodDelete, Path: path + "/delete/:id", Tag: tag, Summary: "Delete; refused while open tickets use it", Auth: true, Permission: model.PermissionMasterDataWrite, Query: []apiParam{
+			{Name: "replace_with", Type: "integer", Description: "Move the open tickets to this record first"},
+		}},
+	}
+
+	return append(ops, trashOperations(path+"/trash", path, tag, model.PermissionMasterDataWrite, item)...)
+}
+
+// trashOperations documents the trash listing, restore and purge routes
+// that every soft-deleted entity has.
+func trashOperations(trashPath, itemPath, tag, permission string, item interface{}) []apiOperation {
+	return []apiOperation{
+		{Method: http.MethodGet, Path: trashPath, Tag: tag, Summary: "List deleted records", Auth: true, Permission: permission, Query: withPage(), Data: []interface{}{item}, Meta: true},
+		{Method: http.MethodPost, Path: itemPath + "/restore/:id", Tag: tag, Summary: "Restore a deleted record", Auth: true, Permission: permission},
+		{Method: http.MethodDelete, Path: itemPath + "/purge/:id", Tag: tag, Summary: "Permanently delete a record from the trash", Auth: true, Permission: model.PermissionTrashPurge},
+	}
+}
+
+func apiOperations() []apiOperation {
+	ops := []apiOperation{
+		{Method: http.MethodPost, Path: "/v1/auth/login", Tag: "auth", Summary: "Log in and get an access and refresh token", Body: model.LoginInput{}},
+		{Method: http.MethodPost, Path: "/v1/auth/refresh", Tag: "auth", Summary: "Exchange a refresh token for a new token pair", Body: model.RefreshTokenInput{}},
+		{Method: http.MethodPost, Path: "/v1/auth/logout", Tag: "auth", Summary: "Revoke the access token and, if given, the refresh token", Auth: true, Body: model.LogoutInput{}},
+		{Method: http.MethodPut, Path: "/v1/auth/password", Tag: "auth", Summary: "Change your own password", Auth: true, Body: model.ChangePasswordInput{}},
+		{Method: http.MethodPost, Path: "/v1/auth/password/reset", Tag: "auth", Summary: "Set a new password with a one-time reset token", Body: model.ResetPasswordInput{}},
+		{Method: http.MethodGet, Path: "/v1/auth/user/:id", Tag: "users", Summary: "Get a user", Auth: true, Permission: model.PermissionUserRead, Data: model.User{}},
+		{Method: http.MethodGet, Path: "/v1/auth/users", Tag: "users", Summary: "List users", Auth: true, Permission: model.PermissionUserRead, Query: withPage(
+			apiParam{Name: "username", Type: "string"},
+			apiParam{Name: "code_name", Type: "string"},
+			apiParam{Name: "role", Type: "string"},
+		), Data: []model.User{}, Meta: true},
+		{Method: http.MethodPost, Path: "/v1/auth/register", Tag: "users", Summary: "Create a user; assigning a role beyond the caller's own permissions needs role:manage", Auth: true, Permission: model.PermissionUserWrite, Body: model.CreateUserInput{}, Data: model.User{}},
+		{Method: http.MethodPut, Path: "/v1/auth/user/update/:id", Tag: "users", Summary: "Update a user's profile", Auth: true, Permission: model.PermissionUserWrite, Body: model.UpdateUserInput{}, Data: model.UpdateUserInput{}},
+		{Method: http.MethodDelete, Path: "/v1/auth/user/delete/:id", Tag: "users", Summary: "Delete a user", Auth: true, Permission: model.PermissionUserWrite},
+		{Method: http.MethodPost, Path: "/v1/auth/user/:id/password-reset", Tag: "users", Summary: "Issue a one-time password reset token", Auth: true, Permission: model.PermissionUserWrite, Data: model.PasswordReset{}},
+		{Method: http.MethodPost, Path: "/v1/auth/user/:id/unlock", Tag: "users", Summary: "Clear failed logins and lockout", Auth: true, Permission: model.PermissionUserWrite},
+
+		{Method: http.MethodPost, Path: "/v1/role/create", Tag: "roles", Summary: "Create a role", Auth: true, Permission: model.PermissionRoleManage, Body: model.RoleInput{}, Data: model.Role{}},
+		{Method: http.MethodGet, Path: "/v1/role/", Tag: "roles", Summary: "List roles", Auth: true, Permission: model.PermissionRoleManage, Data: []model.Role{}},
+		{Method: http.MethodGet, Path: "/v1/role/permissions", Tag: "roles", Summary: "List permissions", Auth: true, Permission: model.PermissionRoleManage, Data: []model.Permission{}},
+		{Method: http.MethodGet, Path: "/v1/role/:id", Tag: "roles", Summary: "Get a role", Auth: true, Permission: model.PermissionRoleManage, Data: model.Role{}},
+		{Method: http.MethodPut, Path: "/v1/role/update/:id", Tag: "roles", Summary: "Update a role", Auth: true, Permission: model.PermissionRoleManage, Body: model.RoleInput{}, Data: model.RoleInput{}},
+		{Method: http.MethodDelete, Path: "/v1/role/delete/:id", Tag: "roles", Summary: "Delete a role", Auth: true, Permission: model.PermissionRoleManage},
+
+		{Method: http.MethodPost, Path: "/v1/api-key/create", Tag: "api keys", Summary: "Issue an API key; the key is only returned here", Auth: true, Permission: model.PermissionAPIKeyManage, Body: model.CreateAPIKeyInput{}, Data: model.IssuedAPIKey{}},
+		{Method: http.MethodGet, Path: "/v1/api-key/", Tag: "api keys", Summary: "List API keys", Auth: true, Permission: model.PermissionAPIKeyManage, Data: []model.APIKey{}},
+		{Method: http.MethodDelete, Path: "/v1/api-key/revoke/:id", Tag: "api keys", Summary: "Revoke an API key", Auth: true, Permission: model.PermissionAPIKeyManage},
+
+		{Method: http.MethodGet, Path: "/v1/project/:id/members", Tag: "projects", Summary: "List project members", Auth: true, Permission: model.PermissionUserRead, Data: []model.User{}},
+		{Method: http.MethodPost, Path: "/v1/project/:id/members", Tag: "projects", Summary: "Add a project member", Auth: true, Permission: model.PermissionUserWrite, Body: model.ProjectMemberInput{}},
+		{Method: http.MethodDelete, Path: "/v1/project/:id/members/:user_id", Tag: "projects", Summary: "Remove a project member", Auth: true, Permission: model.PermissionUserWrite},
+
+		{Method: http.MethodPost, Path: "/v1/troubleshoot-log/create", Tag: "troubleshoot logs", Summary: "Create a ticket", Auth: true, Permission: model.PermissionTicketWrite, Body: model.CreateTroubleshootLogInput{}, Status: http.StatusCreated, Raw: model.TroubleshootLog{}},
+		{Method: http.MethodGet, Path: "/v1/troubleshoot-log/", Tag: "troubleshoot logs", Summary: "List tickets", Auth: true, Permission: model.PermissionTicketRead, Query: withPage(troubleshootLogFilterParams...), Data: []model.TroubleshootLog{}, Meta: true},
+		{Method: http.MethodGet, Path: "/v1/troubleshoot-log/search", Tag: "troubleshoot logs", Summary: "Full-text search", Auth: true, Permission: model.PermissionTicketRead, Query: []apiParam{
+			{Name: "q", Type: "string", Required: true},
+			{Name: "page", Type: "integer"},
+			{Name: "limit", Type: "integer"},
+		}, Raw: []model.TroubleshootLogSearchResult{}},
+		{Method: http.MethodGet, Path: "/v1/troubleshoot-log/recurring", Tag: "troubleshoot logs", Summary: "Devices and parts that keep failing", Auth: true, Permission: model.PermissionTicketRead, Query: append(dateRange("trouble_date"),
+			apiParam{Name: "project_id", Type: "integer"},
+			apiParam{Name: "location_id", Type: "integer"},
+			apiParam{Name: "per_location", Type: "integer"},
+		), Raw: []model.RepeatOffender{}},
+		{Method: http.MethodGet, Path: "/v1/troubleshoot-log/export", Tag: "troubleshoot logs", Summary: "Export tickets", Auth: true, Permission: model.PermissionTicketRead, Query: append([]apiParam{
+			{Name: "format", Type: "string", Description: "csv (default), xlsx or pdf; pdf is limited to export.pdf_max_rows tickets (5000 by default)"},
+			{Name: "sort_by", Type: "string"},
+			{Name: "sort_dir", Type: "string", Description: "asc or desc"},
+		}, troubleshootLogFilterParams...), Files: []string{
+			exportContentTypes[model.ExportFormatCSV],
+			exportContentTypes[model.ExportFormatXLSX],
+			exportContentTypes[model.ExportFormatPDF],
+		}},
+		{Method: http.MethodGet, Path: "/v1/troubleshoot-log/:id", Tag: "troubleshoot logs", Summary: "Get a ticket", Auth: true, Permission: model.PermissionTicketRead, Raw: model.TroubleshootLog{}},
+		{Method: http.MethodPut, Path: "/v1/troubleshoot-log/update/:id", Tag: "troubleshoot logs", Summary: "Update a ticket; closing it needs ticket:close", Auth: true, Permission: model.PermissionTicketWrite, Body: model.UpdateTroubleshootLogInput{}, Raw: messageBody{}},
+		{Method: http.MethodDelete, Path: "/v1/troubleshoot-log/delete/:id", Tag: "troubleshoot logs", Summary: "Delete a ticket", Auth: true, Permission: model.PermissionTicketDelete, Raw: messageBody{}},
+
+		{Method: http.MethodPost, Path: "/v1/troubleshoot-log/:id/attachments", Tag: "attachments", Summary: "Upload an attachment (form field file)", Auth: true, Permission: model.PermissionTicketWrite, Multipart: true, Data: model.Attachment{}},
+		{Method: http.MethodGet, Path: "/v1/troubleshoot-log/:id/attachments", Tag: "attachments", Summary: "List attachments", Auth: true, Permission: model.PermissionTicketRead, Data: []model.Attachment{}},
+		{Method: http.MethodGet, Path: "/v1/troubleshoot-log/:id/attachments/:attachment_id", Tag: "attachments", Summary: "Download an attachment", Auth: true, Permission: model.PermissionTicketRead, Query: []apiParam{
+			{Name: "thumbnail", Type: "boolean", Description: "Return the image thumbnail"},
+		}, Files: []string{"application/octet-stream"}},
+		{Method: http.MethodDelete, Path: "/v1/troubleshoot-log/:id/attachments/:attachment_id", Tag: "attachments", Summary: "Delete an attachment", Auth: true, Permission: model.PermissionTicketWrite},
+
+		{Method: http.MethodGet, Path: "/v1/stats/summary", Tag: "stats", Summary: "Ticket totals and MTTR", Auth: true, Permission: model.PermissionStatsRead, Query: statsParams, Data: model.StatsSummary{}},
+		{Method: http.MethodGet, Path: "/v1/stats/breakdown", Tag: "stats", Summary: "Totals grouped by a dimension", Auth: true, Permission: model.PermissionStatsRead, Query: append(statsParams,
+			apiParam{Name: "by", Type: "string", Description: "status, project, location, device, work_type or part"},
+		), Data: []model.StatsGroup{}},
+		{Method: http.MethodGet, Path: "/v1/stats/timeseries", Tag: "stats", Summary: "Totals per period", Auth: true, Permission: model.PermissionStatsRead, Query: append(statsParams,
+			apiParam{Name: "interval", Type: "string", Description: "day, week or month"},
+		), Data: []model.StatsTimeSeriesPoint{}},
+
+		{Method: http.MethodPost, Path: "/v1/report-schedule/create", Tag: "report schedules", Summary: "Create a schedule", Auth: true, Permission: model.PermissionReportManage, Body: model.ReportScheduleInput{}, Data: model.ReportSchedule{}},
+		{Method: http.MethodGet, Path: "/v1/report-schedule/", Tag: "report schedules", Summary: "List schedules", Auth: true, Permission: model.PermissionReportManage, Data: []model.ReportSchedule{}},
+		{Method: http.MethodGet, Path: "/v1/report-schedule/:id", Tag: "report schedules", Summary: "Get a schedule", Auth: true, Permission: model.PermissionReportManage, Data: model.ReportSchedule{}},
+		{Method: http.MethodPut, Path: "/v1/report-schedule/update/:id", Tag: "report schedules", Summary: "Update a schedule", Auth: true, Permission: model.PermissionReportManage, Body: model.ReportScheduleInput{}, Data: model.ReportSchedule{}},
+		{Method: http.MethodDelete, Path: "/v1/report-schedule/delete/:id", Tag: "report schedules", Summary: "Delete a schedule", Auth: true, Permission: model.PermissionReportManage},
+		{Method: http.MethodPost, Path: "/v1/report-schedule/:id/run", Tag: "report schedules", Summary: "Send the report now", Auth: true, Permission: model.PermissionReportManage, Query: []apiParam{
+			{Name: "period_end", Type: "string", Format: "date-time", Description: "Regenerate the report of an earlier period"},
+		}, Data: model.ReportDelivery{}},
+		{Method: http.MethodGet, Path: "/v1/report-schedule/:id/deliveries", Tag: "report schedules", Summary: "Delivery history", Auth: true, Permission: model.PermissionReportManage, Data: []model.ReportDelivery{}},
+		{Method: http.MethodPost, Path: "/v1/report-schedule/delivery/:id/resend", Tag: "report schedules", Summary: "Resend a delivery", Auth: true, Permission: model.PermissionReportManage, Data: model.ReportDelivery{}},
+
+		{Method: http.MethodPost, Path: "/webhook/whatsapp", Tag: "webhooks", Summary: "Receive a WhatsApp group message; the gateway authenticates with an API key. Media is attached in the background", Auth: true, APIKeyOnly: true, Permission: model.PermissionWhatsAppWebhook, Body: model.WhatsAppWebhookRequest{}, Raw: messageBody{}},
+		{Method: http.MethodGet, Path: "/.well-known/jwks.json", Tag: "auth", Summary: "Public keys that verify access tokens", Raw: helper.JWKSet{}},
+		{Method: http.MethodGet, Path: "/docs", Tag: "docs", Summary: "API reference", Files: []string{"text/html"}},
+		{Method: http.MethodGet, Path: "/docs/openapi.json", Tag: "docs", Summary: "This document", Raw: map[string]interface{}{}},
+		{Method: http.MethodGet, Path: "/healthz", Tag: "health", Summary: "Liveness probe, answers while the process is up", Raw: model.HealthReport{}},
+		{Method: http.MethodGet, Path: "/metrics", Tag: "health", Summary: "Prometheus metrics; needs the metrics token as bearer token, disabled without one unless metrics.public is set", Files: []string{"text/plain"}},
+		{Method: http.MethodGet, Path: "/readyz", Tag: "health", Summary: "Readiness probe, 503 when the database is unreachable, degraded when the spreadsheet is", Raw: model.HealthReport{}},
+	}
+
+	ops = append(ops, masterDataOperations("/v1/project", "projects", model.CreateProjectInput{}, model.UpdateProjectInput{}, model.Project{},
+		apiParam{Name: "name", Type: "string"})...)
+	ops = append(ops, masterDataOperations("/v1/device", "devices", model.CreateDeviceInput{}, model.UpdateDeviceInput{}, model.Device{},
+		apiParam{Name: "name", Type: "string"})...)
+	ops = append(ops, masterDataOperations("/v1/location", "locations", model.CreateLocationInput{}, model.UpdateLocationInput{}, model.Location{},
+		apiParam{Name: "name", Type: "string"}, apiParam{Name: "code_name", Type: "string"})...)
+	ops = append(ops, masterDataOperations("/v1/work-type", "work types", model.CreateWorkTypeInput{}, model.UpdateWorkTypeInput{}, model.WorkType{},
+		apiParam{Name: "name", Type: "string"})...)
+
+	for _, target := range []struct{ path, tag, permission string }{
+		{"/v1/project", "projects", model.PermissionMasterDataWrite},
+		{"/v1/location", "locations", model.PermissionMasterDataWrite},
+		{"/v1/device", "devices", model.PermissionMasterDataWrite},
+		{"/v1/work-type", "work types", model.PermissionMasterDataWrite},
+		{"/v1/auth/users", "users", model.PermissionUserWrite},
+	} {
+		ops = append(ops, apiOperation{Method: http.MethodPost, Path: target.path + "/import", Tag: target.tag, Summary: "Import a CSV or XLSX file (form field file); 422 lists the failed rows", Auth: true, Permission: target.permission, Multipart: true, Query: []apiParam{
+			{Name: "dry_run", Type: "boolean", Description: "Report what the import would do without saving"},
+		}, Data: model.ImportReport{}})
+	}
+
+	ops = append(ops, trashOperations("/v1/auth/users/trash", "/v1/auth/user", "users", model.PermissionUserWrite, model.User{})...)
+	ops = append(ops, trashOperations("/v1/troubleshoot-log/trash", "/v1/troubleshoot-log", "troubleshoot logs", model.PermissionTicketDelete, model.TroubleshootLog{})...)
+	ops = append(ops, trashOperations("/v1/report-schedule/trash", "/v1/report-schedule", "report schedules", model.PermissionReportManage, model.ReportSchedule{})...)
+
+	return ops
+}
+
+var routeParamPattern = regexp.MustCompile(`:([A-Za-z0-9_]+)`)
+
+func openAPIPath(path string) string {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return routeParamPattern.ReplaceAllString(path, "{$1}")
+}
+
+// BuildOpenAPI renders the OpenAPI 3 document of every documented route.
+func BuildOpenAPI() map[string]interface{} {
+	registry := newSchemaRegistry()
+	paths := map[string]map[string]interface{}{}
+
+	failure := map[string]interface{}{
+		"description": "Error; status and code follow the HTTP status, validation errors list the rejected fields",
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{
+				"schema": registry.schemaOf(ErrorResponse{}),
+			},
+		},
+	}
+
+	for _, op := range apiOperations() {
+		path := openAPIPath(op.Path)
+		if paths[path] == nil {
+			paths[path] = map[string]interface{}{}
+		}
+
+		var parameters []interface{}
+		for _, match := range routeParamPattern.FindAllStringSubmatch(op.Path, -1) {
+			parameters = append(parameters, map[string]interface{}{
+				"name":     match[1],
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]interface{}{"type": "integer", "format": "int64"},
+			})
+		}
+		for _, param := range op.Query {
+			schema := map[string]interface{}{"type": param.Type}
+			if param.Format != "" {
+				schema["format"] = param.Format
+			}
+			parameter := map[string]interface{}{
+				"name":     param.Name,
+				"in":       "query",
+				"required": param.Required,
+				"schema":   schema,
+			}
+			if param.Description != "" {
+				parameter["description"] = param.Description
+			}
+			parameters = append(parameters, parameter)
+		}
+
+		operation := map[string]interface{}{
+			"tags":        []string{op.Tag},
+			"summary":     op.Summary,
+			"operationId": strings.ToLower(op.Method) + routeParamPattern.ReplaceAllString(op.Path, "by_$1"),
+			"responses": map[string]interface{}{
+				fmt.Sprint(successStatus(op)): successResponse(registry, op),
+				"default":                     failure,
+			},
+		}
+
+		if len(parameters) > 0 {
+			operation["parameters"] = parameters
+		}
+
+		switch {
+		case op.APIKeyOnly:
+			operation["security"] = []interface{}{
+				map[string]interface{}{"apiKeyAuth": []string{}},
+			}
+		case op.Auth:
+			operation["security"] = []interface{}{
+				map[string]interface{}{"bearerAuth": []string{}},
+				map[string]interface{}{"apiKeyAuth": []string{}},
+			}
+		}
+
+		if op.Permission != "" {
+			operation["description"] = "Requires the `" + op.Permission + "` permission."
+			operation["x-permission"] = op.Permission
+		}
+
+		switch {
+		case op.Multipart:
+			operation["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					"multipart/form-data": map[string]interface{}{
+						"schema": map[string]interface{}{
+							"type":     "object",
+							"required": []string{"file"},
+							"properties": map[string]interface{}{
+								"file": map[string]interface{}{"type": "string", "format": "binary"},
+							},
+						},
+					},
+				},
+			}
+		case op.Body != nil:
+			operation["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{
+						"schema": registry.schemaOf(op.Body),
+					},
+				},
+			}
+		}
+
+		paths[path][strings.ToLower(op.Method)] = operation
+	}
+
+	registry.schemaOf(Response{})
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "Log Troubleshoot API",
+			"version":     "1.0.0",
+			"description": "Troubleshooting tickets reported from WhatsApp groups, with master data, statistics and reports.",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": registry.schemas,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{
+					"type":         "http",
+					"scheme":       "bearer",
+					"bearerFormat": "JWT",
+				},
+				"apiKeyAuth": map[string]interface{}{
+					"type": "apiKey",
+					"in":   "header",
+					"name": "X-API-Key",
+				},
+			},
+		},
+	}
+}
+
+func successStatus(op apiOperation) int {
+	if op.Status != 0 {
+		return op.Status
+	}
+	return http.StatusOK
+}
+
+func successResponse(registry *schemaRegistry, op apiOperation) map[string]interface{} {
+	response := map[string]interface{}{"description": http.StatusText(successStatus(op))}
+
+	switch {
+	case len(op.Files) > 0:
+		content := map[string]interface{}{}
+		for _, contentType := range op.Files {
+			content[contentType] = map[string]interface{}{
+				"schema": map[string]interface{}{"type": "string", "format": "binary"},
+			}
+		}
+		response["content"] = content
+	case op.Raw != nil:
+		response["content"] = map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": registry.schemaOf(op.Raw)},
+		}
+	default:
+		envelope := map[string]interface{}{"$ref": "#/components/schemas/Response"}
+		properties := map[string]interface{}{}
+		if op.Data != nil {
+			properties["data"] = registry.schemaOf(op.Data)
+		}
+		if op.Meta {
+			properties["meta"] = registry.schemaOf(model.PageMeta{})
+		}
+
+		schema := envelope
+		if len(properties) > 0 {
+			schema = map[string]interface{}{
+				"allOf": []interface{}{envelope, map[string]interface{}{
+					"type":       "object",
+					"properties": properties,
+				}},
+			}
+		}
+
+		response["content"] = map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": schema},
+		}
+	}
+
+	return response
+}
+
+// CheckOpenAPIRoutes compares the registered routes with the document and
+// describes every route that is registered but undocumented, or documented
+// but no longer registered.
+func CheckOpenAPIRoutes(routes []*echo.Route) []string {
+	documented := map[string]bool{}
+	for _, op := range apiOperations() {
+		documented[op.Method+" "+openAPIPath(op.Path)] = true
+	}
+
+	registered := map[string]bool{}
+	for _, route := range routes {
+		registered[route.Method+" "+openAPIPath(route.Path)] = true
+	}
+
+	var problems []string
+	for route := range registered {
+		if !documented[route] {
+			problems = append(problems, "undocumented route "+route)
+		}
+	}
+	for route := range documented {
+		if !registered[route] {
+			problems = append(problems, "documented route is not registered: "+route)
+		}
+	}
+
+	sort.Strings(problems)
+	return problems
+}
@@ -0,0 +1,192 @@
+package http
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schemaRegistry turns Go types into OpenAPI schemas. Named structs are
+// stored once under components/schemas and referenced from operations, so
+// the document follows the DTOs in the model package as they change.
+type schemaRegistry struct {
+	schemas map[string]map[string]interface{}
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{schemas: map[string]map[string]interface{}{}}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (r *schemaRegistry) schemaOf(value interface{}) map[string]interface{} {
+	return r.schemaFor(reflect.TypeOf(value))
+}
+
+func (r *schemaRegistry) schemaFor(t reflect.Type) map[string]interface{} {
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		nullable = true
+	}
+
+	var schema map[string]interface{}
+
+	switch {
+	case t == timeType:
+		schema = map[string]interface{}{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		r.register(t)
+		ref := map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+		if nullable {
+			return map[string]interface{}{"allOf": []interface{}{ref}, "nullable": true}
+		}
+		return ref
+	case t.Kind() == reflect.Struct:
+		schema = r.structSchema(t)
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		schema = map[string]interface{}{"type": "string", "format": "byte"}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		schema = map[string]interface{}{"type": "array", "items": r.schemaFor(t.Elem())}
+	case t.Kind() == reflect.Map:
+		schema = map[string]interface{}{"type": "object", "additionalProperties": r.schemaFor(t.Elem())}
+	case t.Kind() == reflect.String:
+		schema = map[string]interface{}{"type": "string"}
+	case t.Kind() == reflect.Bool:
+		schema = map[string]interface{}{"type": "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		schema = map[string]interface{}{"type": "integer"}
+		if t.Kind() == reflect.Int64 || t.Kind() == reflect.Uint64 {
+			schema["format"] = "int64"
+		}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		schema = map[string]interface{}{"type": "number"}
+	default:
+		schema = map[string]interface{}{}
+	}
+
+	if nullable {
+		schema["nullable"] = true
+	}
+
+	return schema
+}
+
+func (r *schemaRegistry) register(t reflect.Type) {
+	if _, ok := r.schemas[t.Name()]; ok {
+		return
+	}
+
+	// Reserve the name first so self-referencing types terminate.
+	r.schemas[t.Name()] = map[string]interface{}{}
+	r.schemas[t.Name()] = r.structSchema(t)
+}
+
+func (r *schemaRegistry) structSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+
+	r.collectFields(t, properties, &required)
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
+
+func (r *schemaRegistry) collectFields(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				r.collectFields(embedded, properties, required)
+				continue
+			}
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		schema := r.schemaFor(field.Type)
+		if applyValidateTag(schema, field.Type, field.Tag.Get("validate")) {
+			*required = append(*required, name)
+		}
+
+		properties[name] = schema
+	}
+}
+
+// applyValidateTag copies the validator rules that have an OpenAPI
+// equivalent onto the schema and reports whether the field is required.
+func applyValidateTag(schema map[string]interface{}, t reflect.Type, tag string) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if _, isRef := schema["$ref"]; isRef {
+		return strings.Contains(","+tag+",", ",required,")
+	}
+
+	required := false
+	for _, rule := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(rule, "=")
+
+		switch key {
+		case "dive":
+			// Rules after dive apply to the elements.
+			return required
+		case "required":
+			required = true
+		case "oneof":
+			var enum []interface{}
+			for _, option := range strings.Fields(value) {
+				enum = append(enum, option)
+			}
+			schema["enum"] = enum
+		case "min", "max", "gte", "lte":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				continue
+			}
+
+			lower := key == "min" || key == "gte"
+			switch t.Kind() {
+			case reflect.String:
+				schema[pick(lower, "minLength", "maxLength")] = n
+			case reflect.Slice, reflect.Array:
+				schema[pick(lower, "minItems", "maxItems")] = n
+			default:
+				schema[pick(lower, "minimum", "maximum")] = n
+			}
+		}
+	}
+
+	return required
+}
+
+func pick(first bool, a, b string) string {
+	if first {
+		return a
+	}
+	return b
+}
@@ -9,20 +9,28 @@ import (
 )
 
 type ProjectHandler struct {
-	projectUsecase model.IProjectUsecase
+	projectUsecase       model.IProjectUsecase
+	projectMemberUsecase model.IProjectMemberUsecase
 }
 
-func NewProjectHandler(e *echo.Echo, projectUsecase model.IProjectUsecase) {
+func NewProjectHandler(e *echo.Echo, auth *Auth, projectUsecase model.IProjectUsecase, projectMemberUsecase model.IProjectMemberUsecase) {
 	handlers := &ProjectHandler{
-		projectUsecase: projectUsecase,
+		projectUsecase:       projectUsecase,
+		projectMemberUsecase: projectMemberUsecase,
 	}
 
 	routeProject := e.Group("v1/project")
-	routeProject.POST("/create", handlers.Create, AuthMiddleware)
-	routeProject.GET("/", handlers.FindAll, AuthMiddleware)
-	routeProject.GET("/:id", handlers.FindByID, AuthMiddleware)
-	routeProject.PUT("/update/:id", handlers.Update, AuthMiddleware)
-	routeProject.DELETE("/delete/:id", handlers.Delete, AuthMiddleware)
+	routeProject.POST("/create", handlers.Create, auth.Middleware, RequirePermission(model.PermissionMasterDataWrite))
+	routeProject.GET("/", handlers.FindAll, auth.Middleware, RequirePermission(model.PermissionMasterDataRead))
+	routeProject.GET("/:id", handlers.FindByID, auth.Middleware, RequirePermission(model.PermissionMasterDataRead))
+	routeProject.PUT("/update/:id", handlers.Update, auth.Middleware, RequirePermission(model.PermissionMasterDataWrite))
+	routeProject.DELETE("/delete/:id", handlers.Delete, auth.Middleware, RequirePermission(model.PermissionMasterDataWrite))
+	routeProject.GET("/trash", handlers.FindTrash, auth.Middleware, RequirePermission(model.PermissionMasterDataWrite))
+	routeProject.POST("/restore/:id", handlers.Restore, auth.Middleware, RequirePermission(model.PermissionMasterDataWrite))
+	routeProject.DELETE("/purge/:id", handlers.Purge, auth.Middleware, RequirePermission(model.PermissionTrashPurge))
+	routeProject.GET("/:id/members", handlers.FindMembers, auth.Middleware, RequirePermission(model.PermissionUserRead))
+	routeProject.POST("/:id/members", handlers.AddMember, auth.Middleware, RequirePermission(model.PermissionUserWrite))
+	routeProject.DELETE("/:id/members/:user_id", handlers.RemoveMember, auth.Middleware, RequirePermission(model.PermissionUserWrite))
 }
 
 func (handler *ProjectHandler) Create(c echo.Context) error {
@@ -33,7 +41,7 @@ func (handler *ProjectHandler) Create(c echo.Context) error {
 
 	project, err := handler.projectUsecase.Create(c.Request().Context(), body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return c.JSON(http.StatusOK, Response{
@@ -44,14 +52,24 @@ func (handler *ProjectHandler) Create(c echo.Context) error {
 }
 
 func (handler *ProjectHandler) FindAll(c echo.Context) error {
-	projects, err := handler.projectUsecase.FindAll(c.Request().Context(), model.Project{})
+	page, err := parsePageRequest(c)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	filter := model.Project{
+		Name: c.QueryParam("name"),
+	}
+
+	projects, meta, err := handler.projectUsecase.FindAll(c.Request().Context(), filter, page)
+	if err != nil {
+		return err
 	}
 
 	return c.JSON(http.StatusOK, Response{
 		Status: http.StatusOK,
 		Data:   projects,
+		Meta:   meta,
 	})
 }
 
@@ -64,7 +82,7 @@ func (handler *ProjectHandler) FindByID(c echo.Context) error {
 
 	project, err := handler.projectUsecase.FindByID(c.Request().Context(), id)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return c.JSON(http.StatusOK, Response{
@@ -92,7 +110,7 @@ func (handler *ProjectHandler) Update(c echo.Context) error {
 
 	err = handler.projectUsecase.Update(c.Request().Context(), id, body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return c.JSON(http.StatusOK, Response{
@@ -114,9 +132,14 @@ func (handler *ProjectHandler) Delete(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusUnauthorized)
 	}
 
-	err = handler.projectUsecase.Delete(c.Request().Context(), id)
+	replaceWith, err := queryInt64Ptr(c, "replace_with")
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	err = handler.projectUsecase.Delete(c.Request().Context(), id, replaceWith)
+	if err != nil {
+		return err
 	}
 
 	return c.JSON(http.StatusOK, Response{
@@ -124,3 +147,112 @@ func (handler *ProjectHandler) Delete(c echo.Context) error {
 		Message: "Project deleted successfully",
 	})
 }
+
+func (handler *ProjectHandler) FindMembers(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	users, err := handler.projectMemberUsecase.FindMembers(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   users,
+	})
+}
+
+func (handler *ProjectHandler) AddMember(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	var body model.ProjectMemberInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := handler.projectMemberUsecase.Add(c.Request().Context(), id, body); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Project member added successfully",
+	})
+}
+
+func (handler *ProjectHandler) RemoveMember(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	userID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid user ID format")
+	}
+
+	if err := handler.projectMemberUsecase.Remove(c.Request().Context(), id, userID); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Project member removed successfully",
+	})
+}
+
+func (handler *ProjectHandler) FindTrash(c echo.Context) error {
+	page, err := parsePageRequest(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	projects, meta, err := handler.projectUsecase.FindTrash(c.Request().Context(), page)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   projects,
+		Meta:   meta,
+	})
+}
+
+func (handler *ProjectHandler) Restore(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	if err := handler.projectUsecase.Restore(c.Request().Context(), id); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Project restored successfully",
+	})
+}
+
+func (handler *ProjectHandler) Purge(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	if err := handler.projectUsecase.Purge(c.Request().Context(), id); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Project purged successfully",
+	})
+}
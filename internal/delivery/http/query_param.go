@@ -0,0 +1,119 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+func queryInt(c echo.Context, name string) (int, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s", name)
+	}
+
+	return n, nil
+}
+
+func queryInt64Ptr(c echo.Context, name string) (*int64, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s", name)
+	}
+
+	return &n, nil
+}
+
+// queryList accepts both repeated params (?status=A&status=B) and comma
+// separated values (?status=A,B).
+func queryList(c echo.Context, name string) []string {
+	var values []string
+
+	for _, raw := range c.QueryParams()[name] {
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+
+	return values
+}
+
+// queryTimeRange reads <prefix>_from and <prefix>_to as a date (2006-01-02)
+// or an RFC 3339 timestamp. A date-only upper bound covers the whole day, so
+// the returned upper bound is exclusive.
+func queryTimeRange(c echo.Context, prefix string) (from *time.Time, to *time.Time, err error) {
+	if value := c.QueryParam(prefix + "_from"); value != "" {
+		t, _, err := parseQueryTime(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid %s_from", prefix)
+		}
+		from = &t
+	}
+
+	if value := c.QueryParam(prefix + "_to"); value != "" {
+		t, dateOnly, err := parseQueryTime(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid %s_to", prefix)
+		}
+		if dateOnly {
+			t = t.AddDate(0, 0, 1)
+		}
+		to = &t
+	}
+
+	return from, to, nil
+}
+
+func parseQueryTime(value string) (t time.Time, dateOnly bool, err error) {
+	if t, err = time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return t, true, nil
+	}
+
+	t, err = time.Parse(time.RFC3339, value)
+	return t, false, err
+}
+
+// parsePageRequest reads page, limit, cursor, sort_by and sort_dir. Passing
+// cursor (empty for the first page) switches the list to cursor mode.
+func parsePageRequest(c echo.Context) (page model.PageRequest, err error) {
+	if page.Page, err = queryInt(c, "page"); err != nil {
+		return page, err
+	}
+
+	if page.Limit, err = queryInt(c, "limit"); err != nil {
+		return page, err
+	}
+
+	if page.Page < 0 || page.Limit < 0 || page.Limit > model.MaxPageLimit {
+		return page, fmt.Errorf("page must be positive and limit between 1 and %d", model.MaxPageLimit)
+	}
+
+	page.CursorMode = c.QueryParams().Has("cursor")
+	page.Cursor = c.QueryParam("cursor")
+	page.SortBy = c.QueryParam("sort_by")
+	page.SortDir = strings.ToLower(c.QueryParam("sort_dir"))
+
+	if page.SortDir != "" && page.SortDir != "asc" && page.SortDir != "desc" {
+		return page, errors.New("sort_dir must be asc or desc")
+	}
+
+	return page, nil
+}
@@ -0,0 +1,139 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+func newQueryContext(query string) echo.Context {
+	req := httptest.NewRequest(http.MethodGet, "/?"+query, nil)
+	return echo.New().NewContext(req, httptest.NewRecorder())
+}
+
+func TestParsePageRequest(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    model.PageRequest
+		wantErr bool
+	}{
+		{query: "", want: model.PageRequest{}},
+		{query: "page=2&limit=50", want: model.PageRequest{Page: 2, Limit: 50}},
+		{query: "cursor=", want: model.PageRequest{CursorMode: true}},
+		{query: "cursor=abc&limit=10", want: model.PageRequest{CursorMode: true, Cursor: "abc", Limit: 10}},
+		{query: "sort_by=name&sort_dir=DESC", want: model.PageRequest{SortBy: "name", SortDir: "desc"}},
+		{query: "page=x", wantErr: true},
+		{query: "page=-1", wantErr: true},
+		{query: "limit=101", wantErr: true},
+		{query: "sort_dir=up", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, err := parsePageRequest(newQueryContext(tt.query))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parsePageRequest() = %+v, want an error", got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("parsePageRequest() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("parsePageRequest() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryTimeRange(t *testing.T) {
+	day := func(year int, month time.Month, d int) *time.Time {
+		t := time.Date(year, month, d, 0, 0, 0, 0, time.Local)
+		return &t
+	}
+	instant := func(value string) *time.Time {
+		t, _ := time.Parse(time.RFC3339, value)
+		return &t
+	}
+
+	tests := []struct {
+		query    string
+		wantFrom *time.Time
+		wantTo   *time.Time
+		wantErr  bool
+	}{
+		{query: ""},
+		{
+			query:    "created_from=2026-10-01&created_to=2026-10-19",
+			wantFrom: day(2026, time.October, 1),
+			// A date-only upper bound covers the whole day.
+			wantTo: day(2026, time.October, 20),
+		},
+		{
+			query:    "created_from=2026-10-01T08:00:00Z&created_to=2026-10-01T09:30:00%2B07:00",
+			wantFrom: instant("2026-10-01T08:00:00Z"),
+			wantTo:   instant("2026-10-01T09:30:00+07:00"),
+		},
+		{query: "created_to=2026-12-31", wantTo: day(2027, time.January, 1)},
+		{query: "created_from=yesterday", wantErr: true},
+		{query: "created_to=2026-13-01", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			from, to, err := queryTimeRange(newQueryContext(tt.query), "created")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("queryTimeRange() want an error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("queryTimeRange() error = %v", err)
+			}
+			if !sameTime(from, tt.wantFrom) {
+				t.Errorf("from = %v, want %v", from, tt.wantFrom)
+			}
+			if !sameTime(to, tt.wantTo) {
+				t.Errorf("to = %v, want %v", to, tt.wantTo)
+			}
+		})
+	}
+}
+
+func TestQueryList(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: "", want: nil},
+		{query: "status=OPEN", want: []string{"OPEN"}},
+		{query: "status=OPEN,CLOSED", want: []string{"OPEN", "CLOSED"}},
+		{query: "status=OPEN&status=CLOSED", want: []string{"OPEN", "CLOSED"}},
+		{query: "status=OPEN,+,&status=", want: []string{"OPEN"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := queryList(newQueryContext(tt.query), "status"); !slices.Equal(got, tt.want) {
+				t.Errorf("queryList() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func sameTime(got *time.Time, want *time.Time) bool {
+	if got == nil || want == nil {
+		return got == want
+	}
+	return got.Equal(*want)
+}
@@ -0,0 +1,229 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ReportScheduleHandler struct {
+	reportScheduleUsecase model.IReportScheduleUsecase
+}
+
+func NewReportScheduleHandler(e *echo.Echo, auth *Auth, reportScheduleUsecase model.IReportScheduleUsecase) {
+	handler := &ReportScheduleHandler{
+		reportScheduleUsecase: reportScheduleUsecase,
+	}
+
+	route := e.Group("v1/report-schedule")
+	route.POST("/create", handler.Create, auth.Middleware, RequirePermission(model.PermissionReportManage))
+	route.GET("/", handler.FindAll, auth.Middleware, RequirePermission(model.PermissionReportManage))
+	route.GET("/:id", handler.FindByID, auth.Middleware, RequirePermission(model.PermissionReportManage))
+	route.PUT("/update/:id", handler.Update, auth.Middleware, RequirePermission(model.PermissionReportManage))
+	route.DELETE("/delete/:id", handler.Delete, auth.Middleware, RequirePermission(model.PermissionReportManage))
+	route.GET("/trash", handler.FindTrash, auth.Middleware, RequirePermission(model.PermissionReportManage))
+	route.POST("/restore/:id", handler.Restore, auth.Middleware, RequirePermission(model.PermissionReportManage))
+	route.DELETE("/purge/:id", handler.Purge, auth.Middleware, RequirePermission(model.PermissionTrashPurge))
+	route.POST("/:id/run", handler.Run, auth.Middleware, RequirePermission(model.PermissionReportManage))
+	route.GET("/:id/deliveries", handler.FindDeliveries, auth.Middleware, RequirePermission(model.PermissionReportManage))
+	route.POST("/delivery/:id/resend", handler.Resend, auth.Middleware, RequirePermission(model.PermissionReportManage))
+}
+
+func (h *ReportScheduleHandler) Create(c echo.Context) error {
+	var body model.ReportScheduleInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	schedule, err := h.reportScheduleUsecase.Create(c.Request().Context(), body)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Report schedule created successfully",
+		Data:    schedule,
+	})
+}
+
+func (h *ReportScheduleHandler) FindAll(c echo.Context) error {
+	schedules, err := h.reportScheduleUsecase.FindAll(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   schedules,
+	})
+}
+
+func (h *ReportScheduleHandler) FindByID(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	schedule, err := h.reportScheduleUsecase.FindByID(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   schedule,
+	})
+}
+
+func (h *ReportScheduleHandler) Update(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	var body model.ReportScheduleInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := h.reportScheduleUsecase.Update(c.Request().Context(), id, body); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Report schedule updated successfully",
+		Data:    body,
+	})
+}
+
+func (h *ReportScheduleHandler) Delete(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	if err := h.reportScheduleUsecase.Delete(c.Request().Context(), id); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Report schedule deleted successfully",
+	})
+}
+
+// Run sends the report now. period_end (RFC 3339) regenerates the report
+// of an earlier period, e.g. one that was missed while the server was down.
+func (h *ReportScheduleHandler) Run(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	periodEnd := time.Now()
+	if value := c.QueryParam("period_end"); value != "" {
+		if periodEnd, err = time.Parse(time.RFC3339, value); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid period_end")
+		}
+	}
+
+	delivery, err := h.reportScheduleUsecase.Run(c.Request().Context(), id, periodEnd)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   delivery,
+	})
+}
+
+func (h *ReportScheduleHandler) FindDeliveries(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	deliveries, err := h.reportScheduleUsecase.FindDeliveries(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   deliveries,
+	})
+}
+
+func (h *ReportScheduleHandler) Resend(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	delivery, err := h.reportScheduleUsecase.Resend(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   delivery,
+	})
+}
+
+func (h *ReportScheduleHandler) FindTrash(c echo.Context) error {
+	page, err := parsePageRequest(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	schedules, meta, err := h.reportScheduleUsecase.FindTrash(c.Request().Context(), page)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   schedules,
+		Meta:   meta,
+	})
+}
+
+func (h *ReportScheduleHandler) Restore(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	if err := h.reportScheduleUsecase.Restore(c.Request().Context(), id); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Report schedule restored successfully",
+	})
+}
+
+func (h *ReportScheduleHandler) Purge(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	if err := h.reportScheduleUsecase.Purge(c.Request().Context(), id); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Report schedule purged successfully",
+	})
+}
@@ -0,0 +1,126 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+)
+
+type RoleHandler struct {
+	roleUsecase model.IRoleUsecase
+}
+
+func NewRoleHandler(e *echo.Echo, auth *Auth, roleUsecase model.IRoleUsecase) {
+	handler := &RoleHandler{
+		roleUsecase: roleUsecase,
+	}
+
+	manage := RequirePermission(model.PermissionRoleManage)
+
+	route := e.Group("v1/role")
+	route.POST("/create", handler.Create, auth.Middleware, manage)
+	route.GET("/", handler.FindAll, auth.Middleware, manage)
+	route.GET("/permissions", handler.FindPermissions, auth.Middleware, manage)
+	route.GET("/:id", handler.FindByID, auth.Middleware, manage)
+	route.PUT("/update/:id", handler.Update, auth.Middleware, manage)
+	route.DELETE("/delete/:id", handler.Delete, auth.Middleware, manage)
+}
+
+func (handler *RoleHandler) Create(c echo.Context) error {
+	var body model.RoleInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	role, err := handler.roleUsecase.Create(c.Request().Context(), body)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Role created successfully",
+		Data:    role,
+	})
+}
+
+func (handler *RoleHandler) FindAll(c echo.Context) error {
+	roles, err := handler.roleUsecase.FindAll(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   roles,
+	})
+}
+
+func (handler *RoleHandler) FindPermissions(c echo.Context) error {
+	permissions, err := handler.roleUsecase.FindPermissions(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   permissions,
+	})
+}
+
+func (handler *RoleHandler) FindByID(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	role, err := handler.roleUsecase.FindByID(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   role,
+	})
+}
+
+func (handler *RoleHandler) Update(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	var body model.RoleInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := handler.roleUsecase.Update(c.Request().Context(), id, body); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Role updated successfully",
+		Data:    body,
+	})
+}
+
+func (handler *RoleHandler) Delete(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	if err := handler.roleUsecase.Delete(c.Request().Context(), id); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Role deleted successfully",
+	})
+}
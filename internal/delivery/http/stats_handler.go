@@ -0,0 +1,103 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+type StatsHandler struct {
+	statsUsecase model.IStatsUsecase
+}
+
+func NewStatsHandler(e *echo.Echo, auth *Auth, statsUsecase model.IStatsUsecase) {
+	handler := &StatsHandler{
+		statsUsecase: statsUsecase,
+	}
+
+	route := e.Group("v1/stats")
+	route.GET("/summary", handler.Summary, auth.Middleware, RequirePermission(model.PermissionStatsRead))
+	route.GET("/breakdown", handler.Breakdown, auth.Middleware, RequirePermission(model.PermissionStatsRead))
+	route.GET("/timeseries", handler.TimeSeries, auth.Middleware, RequirePermission(model.PermissionStatsRead))
+}
+
+func (h *StatsHandler) Summary(c echo.Context) error {
+	filter, err := parseStatsFilter(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	summary, err := h.statsUsecase.Summary(c.Request().Context(), filter)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   summary,
+	})
+}
+
+func (h *StatsHandler) Breakdown(c echo.Context) error {
+	filter, err := parseStatsFilter(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	groups, err := h.statsUsecase.Breakdown(c.Request().Context(), filter)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   groups,
+	})
+}
+
+func (h *StatsHandler) TimeSeries(c echo.Context) error {
+	filter, err := parseStatsFilter(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	points, err := h.statsUsecase.TimeSeries(c.Request().Context(), filter)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   points,
+	})
+}
+
+func parseStatsFilter(c echo.Context) (filter model.StatsFilter, err error) {
+	from, to, err := queryTimeRange(c, "date")
+	if err != nil {
+		return filter, err
+	}
+
+	if from != nil {
+		filter.From = *from
+	}
+
+	if to != nil {
+		filter.To = *to
+	}
+
+	if filter.ProjectID, err = queryInt64Ptr(c, "project_id"); err != nil {
+		return filter, err
+	}
+
+	if filter.LocationID, err = queryInt64Ptr(c, "location_id"); err != nil {
+		return filter, err
+	}
+
+	filter.By = c.QueryParam("by")
+	filter.Interval = c.QueryParam("interval")
+
+	return filter, nil
+}
@@ -1,8 +1,11 @@
 package http
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
 
@@ -13,81 +16,234 @@ type TroubleshootLogHandler struct {
 	usecase model.ITroubleshootLogUsecase
 }
 
-func NewTroubleshootLogHandler(e *echo.Echo, troubleshootLogUsecase model.ITroubleshootLogUsecase) {
+func NewTroubleshootLogHandler(e *echo.Echo, auth *Auth, troubleshootLogUsecase model.ITroubleshootLogUsecase) {
 	handler := &TroubleshootLogHandler{
 		usecase: troubleshootLogUsecase,
 	}
 
 	route := e.Group("v1/troubleshoot-log")
-	route.POST("/create", handler.Create, AuthMiddleware)
-	route.GET("/", handler.FindAll, AuthMiddleware)
-	route.GET("/:id", handler.FindByID, AuthMiddleware)
-	route.PUT("/update/:id", handler.Update, AuthMiddleware)
-	route.DELETE("/delete/:id", handler.Delete, AuthMiddleware)
+	route.POST("/create", handler.Create, auth.Middleware, RequirePermission(model.PermissionTicketWrite))
+	route.GET("/", handler.FindAll, auth.Middleware, RequirePermission(model.PermissionTicketRead))
+	route.GET("/search", handler.Search, auth.Middleware, RequirePermission(model.PermissionTicketRead))
+	route.GET("/recurring", handler.FindRepeatOffenders, auth.Middleware, RequirePermission(model.PermissionTicketRead))
+	route.GET("/export", handler.Export, auth.Middleware, RequirePermission(model.PermissionTicketRead))
+	route.GET("/:id", handler.FindByID, auth.Middleware, RequirePermission(model.PermissionTicketRead))
+	route.PUT("/update/:id", handler.Update, auth.Middleware, RequirePermission(model.PermissionTicketWrite))
+	route.DELETE("/delete/:id", handler.Delete, auth.Middleware, RequirePermission(model.PermissionTicketDelete))
+	route.GET("/trash", handler.FindTrash, auth.Middleware, RequirePermission(model.PermissionTicketDelete))
+	route.POST("/restore/:id", handler.Restore, auth.Middleware, RequirePermission(model.PermissionTicketDelete))
+	route.DELETE("/purge/:id", handler.Purge, auth.Middleware, RequirePermission(model.PermissionTrashPurge))
 }
 
 func (h *TroubleshootLogHandler) Create(c echo.Context) error {
-	var input model.TroubleshootLog
-
+	var input model.CreateTroubleshootLogInput
 	if err := c.Bind(&input); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"message": err.Error(),
-		})
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
 	data, err := h.usecase.Create(c.Request().Context(), input)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"message": err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusCreated, data)
 }
 
 func (h *TroubleshootLogHandler) FindAll(c echo.Context) error {
-	var filter model.TroubleshootLog
+	filter, err := parseTroubleshootLogFilter(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
 
-	filter.Status = c.QueryParam("status")
+	page, err := parsePageRequest(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	logs, meta, err := h.usecase.FindAll(c.Request().Context(), filter, page)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   logs,
+		Meta:   meta,
+	})
+}
+
+func parseTroubleshootLogFilter(c echo.Context) (filter model.TroubleshootLogFilter, err error) {
 	filter.TicketNumber = c.QueryParam("ticket_number")
+	filter.Statuses = queryList(c, "status")
+	filter.Reporter = c.QueryParam("reporter")
+	filter.Part = c.QueryParam("part")
+	filter.SortBy = c.QueryParam("sort_by")
+	filter.SortDir = strings.ToLower(c.QueryParam("sort_dir"))
+
+	if filter.ProjectID, err = queryInt64Ptr(c, "project_id"); err != nil {
+		return filter, err
+	}
+
+	if filter.LocationID, err = queryInt64Ptr(c, "location_id"); err != nil {
+		return filter, err
+	}
+
+	if filter.DeviceID, err = queryInt64Ptr(c, "device_id"); err != nil {
+		return filter, err
+	}
+
+	if filter.WorkTypeID, err = queryInt64Ptr(c, "work_type_id"); err != nil {
+		return filter, err
+	}
+
+	if filter.AssigneeID, err = queryInt64Ptr(c, "assignee_id"); err != nil {
+		return filter, err
+	}
+
+	if filter.TroubleDateFrom, filter.TroubleDateTo, err = queryTimeRange(c, "trouble_date"); err != nil {
+		return filter, err
+	}
+
+	if filter.DoneDateFrom, filter.DoneDateTo, err = queryTimeRange(c, "done_date"); err != nil {
+		return filter, err
+	}
 
-	data, err := h.usecase.FindAll(c.Request().Context(), filter)
+	if filter.CreatedFrom, filter.CreatedTo, err = queryTimeRange(c, "created"); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+func (h *TroubleshootLogHandler) Search(c echo.Context) error {
+	in := model.SearchTroubleshootLogInput{
+		Query: c.QueryParam("q"),
+	}
+
+	var err error
+	if in.Page, err = queryInt(c, "page"); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if in.Limit, err = queryInt(c, "limit"); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	data, err := h.usecase.Search(c.Request().Context(), in)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"message": err.Error(),
-		})
+		return err
+	}
+
+	return c.JSON(http.StatusOK, data)
+}
+
+var exportContentTypes = map[string]string{
+	model.ExportFormatCSV:  "text/csv; charset=utf-8",
+	model.ExportFormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	model.ExportFormatPDF:  "application/pdf",
+}
+
+func (h *TroubleshootLogHandler) Export(c echo.Context) error {
+	format := c.QueryParam("format")
+	if format == "" {
+		format = model.ExportFormatCSV
+	}
+
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		return echo.NewHTTPError(http.StatusBadRequest, "format must be csv, xlsx or pdf")
+	}
+
+	filter, err := parseTroubleshootLogFilter(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	fileName := fmt.Sprintf("troubleshoot-logs-%s.%s", time.Now().Format("20060102-150405"), format)
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, contentType)
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", fileName))
+
+	if err := h.usecase.Export(c.Request().Context(), filter, format, res); err != nil {
+		// Once rows are streamed the status is already sent, all we can do
+		// is log and cut the download short.
+		if res.Committed {
+			c.Logger().Error("export failed: ", err)
+			return nil
+		}
+
+		res.Header().Del(echo.HeaderContentType)
+		res.Header().Del(echo.HeaderContentDisposition)
+		return err
+	}
+
+	return nil
+}
+
+func (h *TroubleshootLogHandler) FindRepeatOffenders(c echo.Context) error {
+	var filter model.RepeatOffenderFilter
+
+	from, to, err := queryTimeRange(c, "trouble_date")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if from != nil {
+		filter.From = *from
+	}
+
+	if to != nil {
+		filter.To = *to
+	}
+
+	if filter.ProjectID, err = queryInt64Ptr(c, "project_id"); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if filter.LocationID, err = queryInt64Ptr(c, "location_id"); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if filter.PerLocation, err = queryInt(c, "per_location"); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	data, err := h.usecase.FindRepeatOffenders(c.Request().Context(), filter)
+	if err != nil {
+		return err
 	}
 
 	return c.JSON(http.StatusOK, data)
 }
 
 func (h *TroubleshootLogHandler) FindByID(c echo.Context) error {
-	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
 
 	data, err := h.usecase.FindByID(c.Request().Context(), id)
 	if err != nil {
-		return c.JSON(http.StatusNotFound, map[string]string{
-			"message": "data not found",
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, data)
 }
 
 func (h *TroubleshootLogHandler) Update(c echo.Context) error {
-	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
 
-	var input model.TroubleshootLog
+	var input model.UpdateTroubleshootLogInput
 	if err := c.Bind(&input); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"message": err.Error(),
-		})
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
 	if err := h.usecase.Update(c.Request().Context(), id, input); err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"message": err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, map[string]string{
@@ -96,15 +252,66 @@ func (h *TroubleshootLogHandler) Update(c echo.Context) error {
 }
 
 func (h *TroubleshootLogHandler) Delete(c echo.Context) error {
-	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
 
 	if err := h.usecase.Delete(c.Request().Context(), id); err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"message": err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, map[string]string{
 		"message": "success delete troubleshoot log",
 	})
 }
+
+func (h *TroubleshootLogHandler) FindTrash(c echo.Context) error {
+	page, err := parsePageRequest(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	logs, meta, err := h.usecase.FindTrash(c.Request().Context(), page)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   logs,
+		Meta:   meta,
+	})
+}
+
+func (h *TroubleshootLogHandler) Restore(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	if err := h.usecase.Restore(c.Request().Context(), id); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Troubleshoot log restored successfully",
+	})
+}
+
+func (h *TroubleshootLogHandler) Purge(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	if err := h.usecase.Purge(c.Request().Context(), id); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Troubleshoot log purged successfully",
+	})
+}
@@ -1,7 +1,6 @@
 package http
 
 import (
-	"log"
 	"net/http"
 	"strconv"
 
@@ -14,18 +13,27 @@ type UserHandler struct {
 	userUsecase model.IUserUsecase
 }
 
-func NewUserHandler(e *echo.Echo, userUsecase model.IUserUsecase) {
+func NewUserHandler(e *echo.Echo, auth *Auth, userUsecase model.IUserUsecase) {
 	handlers := &UserHandler{
 		userUsecase: userUsecase,
 	}
 
 	routeUser := e.Group("v1/auth")
 	routeUser.POST("/login", handlers.Login)
-	routeUser.GET("/user/:id", handlers.FindByID, AuthMiddleware)
-	routeUser.GET("/users", handlers.FindAll, AuthMiddleware)
-	routeUser.POST("/register", handlers.Create, AuthMiddleware)
-	routeUser.PUT("/user/update/:id", handlers.Update, AuthMiddleware)
-	routeUser.DELETE("/user/delete/:id", handlers.Delete, AuthMiddleware)
+	routeUser.POST("/refresh", handlers.Refresh)
+	routeUser.POST("/logout", handlers.Logout, auth.Middleware)
+	routeUser.PUT("/password", handlers.ChangePassword, auth.Middleware)
+	routeUser.POST("/password/reset", handlers.ResetPassword)
+	routeUser.POST("/user/:id/unlock", handlers.Unlock, auth.Middleware, RequirePermission(model.PermissionUserWrite))
+	routeUser.POST("/user/:id/password-reset", handlers.IssuePasswordReset, auth.Middleware, RequirePermission(model.PermissionUserWrite))
+	routeUser.GET("/user/:id", handlers.FindByID, auth.Middleware, RequirePermission(model.PermissionUserRead))
+	routeUser.GET("/users", handlers.FindAll, auth.Middleware, RequirePermission(model.PermissionUserRead))
+	routeUser.POST("/register", handlers.Create, auth.Middleware, RequirePermission(model.PermissionUserWrite))
+	routeUser.PUT("/user/update/:id", handlers.Update, auth.Middleware, RequirePermission(model.PermissionUserWrite))
+	routeUser.DELETE("/user/delete/:id", handlers.Delete, auth.Middleware, RequirePermission(model.PermissionUserWrite))
+	routeUser.GET("/users/trash", handlers.FindTrash, auth.Middleware, RequirePermission(model.PermissionUserWrite))
+	routeUser.POST("/user/restore/:id", handlers.Restore, auth.Middleware, RequirePermission(model.PermissionUserWrite))
+	routeUser.DELETE("/user/purge/:id", handlers.Purge, auth.Middleware, RequirePermission(model.PermissionTrashPurge))
 }
 
 func (handler *UserHandler) Login(c echo.Context) error {
@@ -34,20 +42,55 @@ func (handler *UserHandler) Login(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
-	// log.Printf("LOGIN INPUT: %+v", body)
+	body.IP = c.RealIP()
 
-	accessToken, err := handler.userUsecase.Login(c.Request().Context(), body)
+	tokens, err := handler.userUsecase.Login(c.Request().Context(), body)
 	if err != nil {
-		return c.JSON(http.StatusUnauthorized, Response{
-			Status:  http.StatusUnauthorized,
-			Message: "Email or password is incorrect",
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, Response{
-		Status:      http.StatusOK,
-		Message:     "Success Login",
-		AccessToken: accessToken,
+		Status:       http.StatusOK,
+		Message:      "Success Login",
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+	})
+}
+
+func (handler *UserHandler) Refresh(c echo.Context) error {
+	var body model.RefreshTokenInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	tokens, err := handler.userUsecase.Refresh(c.Request().Context(), body)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:       http.StatusOK,
+		Message:      "Token refreshed",
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+	})
+}
+
+func (handler *UserHandler) Logout(c echo.Context) error {
+	var body model.LogoutInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := handler.userUsecase.Logout(c.Request().Context(), body); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Logged out",
 	})
 }
 
@@ -58,16 +101,14 @@ func (handler *UserHandler) FindByID(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
 	}
 
-	claim, ok := c.Request().Context().Value(model.BearerAuthKey).(model.CustomClaims)
-	if !ok {
+	claim, ok := c.Request().Context().Value(model.BearerAuthKey).(*model.CustomClaims)
+	if !ok || claim == nil {
 		return echo.NewHTTPError(http.StatusUnauthorized)
 	}
 
-	log.Printf("Authenticated User ID: %d", claim.UserID)
-
 	user, err := handler.userUsecase.FindByID(c.Request().Context(), int64(id))
 	if err != nil {
-		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		return err
 	}
 
 	return c.JSON(http.StatusOK, Response{
@@ -77,14 +118,26 @@ func (handler *UserHandler) FindByID(c echo.Context) error {
 }
 
 func (handler *UserHandler) FindAll(c echo.Context) error {
-	users, err := handler.userUsecase.FindAll(c.Request().Context(), model.User{})
+	page, err := parsePageRequest(c)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	filter := model.User{
+		Username: c.QueryParam("username"),
+		CodeName: c.QueryParam("code_name"),
+		Role:     c.QueryParam("role"),
+	}
+
+	users, meta, err := handler.userUsecase.FindAll(c.Request().Context(), filter, page)
+	if err != nil {
+		return err
 	}
 
 	return c.JSON(http.StatusOK, Response{
 		Status: http.StatusOK,
 		Data:   users,
+		Meta:   meta,
 	})
 }
 
@@ -94,15 +147,15 @@ func (handler *UserHandler) Create(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
-	accessToken, err := handler.userUsecase.Create(c.Request().Context(), body)
+	user, err := handler.userUsecase.Create(c.Request().Context(), body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return c.JSON(http.StatusOK, Response{
-		Status:      http.StatusOK,
-		Message:     "Success Register",
-		AccessToken: accessToken,
+		Status:  http.StatusOK,
+		Message: "Success Register",
+		Data:    user,
 	})
 }
 
@@ -118,8 +171,6 @@ func (handler *UserHandler) Update(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusUnauthorized)
 	}
 
-	// log.Printf("Authenticated User ID: %d", claim.UserID)
-
 	var body model.UpdateUserInput
 	if err := c.Bind(&body); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
@@ -127,7 +178,7 @@ func (handler *UserHandler) Update(c echo.Context) error {
 
 	err = handler.userUsecase.Update(c.Request().Context(), id, body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return c.JSON(http.StatusOK, Response{
@@ -149,11 +200,9 @@ func (handler *UserHandler) Delete(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusUnauthorized)
 	}
 
-	// log.Printf("Authenticated User ID: %d", claim.UserID)
-
 	err = handler.userUsecase.Delete(c.Request().Context(), id)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return c.JSON(http.StatusOK, Response{
@@ -161,3 +210,119 @@ func (handler *UserHandler) Delete(c echo.Context) error {
 		Message: "User deleted successfully",
 	})
 }
+
+func (handler *UserHandler) ChangePassword(c echo.Context) error {
+	var body model.ChangePasswordInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := handler.userUsecase.ChangePassword(c.Request().Context(), body); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Password changed successfully",
+	})
+}
+
+func (handler *UserHandler) IssuePasswordReset(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	reset, err := handler.userUsecase.IssuePasswordReset(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Password reset token issued",
+		Data:    reset,
+	})
+}
+
+func (handler *UserHandler) ResetPassword(c echo.Context) error {
+	var body model.ResetPasswordInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := handler.userUsecase.ResetPassword(c.Request().Context(), body); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Password has been reset",
+	})
+}
+
+func (handler *UserHandler) Unlock(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	if err := handler.userUsecase.Unlock(c.Request().Context(), id); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "User unlocked successfully",
+	})
+}
+
+func (handler *UserHandler) FindTrash(c echo.Context) error {
+	page, err := parsePageRequest(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	users, meta, err := handler.userUsecase.FindTrash(c.Request().Context(), page)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   users,
+		Meta:   meta,
+	})
+}
+
+func (handler *UserHandler) Restore(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	if err := handler.userUsecase.Restore(c.Request().Context(), id); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "User restored successfully",
+	})
+}
+
+func (handler *UserHandler) Purge(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	if err := handler.userUsecase.Purge(c.Request().Context(), id); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "User purged successfully",
+	})
+}
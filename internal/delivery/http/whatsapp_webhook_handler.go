@@ -1,42 +1,50 @@
 package http
 
 import (
-	"fmt"
 	"net/http"
 
+	"github.com/tubagusmf/log-troubleshoot-be/internal/config"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/helper"
 	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
 	"github.com/tubagusmf/log-troubleshoot-be/internal/usecase"
 
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/sirupsen/logrus"
 )
 
 type WhatsAppWebhookHandler struct {
 	usecase *usecase.WhatsAppConsumerUsecase
 }
 
+// NewWhatsAppWebhookHandler registers the webhook the WhatsApp gateway posts
+// group messages to. The gateway authenticates with an API key that has the
+// webhook:whatsapp scope. The body, base64 media included, is capped by
+// whatsapp.webhook.body_limit.
 func NewWhatsAppWebhookHandler(
 	e *echo.Echo,
+	auth *Auth,
 	usecase *usecase.WhatsAppConsumerUsecase,
 ) {
 	handler := &WhatsAppWebhookHandler{usecase: usecase}
 
-	e.POST("webhook/whatsapp", handler.Handle)
+	e.POST("webhook/whatsapp", handler.Handle,
+		auth.APIKeyMiddleware,
+		RequirePermission(model.PermissionWhatsAppWebhook),
+		middleware.BodyLimit(config.WhatsAppWebhookBodyLimit()),
+	)
 }
 
 func (h *WhatsAppWebhookHandler) Handle(c echo.Context) error {
 	var payload model.WhatsAppWebhookRequest
 
 	if err := c.Bind(&payload); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"message": err.Error(),
-		})
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
 	err := h.usecase.Consume(c.Request().Context(), payload)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, map[string]string{
@@ -44,17 +52,20 @@ func (h *WhatsAppWebhookHandler) Handle(c echo.Context) error {
 	})
 }
 
+// ReceiveWebhook acknowledges a payload without creating a ticket. Only the
+// hashed sender and the message length are logged, never the message.
 func (h *WhatsAppWebhookHandler) ReceiveWebhook(c echo.Context) error {
-	var payload map[string]interface{}
+	var payload model.WhatsAppWebhookRequest
 
 	if err := c.Bind(&payload); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": err.Error(),
-		})
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 
 	}
 
-	fmt.Println("Incoming Payload:", payload)
+	logrus.WithContext(c.Request().Context()).WithFields(logrus.Fields{
+		"sender_hash":    helper.HashSender(payload.Sender),
+		"message_length": len(payload.Message),
+	}).Debug("Incoming WhatsApp payload")
 
 	return c.JSON(http.StatusOK, map[string]string{
 		"message": "webhook received",
@@ -12,17 +12,20 @@ type WorkTypeHandler struct {
 	workTypeUsecase model.IWorkTypeUsecase
 }
 
-func NewWorkTypeHandler(e *echo.Echo, usecase model.IWorkTypeUsecase) {
+func NewWorkTypeHandler(e *echo.Echo, auth *Auth, usecase model.IWorkTypeUsecase) {
 	handler := &WorkTypeHandler{
 		workTypeUsecase: usecase,
 	}
 
 	route := e.Group("v1/work-type")
-	route.POST("/create", handler.Create, AuthMiddleware)
-	route.GET("/", handler.FindAll, AuthMiddleware)
-	route.GET("/:id", handler.FindByID, AuthMiddleware)
-	route.PUT("/update/:id", handler.Update, AuthMiddleware)
-	route.DELETE("/delete/:id", handler.Delete, AuthMiddleware)
+	route.POST("/create", handler.Create, auth.Middleware, RequirePermission(model.PermissionMasterDataWrite))
+	route.GET("/", handler.FindAll, auth.Middleware, RequirePermission(model.PermissionMasterDataRead))
+	route.GET("/:id", handler.FindByID, auth.Middleware, RequirePermission(model.PermissionMasterDataRead))
+	route.PUT("/update/:id", handler.Update, auth.Middleware, RequirePermission(model.PermissionMasterDataWrite))
+	route.DELETE("/delete/:id", handler.Delete, auth.Middleware, RequirePermission(model.PermissionMasterDataWrite))
+	route.GET("/trash", handler.FindTrash, auth.Middleware, RequirePermission(model.PermissionMasterDataWrite))
+	route.POST("/restore/:id", handler.Restore, auth.Middleware, RequirePermission(model.PermissionMasterDataWrite))
+	route.DELETE("/purge/:id", handler.Purge, auth.Middleware, RequirePermission(model.PermissionTrashPurge))
 }
 
 func (h *WorkTypeHandler) Create(c echo.Context) error {
@@ -33,7 +36,7 @@ func (h *WorkTypeHandler) Create(c echo.Context) error {
 
 	data, err := h.workTypeUsecase.Create(c.Request().Context(), body)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return c.JSON(http.StatusOK, Response{
@@ -44,14 +47,24 @@ func (h *WorkTypeHandler) Create(c echo.Context) error {
 }
 
 func (h *WorkTypeHandler) FindAll(c echo.Context) error {
-	data, err := h.workTypeUsecase.FindAll(c.Request().Context(), model.WorkType{})
+	page, err := parsePageRequest(c)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	filter := model.WorkType{
+		Name: c.QueryParam("name"),
+	}
+
+	data, meta, err := h.workTypeUsecase.FindAll(c.Request().Context(), filter, page)
+	if err != nil {
+		return err
 	}
 
 	return c.JSON(http.StatusOK, Response{
 		Status: http.StatusOK,
 		Data:   data,
+		Meta:   meta,
 	})
 }
 
@@ -63,7 +76,7 @@ func (h *WorkTypeHandler) FindByID(c echo.Context) error {
 
 	data, err := h.workTypeUsecase.FindByID(c.Request().Context(), id)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return c.JSON(http.StatusOK, Response{
@@ -89,7 +102,7 @@ func (h *WorkTypeHandler) Update(c echo.Context) error {
 	}
 
 	if err := h.workTypeUsecase.Update(c.Request().Context(), id, body); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return c.JSON(http.StatusOK, Response{
@@ -110,8 +123,13 @@ func (h *WorkTypeHandler) Delete(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusUnauthorized)
 	}
 
-	if err := h.workTypeUsecase.Delete(c.Request().Context(), id); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	replaceWith, err := queryInt64Ptr(c, "replace_with")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := h.workTypeUsecase.Delete(c.Request().Context(), id, replaceWith); err != nil {
+		return err
 	}
 
 	return c.JSON(http.StatusOK, Response{
@@ -119,3 +137,53 @@ func (h *WorkTypeHandler) Delete(c echo.Context) error {
 		Message: "Work type deleted successfully",
 	})
 }
+
+func (h *WorkTypeHandler) FindTrash(c echo.Context) error {
+	page, err := parsePageRequest(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	workTypes, meta, err := h.workTypeUsecase.FindTrash(c.Request().Context(), page)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   workTypes,
+		Meta:   meta,
+	})
+}
+
+func (h *WorkTypeHandler) Restore(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	if err := h.workTypeUsecase.Restore(c.Request().Context(), id); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Work type restored successfully",
+	})
+}
+
+func (h *WorkTypeHandler) Purge(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ID format")
+	}
+
+	if err := h.workTypeUsecase.Purge(c.Request().Context(), id); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Work type purged successfully",
+	})
+}
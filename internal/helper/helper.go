@@ -1,7 +1,12 @@
 package helper
 
 import (
-	"os"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -12,7 +17,7 @@ import (
 )
 
 func HashRequestPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), config.PasswordBcryptCost())
 	return string(bytes), err
 }
 
@@ -21,22 +26,74 @@ func CheckPasswordHash(password, hash string) bool {
 	return err == nil
 }
 
-func GenerateToken(user model.User) (string, error) {
+func GenerateToken(user model.User, permissions []string) (string, error) {
+	jti, err := RandomToken(16)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
 	claims := model.CustomClaims{
-		UserID: user.Id,
-		Role:   user.Role,
+		UserID:      user.Id,
+		Role:        user.Role,
+		Permissions: permissions,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ID:        jti,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(config.JWTExp())),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	keys, err := currentKeyManager()
+	if err != nil {
+		return "", err
+	}
+
+	return keys.Sign(claims)
+}
+
+func DecodeToken(token string, claim *model.CustomClaims) error {
+	keys, err := currentKeyManager()
+	if err != nil {
+		return err
+	}
+
+	return keys.Parse(token, claim)
 }
 
-func DecodeToken(token string, claim *model.CustomClaims) (err error) {
-	jwt.ParseWithClaims(token, claim, func(t *jwt.Token) (interface{}, error) {
-		return []byte(config.JWTSigningKey()), nil
-	})
-	return
+// RandomToken returns n random bytes encoded as URL safe base64.
+func RandomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken returns the hex SHA-256 of an opaque token, for storage.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// processSenderHashKey stands in for log.sender_hash_key when it is not
+// configured.
+var processSenderHashKey = sync.OnceValue(func() []byte {
+	key := make([]byte, 32)
+	rand.Read(key)
+	return key
+})
+
+// HashSender identifies a WhatsApp sender in logs without their number. It
+// is keyed, as phone numbers are few enough to find a plain hash of by
+// trying them all.
+func HashSender(sender string) string {
+	key := []byte(config.LogSenderHashKey())
+	if len(key) == 0 {
+		key = processSenderHashKey()
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(sender))
+	return hex.EncodeToString(mac.Sum(nil))[:16]
 }
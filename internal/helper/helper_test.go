@@ -0,0 +1,36 @@
+package helper
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestHashSender(t *testing.T) {
+	t.Cleanup(viper.Reset)
+
+	const sender = "6281234567890"
+
+	unkeyed := HashSender(sender)
+	if unkeyed != HashSender(sender) {
+		t.Fatal("HashSender() without a key is not stable within the process")
+	}
+
+	viper.Set("log.sender_hash_key", "first")
+	first := HashSender(sender)
+
+	if len(first) != 16 {
+		t.Fatalf("HashSender() = %q, want 16 hex characters", first)
+	}
+	if first == HashToken(sender)[:16] {
+		t.Fatal("HashSender() is a plain SHA-256 of the number")
+	}
+	if first == unkeyed || first == HashSender("6281234567891") {
+		t.Fatal("HashSender() collides")
+	}
+
+	viper.Set("log.sender_hash_key", "second")
+	if HashSender(sender) == first {
+		t.Fatal("HashSender() ignores log.sender_hash_key")
+	}
+}
@@ -0,0 +1,341 @@
+package helper
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/config"
+)
+
+// SigningKey is one entry of the key set. Private is nil for keys that are
+// kept only to verify tokens issued before a rotation.
+type SigningKey struct {
+	KID       string
+	Method    jwt.SigningMethod
+	Private   crypto.PrivateKey
+	Public    crypto.PublicKey
+	ExpiresAt *time.Time
+}
+
+func (k *SigningKey) expired(now time.Time) bool {
+	return k.ExpiresAt != nil && now.After(*k.ExpiresAt)
+}
+
+// KeyManager signs tokens with the active key and verifies them with
+// whichever key the token's kid header names.
+type KeyManager struct {
+	mu     sync.RWMutex
+	keys   map[string]*SigningKey
+	active string
+}
+
+func NewKeyManager(keys []*SigningKey, activeKID string) (*KeyManager, error) {
+	m := &KeyManager{}
+	if err := m.Rotate(keys, activeKID); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Rotate swaps the key set. Tokens signed with a key that is still in the
+// new set keep verifying, so rotating does not log anyone out.
+func (m *KeyManager) Rotate(keys []*SigningKey, activeKID string) error {
+	set := make(map[string]*SigningKey, len(keys))
+	for _, key := range keys {
+		if key.KID == "" {
+			return errors.New("jwt key is missing a kid")
+		}
+		if _, ok := set[key.KID]; ok {
+			return fmt.Errorf("duplicate jwt key %q", key.KID)
+		}
+		set[key.KID] = key
+	}
+
+	active, ok := set[activeKID]
+	if !ok {
+		return fmt.Errorf("active jwt key %q is not configured", activeKID)
+	}
+	if active.Private == nil {
+		return fmt.Errorf("active jwt key %q has no private key", activeKID)
+	}
+	if active.expired(time.Now()) {
+		return fmt.Errorf("active jwt key %q has expired", activeKID)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.keys = set
+	m.active = activeKID
+	return nil
+}
+
+func (m *KeyManager) Sign(claims jwt.Claims) (string, error) {
+	m.mu.RLock()
+	key := m.keys[m.active]
+	m.mu.RUnlock()
+
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.KID
+	return token.SignedString(key.Private)
+}
+
+func (m *KeyManager) Parse(tokenString string, claims jwt.Claims) error {
+	m.mu.RLock()
+	keys := m.keys
+	m.mu.RUnlock()
+
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		if kid == "" {
+			// Tokens issued before key IDs existed were signed with the
+			// single legacy secret.
+			kid = "default"
+		}
+		key, ok := keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		if key.expired(time.Now()) {
+			return nil, fmt.Errorf("signing key %q has expired", kid)
+		}
+		if t.Method.Alg() != key.Method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method %s", t.Method.Alg())
+		}
+		return key.Public, nil
+	}, jwt.WithExpirationRequired())
+
+	return err
+}
+
+// JWK is a public key in JSON Web Key format.
+type JWK struct {
+	KID string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS lists the public halves of the asymmetric keys that still verify.
+// HMAC secrets are never published.
+func (m *KeyManager) JWKS() JWKSet {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	set := JWKSet{Keys: []JWK{}}
+	now := time.Now()
+	for _, key := range m.keys {
+		if key.expired(now) {
+			continue
+		}
+
+		switch pub := key.Public.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				KID: key.KID,
+				Kty: "RSA",
+				Alg: key.Method.Alg(),
+				Use: "sig",
+				N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				KID: key.KID,
+				Kty: "OKP",
+				Alg: key.Method.Alg(),
+				Use: "sig",
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+
+	sort.Slice(set.Keys, func(i, j int) bool {
+		return set.Keys[i].KID < set.Keys[j].KID
+	})
+
+	return set
+}
+
+// LoadSigningKey builds a key from its configuration, reading PEM files
+// when paths are given.
+func LoadSigningKey(cfg config.JWTKey) (*SigningKey, error) {
+	key := &SigningKey{KID: cfg.KID}
+
+	if cfg.ExpiresAt != "" {
+		expiresAt, err := time.Parse(time.RFC3339, cfg.ExpiresAt)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %q: invalid expires_at: %w", cfg.KID, err)
+		}
+		key.ExpiresAt = &expiresAt
+	}
+
+	switch strings.ToUpper(cfg.Algorithm) {
+	case "", "HS256":
+		if cfg.Secret == "" {
+			return nil, fmt.Errorf("jwt key %q: secret is required", cfg.KID)
+		}
+		key.Method = jwt.SigningMethodHS256
+		key.Private = []byte(cfg.Secret)
+		key.Public = []byte(cfg.Secret)
+		return key, nil
+	case "RS256":
+		key.Method = jwt.SigningMethodRS256
+	case "EDDSA":
+		key.Method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("jwt key %q: unsupported algorithm %s", cfg.KID, cfg.Algorithm)
+	}
+
+	privatePEM, err := pemSource(cfg.PrivateKey, cfg.PrivateKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("jwt key %q: %w", cfg.KID, err)
+	}
+	publicPEM, err := pemSource(cfg.PublicKey, cfg.PublicKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("jwt key %q: %w", cfg.KID, err)
+	}
+
+	switch {
+	case privatePEM != nil:
+		private, err := parsePrivateKey(privatePEM)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %q: %w", cfg.KID, err)
+		}
+		key.Private = private
+		key.Public = private.(crypto.Signer).Public()
+	case publicPEM != nil:
+		public, err := parsePublicKey(publicPEM)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %q: %w", cfg.KID, err)
+		}
+		key.Public = public
+	default:
+		return nil, fmt.Errorf("jwt key %q: a private or public key is required", cfg.KID)
+	}
+
+	switch key.Public.(type) {
+	case *rsa.PublicKey:
+		if key.Method != jwt.SigningMethodRS256 {
+			return nil, fmt.Errorf("jwt key %q: RSA key used with %s", cfg.KID, cfg.Algorithm)
+		}
+	case ed25519.PublicKey:
+		if key.Method != jwt.SigningMethodEdDSA {
+			return nil, fmt.Errorf("jwt key %q: Ed25519 key used with %s", cfg.KID, cfg.Algorithm)
+		}
+	default:
+		return nil, fmt.Errorf("jwt key %q: unsupported key type", cfg.KID)
+	}
+
+	return key, nil
+}
+
+func pemSource(inline, path string) ([]byte, error) {
+	if inline != "" {
+		return []byte(inline), nil
+	}
+	if path != "" {
+		return os.ReadFile(path)
+	}
+	return nil, nil
+}
+
+func parsePrivateKey(data []byte) (crypto.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid PEM private key")
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return x509.ParsePKCS1PrivateKey(block.Bytes)
+}
+
+func parsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid PEM public key")
+	}
+
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return x509.ParsePKCS1PublicKey(block.Bytes)
+}
+
+var (
+	keyManagerMu sync.Mutex
+	keyManager   *KeyManager
+)
+
+// LoadKeyManager builds the key manager from configuration and makes it the
+// one used by GenerateToken and DecodeToken. Calling it again rotates keys.
+func LoadKeyManager() (*KeyManager, error) {
+	cfgs, err := config.JWTKeys()
+	if err != nil {
+		return nil, err
+	}
+	if len(cfgs) == 0 {
+		return nil, errors.New("no jwt signing keys configured")
+	}
+
+	keys := make([]*SigningKey, 0, len(cfgs))
+	for _, cfg := range cfgs {
+		key, err := LoadSigningKey(cfg)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	keyManagerMu.Lock()
+	defer keyManagerMu.Unlock()
+
+	if keyManager != nil {
+		if err := keyManager.Rotate(keys, config.JWTActiveKID()); err != nil {
+			return nil, err
+		}
+		return keyManager, nil
+	}
+
+	manager, err := NewKeyManager(keys, config.JWTActiveKID())
+	if err != nil {
+		return nil, err
+	}
+	keyManager = manager
+	return keyManager, nil
+}
+
+func currentKeyManager() (*KeyManager, error) {
+	keyManagerMu.Lock()
+	manager := keyManager
+	keyManagerMu.Unlock()
+
+	if manager != nil {
+		return manager, nil
+	}
+	return LoadKeyManager()
+}
@@ -0,0 +1,55 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/config"
+)
+
+// ValidatePassword checks a new password against the configured policy.
+// Every unmet rule is reported so the user can fix them in one go.
+func ValidatePassword(password string) error {
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+
+	var problems []string
+	if n := config.PasswordMinLength(); len([]rune(password)) < n {
+		problems = append(problems, fmt.Sprintf("at least %d characters", n))
+	}
+	// bcrypt ignores everything after 72 bytes.
+	if len(password) > 72 {
+		problems = append(problems, "at most 72 bytes")
+	}
+	if config.PasswordRequireUpper() && !upper {
+		problems = append(problems, "an uppercase letter")
+	}
+	if config.PasswordRequireLower() && !lower {
+		problems = append(problems, "a lowercase letter")
+	}
+	if config.PasswordRequireDigit() && !digit {
+		problems = append(problems, "a digit")
+	}
+	if config.PasswordRequireSymbol() && !symbol {
+		problems = append(problems, "a symbol")
+	}
+
+	if len(problems) > 0 {
+		return errors.New("password must contain " + strings.Join(problems, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package helper
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestValidatePassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   map[string]interface{}
+		password string
+		// wantErr is the error message, empty when the password is valid.
+		wantErr string
+	}{
+		{
+			name:     "default policy accepts letters and a digit",
+			password: "password1",
+		},
+		{
+			name:     "default policy requires a digit",
+			password: "password",
+			wantErr:  "password must contain a digit",
+		},
+		{
+			name:     "too short",
+			password: "pass1",
+			wantErr:  "password must contain at least 8 characters",
+		},
+		{
+			name:     "length counts characters, not bytes",
+			policy:   map[string]interface{}{"password.min_length": 4},
+			password: "ééé1",
+		},
+		{
+			name:     "longer than bcrypt accepts",
+			password: strings.Repeat("a", 72) + "1",
+			wantErr:  "password must contain at most 72 bytes",
+		},
+		{
+			name:     "digit requirement can be turned off",
+			policy:   map[string]interface{}{"password.require_digit": false},
+			password: "password",
+		},
+		{
+			name: "every unmet rule is reported",
+			policy: map[string]interface{}{
+				"password.min_length":     12,
+				"password.require_upper":  true,
+				"password.require_lower":  true,
+				"password.require_symbol": true,
+			},
+			password: "abc",
+			wantErr:  "password must contain at least 12 characters, an uppercase letter, a digit, a symbol",
+		},
+		{
+			name: "strict policy met",
+			policy: map[string]interface{}{
+				"password.require_upper":  true,
+				"password.require_lower":  true,
+				"password.require_symbol": true,
+			},
+			password: "Passw0rd!",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(viper.Reset)
+			for key, value := range tt.policy {
+				viper.Set(key, value)
+			}
+
+			err := ValidatePassword(tt.password)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ValidatePassword() = %v, want nil", err)
+				}
+				return
+			}
+
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("ValidatePassword() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const maxPublicRedirects = 3
+
+var (
+	ErrHostNotAllowed    = errors.New("host is not in the allowlist")
+	ErrAddressNotAllowed = errors.New("address is not a public address")
+)
+
+// NewPublicHTTPClient returns a client for fetching URLs that come from
+// outside, such as WhatsApp media links. Only http and https URLs on the
+// allowed hosts are fetched, redirects included. Connections to loopback,
+// private, link-local and other non-public addresses are refused when
+// dialing, so a DNS name pointing inside the network is caught as well.
+//
+// An allowed host matches exactly, or "*.example.com" matches any subdomain
+// of example.com. With no allowed hosts every request is refused.
+func NewPublicHTTPClient(timeout time.Duration, allowedHosts []string) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || !isPublicIP(ip) {
+				return fmt.Errorf("%w: %s", ErrAddressNotAllowed, host)
+			}
+			return nil
+		},
+	}
+
+	checkURL := func(u *url.URL) error {
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("unsupported url scheme %q", u.Scheme)
+		}
+		if !hostAllowed(u.Hostname(), allowedHosts) {
+			return fmt.Errorf("%w: %s", ErrHostNotAllowed, u.Hostname())
+		}
+		return nil
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &publicTransport{
+			check: checkURL,
+			next: &http.Transport{
+				// No proxy: it would dial on our behalf and skip the
+				// address check.
+				Proxy:                 nil,
+				DialContext:           dialer.DialContext,
+				ForceAttemptHTTP2:     true,
+				MaxIdleConns:          10,
+				IdleConnTimeout:       90 * time.Second,
+				TLSHandshakeTimeout:   10 * time.Second,
+				ResponseHeaderTimeout: 30 * time.Second,
+			},
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxPublicRedirects {
+				return errors.New("too many redirects")
+			}
+			return checkURL(req.URL)
+		},
+	}
+}
+
+// publicTransport checks every request, not only redirects, so a client
+// built above cannot be pointed at a host outside the allowlist.
+type publicTransport struct {
+	check func(*url.URL) error
+	next  http.RoundTripper
+}
+
+func (t *publicTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.check(req.URL); err != nil {
+		return nil, err
+	}
+	return t.next.RoundTrip(req)
+}
+
+func hostAllowed(host string, allowedHosts []string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "" {
+		return false
+	}
+
+	for _, allowed := range allowedHosts {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if suffix, ok := strings.CutPrefix(allowed, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+		} else if host == allowed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isPublicIP reports whether ip is a globally routable unicast address.
+func isPublicIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+		// 0.0.0.0/8 and the 100.64.0.0/10 carrier-grade NAT range are
+		// not covered by the net.IP helpers.
+		if ip[0] == 0 || ip[0] == 100 && ip[1]&0xc0 == 64 {
+			return false
+		}
+	}
+
+	return ip.IsGlobalUnicast() &&
+		!ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast()
+}
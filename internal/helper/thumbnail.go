@@ -0,0 +1,72 @@
+package helper
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+
+	_ "image/gif"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const ThumbnailMaxSize = 320
+
+// ErrImageTooLarge is returned for images with more pixels than allowed.
+var ErrImageTooLarge = errors.New("image has too many pixels")
+
+// CheckImagePixels reads the image header and fails with ErrImageTooLarge
+// when the image has more than maxPixels pixels. A small file can declare a
+// huge image, decoding it would allocate the full size.
+func CheckImagePixels(data []byte, maxPixels int64) error {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	if int64(cfg.Width)*int64(cfg.Height) > maxPixels {
+		return ErrImageTooLarge
+	}
+
+	return nil
+}
+
+// GenerateThumbnail decodes an image and returns a JPEG that fits inside
+// ThumbnailMaxSize x ThumbnailMaxSize, keeping the aspect ratio. Images with
+// more than maxPixels pixels are not decoded.
+func GenerateThumbnail(data []byte, maxPixels int64) ([]byte, error) {
+	if err := CheckImagePixels(data, maxPixels); err != nil {
+		return nil, err
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if width > ThumbnailMaxSize || height > ThumbnailMaxSize {
+		if width >= height {
+			height = height * ThumbnailMaxSize / width
+			width = ThumbnailMaxSize
+		} else {
+			width = width * ThumbnailMaxSize / height
+			height = ThumbnailMaxSize
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, max(width, 1), max(height, 1)))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 80}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
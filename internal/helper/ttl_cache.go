@@ -0,0 +1,60 @@
+package helper
+
+import (
+	"sync"
+	"time"
+)
+
+type ttlCacheEntry struct {
+	value     any
+	expiresAt time.Time
+}
+
+// TTLCache is a small in-memory cache for values that may be a little stale,
+// such as dashboard aggregates.
+type TTLCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]ttlCacheEntry
+}
+
+func NewTTLCache(ttl time.Duration) *TTLCache {
+	return &TTLCache{
+		ttl:     ttl,
+		entries: make(map[string]ttlCacheEntry),
+	}
+}
+
+func (c *TTLCache) Get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	return entry.value, true
+}
+
+func (c *TTLCache) Set(key string, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+
+	c.entries[key] = ttlCacheEntry{
+		value:     value,
+		expiresAt: now.Add(c.ttl),
+	}
+}
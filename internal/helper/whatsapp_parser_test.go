@@ -0,0 +1,87 @@
+package helper
+
+import "testing"
+
+func TestParseWhatsAppReport(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    ParsedReport
+	}{
+		{
+			name: "full report",
+			message: "Project : Proj A\n" +
+				"Stasiun : Station One\n" +
+				"Part : Printer\n" +
+				"ID : G-01\n" +
+				"Permasalahan : paper jam\n" +
+				"#TS01",
+			want: ParsedReport{
+				Project:  "Proj A",
+				Station:  "Station One",
+				Part:     "Printer",
+				DeviceID: "G-01",
+				Issue:    "paper jam",
+				CodeName: "TS01",
+			},
+		},
+		{
+			name:    "keys are case insensitive and spacing is trimmed",
+			message: "  PROJECT:Proj A  \r\nstasiun:   Station One\npermasalahan :  no power ",
+			want: ParsedReport{
+				Project: "Proj A",
+				Station: "Station One",
+				Issue:   "no power",
+			},
+		},
+		{
+			name:    "value keeps later colons",
+			message: "Permasalahan : error: code 42",
+			want:    ParsedReport{Issue: "error: code 42"},
+		},
+		{
+			name:    "key without a colon has no value",
+			message: "Project Proj A\nPermasalahan : jam",
+			want:    ParsedReport{Issue: "jam"},
+		},
+		{
+			name:    "unknown lines are ignored",
+			message: "halo\nProject : Proj A\nterima kasih",
+			want:    ParsedReport{Project: "Proj A"},
+		},
+		{
+			name:    "empty message",
+			message: "",
+			want:    ParsedReport{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseWhatsAppReport(tt.message); got != tt.want {
+				t.Errorf("ParseWhatsAppReport() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractCCCode(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "laporan #TS01", want: "TS01"},
+		{text: "#AB123 dan #CD4", want: "AB123"},
+		{text: "#ts01", want: ""},
+		{text: "#T01", want: ""},
+		{text: "tanpa kode", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := ExtractCCCode(tt.text); got != tt.want {
+				t.Errorf("ExtractCCCode(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sirupsen/logrus"
+)
+
+// Reasons a WhatsApp message is rejected.
+const (
+	RejectInvalidFormat   = "invalid_format"
+	RejectUnknownUser     = "unknown_user"
+	RejectUnknownProject  = "unknown_project"
+	RejectUnknownLocation = "unknown_location"
+	RejectStoreFailed     = "store_failed"
+	RejectTooManyMedia    = "too_many_media"
+)
+
+// Registry holds every metric served on /metrics.
+var Registry = prometheus.NewRegistry()
+
+var (
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency by route and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	WhatsAppReceived = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "whatsapp_messages_received_total",
+		Help: "WhatsApp messages received by the webhook.",
+	})
+
+	WhatsAppParsed = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "whatsapp_messages_parsed_total",
+		Help: "WhatsApp messages parsed as a troubleshooting report.",
+	})
+
+	WhatsAppRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "whatsapp_messages_rejected_total",
+		Help: "WhatsApp messages that did not become a ticket, by reason.",
+	}, []string{"reason"})
+
+	WhatsAppTickets = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "whatsapp_tickets_created_total",
+		Help: "Tickets created from WhatsApp messages.",
+	})
+
+	WhatsAppMedia = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "whatsapp_media_jobs_total",
+		Help: "Queued WhatsApp media processed, by result: attached, retry or failed.",
+	}, []string{"result"})
+
+	SheetSyncs = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "sheet_sync_total",
+		Help: "Queued tickets appended to the spreadsheet, by result.",
+	}, []string{"result"})
+
+	SheetSyncLag = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "sheet_sync_lag_seconds",
+		Help:    "Time from ticket creation until it is in the spreadsheet, queueing included.",
+		Buckets: []float64{1, 5, 10, 30, 60, 300, 900, 3600, 4 * 3600, 24 * 3600},
+	})
+
+	SheetSyncPending = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "sheet_sync_pending",
+		Help: "Tickets waiting to be appended to the spreadsheet.",
+	})
+
+	SheetLastSync = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "sheet_sync_last_success_timestamp_seconds",
+		Help: "Unix time of the last successful spreadsheet append.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequestDuration,
+		WhatsAppReceived,
+		WhatsAppParsed,
+		WhatsAppRejected,
+		WhatsAppTickets,
+		WhatsAppMedia,
+		SheetSyncs,
+		SheetSyncLag,
+		SheetSyncPending,
+		SheetLastSync,
+	)
+}
+
+// Handler serves the registry in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{
+		// A failed open-ticket count should not hide the other metrics.
+		ErrorHandling: promhttp.ContinueOnError,
+	})
+}
+
+// ObserveSheetSync records the outcome of appending a queued ticket created
+// at createdAt. The lag runs from creation to the append, so it grows while
+// the ticket waits in the queue and while the spreadsheet is failing.
+func ObserveSheetSync(createdAt time.Time, err error) {
+	if err != nil {
+		SheetSyncs.WithLabelValues("failure").Inc()
+		return
+	}
+
+	SheetSyncs.WithLabelValues("success").Inc()
+	SheetSyncLag.Observe(time.Since(createdAt).Seconds())
+	SheetLastSync.SetToCurrentTime()
+}
+
+// RegisterDB exports the connection pool stats of the database.
+func RegisterDB(db *sql.DB) {
+	Registry.MustRegister(collectors.NewDBStatsCollector(db, "main"))
+}
+
+// RegisterOpenTickets exports the open tickets per project and location,
+// counted on every scrape.
+func RegisterOpenTickets(count func(ctx context.Context) ([]*model.OpenTicketCount, error)) {
+	Registry.MustRegister(&openTicketsCollector{
+		count: count,
+		desc: prometheus.NewDesc(
+			"troubleshoot_open_tickets",
+			"Open tickets per project and location.",
+			[]string{"project_id", "project", "location_id", "location"},
+			nil,
+		),
+	})
+}
+
+// openTicketsScrapeTimeout keeps a slow count from stalling the scrape.
+const openTicketsScrapeTimeout = 5 * time.Second
+
+type openTicketsCollector struct {
+	count func(ctx context.Context) ([]*model.OpenTicketCount, error)
+	desc  *prometheus.Desc
+}
+
+func (c *openTicketsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *openTicketsCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), openTicketsScrapeTimeout)
+	defer cancel()
+
+	counts, err := c.count(ctx)
+	if err != nil {
+		logrus.Warn("Failed to count open tickets for metrics: ", err)
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+		return
+	}
+
+	for _, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(count.Count),
+			formatID(count.ProjectID), count.ProjectName,
+			formatID(count.LocationID), count.LocationName,
+		)
+	}
+}
+
+func formatID(id *int64) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatInt(*id, 10)
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// APIKeyPrefix starts every API key so it can be told apart from a JWT.
+const APIKeyPrefix = "ltk_"
+
+// APIKey is a long-lived credential for scripts and integrations. Only the
+// SHA-256 hash of the key is stored; Prefix identifies it in listings.
+type APIKey struct {
+	ID         int64      `gorm:"primaryKey" json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     []string   `gorm:"serializer:json" json:"scopes"`
+	CreatedBy  *int64     `json:"created_by"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// IssuedAPIKey is returned once when a key is created; Key is not stored.
+type IssuedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+type CreateAPIKeyInput struct {
+	Name      string     `json:"name" validate:"required,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,required"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type IAPIKeyRepository interface {
+	FindAll(ctx context.Context) ([]*APIKey, error)
+	FindByID(ctx context.Context, id int64) (*APIKey, error)
+	FindByHash(ctx context.Context, hash string) (*APIKey, error)
+	Create(ctx context.Context, key APIKey) (*APIKey, error)
+	Revoke(ctx context.Context, id int64) error
+	// RevokeByCreator revokes every key the user issued.
+	RevokeByCreator(ctx context.Context, userID int64) error
+	// TouchLastUsed records a use, writing at most once per interval.
+	TouchLastUsed(ctx context.Context, id int64, interval time.Duration) error
+}
+
+type IAPIKeyUsecase interface {
+	FindAll(ctx context.Context) ([]*APIKey, error)
+	Create(ctx context.Context, in CreateAPIKeyInput) (*IssuedAPIKey, error)
+	Revoke(ctx context.Context, id int64) error
+	Authenticate(ctx context.Context, key string) (*CustomClaims, error)
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+type Attachment struct {
+	ID                int64      `gorm:"primaryKey" json:"id"`
+	TroubleshootLogID int64      `json:"troubleshoot_log_id"`
+	FileName          string     `json:"file_name"`
+	ContentType       string     `json:"content_type"`
+	Size              int64      `json:"size"`
+	StorageKey        string     `json:"-"`
+	ThumbnailKey      *string    `json:"-"`
+	Source            string     `json:"source"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+	DeletedAt         *time.Time `json:"-"`
+}
+
+const (
+	AttachmentSourceUpload   = "upload"
+	AttachmentSourceWhatsApp = "whatsapp"
+)
+
+type UploadAttachmentInput struct {
+	TroubleshootLogID int64  `validate:"required"`
+	FileName          string `validate:"required,max=255"`
+	Source            string `validate:"required,oneof=upload whatsapp"`
+	Data              []byte `validate:"required"`
+}
+
+// IFileStorage stores attachment blobs. Keys are slash separated paths
+// relative to the storage root or bucket.
+type IFileStorage interface {
+	Put(ctx context.Context, key string, contentType string, data []byte) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type IAttachmentRepository interface {
+	FindByTroubleshootLogID(ctx context.Context, logID int64) ([]*Attachment, error)
+	FindAllByTroubleshootLogID(ctx context.Context, logID int64) ([]*Attachment, error)
+	FindByID(ctx context.Context, id int64) (*Attachment, error)
+	Create(ctx context.Context, attachment Attachment) (*Attachment, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+type IAttachmentUsecase interface {
+	FindByTroubleshootLogID(ctx context.Context, logID int64) ([]*Attachment, error)
+	Upload(ctx context.Context, in UploadAttachmentInput) (*Attachment, error)
+	Open(ctx context.Context, logID int64, id int64, thumbnail bool) (*Attachment, io.ReadCloser, error)
+	Delete(ctx context.Context, logID int64, id int64) error
+}
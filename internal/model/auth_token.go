@@ -0,0 +1,55 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// RefreshToken is a long-lived credential exchanged for a new access token.
+// Only the SHA-256 hash of the token is stored. Every refresh rotates the
+// token inside the same family; presenting an already rotated token revokes
+// the whole family.
+type RefreshToken struct {
+	ID           int64      `gorm:"primaryKey" json:"id"`
+	UserID       int64      `json:"user_id"`
+	FamilyID     string     `json:"family_id"`
+	TokenHash    string     `json:"-"`
+	ReplacedByID *int64     `json:"replaced_by_id"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// RevokedAccessToken is a denylisted access token, kept until it expires.
+type RevokedAccessToken struct {
+	JTI       string `gorm:"column:jti;primaryKey"`
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+type RefreshTokenInput struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type LogoutInput struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type ITokenRepository interface {
+	CreateRefreshToken(ctx context.Context, token RefreshToken) (*RefreshToken, error)
+	FindRefreshTokenByHash(ctx context.Context, hash string) (*RefreshToken, error)
+	// RotateRefreshToken revokes the old token and stores its replacement.
+	// It reports false when the old token was revoked concurrently.
+	RotateRefreshToken(ctx context.Context, oldID int64, next RefreshToken) (*RefreshToken, bool, error)
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+	RevokeUserRefreshTokens(ctx context.Context, userID int64) error
+	RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error
+	IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error)
+	DeleteExpired(ctx context.Context) error
+}
@@ -10,7 +10,7 @@ type Device struct {
 	Name      string     `json:"name"`
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
-	DeletedAt *time.Time `json:"-"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 type CreateDeviceInput struct {
@@ -22,17 +22,23 @@ type UpdateDeviceInput struct {
 }
 
 type IDeviceRepository interface {
-	FindAll(ctx context.Context, device Device) ([]*Device, error)
+	FindAll(ctx context.Context, device Device, page PageRequest) ([]*Device, *PageMeta, error)
 	FindByID(ctx context.Context, id int64) (*Device, error)
 	Create(ctx context.Context, device Device) (*Device, error)
 	Update(ctx context.Context, device Device) error
-	Delete(ctx context.Context, id int64) error
+	Delete(ctx context.Context, id int64, replaceWith *int64) (int64, error)
+	FindTrash(ctx context.Context, page PageRequest) ([]*Device, *PageMeta, error)
+	Restore(ctx context.Context, id int64) error
+	Purge(ctx context.Context, id int64) error
 }
 
 type IDeviceUsecase interface {
-	FindAll(ctx context.Context, device Device) ([]*Device, error)
+	FindAll(ctx context.Context, device Device, page PageRequest) ([]*Device, *PageMeta, error)
 	FindByID(ctx context.Context, id int64) (*Device, error)
 	Create(ctx context.Context, in CreateDeviceInput) (*Device, error)
 	Update(ctx context.Context, id int64, in UpdateDeviceInput) error
-	Delete(ctx context.Context, id int64) error
+	Delete(ctx context.Context, id int64, replaceWith *int64) error
+	FindTrash(ctx context.Context, page PageRequest) ([]*Device, *PageMeta, error)
+	Restore(ctx context.Context, id int64) error
+	Purge(ctx context.Context, id int64) error
 }
@@ -0,0 +1,138 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Error kinds. Repositories and usecases wrap them in a DomainError with a
+// message meant for the caller; delivery picks the status code with
+// errors.Is.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrForbidden    = errors.New("forbidden")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrValidation   = errors.New("validation failed")
+	ErrInternal     = errors.New("internal error")
+)
+
+// FieldError describes why one input field was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule,omitempty"`
+	Message string `json:"message"`
+}
+
+// DomainError is an error of a known kind whose message is safe to show to
+// the caller. Details carries extra data for the caller, e.g. the records
+// that block a delete.
+type DomainError struct {
+	Kind    error
+	Message string
+	Fields  []FieldError
+	Details interface{}
+}
+
+func (e *DomainError) Error() string {
+	return e.Message
+}
+
+func (e *DomainError) Unwrap() error {
+	return e.Kind
+}
+
+func NewNotFoundError(format string, args ...interface{}) error {
+	return &DomainError{Kind: ErrNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+func NewConflictError(format string, args ...interface{}) error {
+	return &DomainError{Kind: ErrConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+func NewForbiddenError(format string, args ...interface{}) error {
+	return &DomainError{Kind: ErrForbidden, Message: fmt.Sprintf(format, args...)}
+}
+
+func NewUnauthorizedError(format string, args ...interface{}) error {
+	return &DomainError{Kind: ErrUnauthorized, Message: fmt.Sprintf(format, args...)}
+}
+
+func NewValidationError(format string, args ...interface{}) error {
+	return &DomainError{Kind: ErrValidation, Message: fmt.Sprintf(format, args...)}
+}
+
+// NewInternalError reports a broken invariant, such as a repository that
+// returned neither a record nor an error. It maps to a 500.
+func NewInternalError(format string, args ...interface{}) error {
+	return &DomainError{Kind: ErrInternal, Message: fmt.Sprintf(format, args...)}
+}
+
+// NewFieldError rejects a single input field.
+func NewFieldError(field, rule, message string) error {
+	return &DomainError{
+		Kind:    ErrValidation,
+		Message: message,
+		Fields:  []FieldError{{Field: field, Rule: rule, Message: message}},
+	}
+}
+
+// ValidationFieldErrors turns validator errors into field errors with a
+// readable message.
+func ValidationFieldErrors(errs validator.ValidationErrors) []FieldError {
+	fields := make([]FieldError, 0, len(errs))
+	for _, fieldErr := range errs {
+		fields = append(fields, FieldError{
+			Field:   fieldPath(fieldErr),
+			Rule:    fieldErr.Tag(),
+			Message: validationMessage(fieldErr),
+		})
+	}
+	return fields
+}
+
+// fieldPath drops the struct name from the validator namespace, so
+// "CreateDeviceInput.name" is reported as "name" and nested fields keep
+// their path.
+func fieldPath(fieldErr validator.FieldError) string {
+	_, path, found := strings.Cut(fieldErr.Namespace(), ".")
+	if !found {
+		return fieldErr.Field()
+	}
+	return path
+}
+
+func validationMessage(fieldErr validator.FieldError) string {
+	field := fieldErr.Field()
+	param := fieldErr.Param()
+
+	unit := ""
+	switch fieldErr.Kind() {
+	case reflect.String:
+		unit = " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		unit = " items"
+	}
+
+	switch fieldErr.Tag() {
+	case "required":
+		return field + " is required"
+	case "oneof":
+		return field + " must be one of " + strings.Join(strings.Fields(param), ", ")
+	case "min", "gte":
+		return fmt.Sprintf("%s must be at least %s%s", field, param, unit)
+	case "max", "lte":
+		return fmt.Sprintf("%s must be at most %s%s", field, param, unit)
+	case "email":
+		return field + " must be a valid email address"
+	default:
+		if param != "" {
+			return fmt.Sprintf("%s failed the %s=%s rule", field, fieldErr.Tag(), param)
+		}
+		return fmt.Sprintf("%s failed the %s rule", field, fieldErr.Tag())
+	}
+}
@@ -1,7 +1,26 @@
 package model
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type ISpreadsheetRepository interface {
-	AppendTroubleshoot(ctx context.Context, log *TroubleshootLog) error
+	// Ping checks the spreadsheet is reachable.
+	Ping(ctx context.Context) error
+	// AppendTroubleshoot appends log as a new row and returns the row
+	// number, 0 when the sheet did not report it.
+	AppendTroubleshoot(ctx context.Context, log *TroubleshootLog) (int, error)
+}
+
+// ISheetSyncRepository is the queue of tickets waiting to be appended to the
+// spreadsheet. A ticket is claimed before it is appended, so only one
+// instance appends it. A claim older than staleBefore is taken over, the
+// instance that held it is assumed gone.
+type ISheetSyncRepository interface {
+	FindPending(ctx context.Context, limit int) ([]*TroubleshootLog, error)
+	CountPending(ctx context.Context) (int64, error)
+	Claim(ctx context.Context, id int64, staleBefore time.Time) (bool, error)
+	MarkSynced(ctx context.Context, id int64, sheetRow int) error
+	Release(ctx context.Context, id int64) error
 }
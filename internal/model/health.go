@@ -0,0 +1,25 @@
+package model
+
+import "context"
+
+const (
+	HealthStatusOK          = "ok"
+	HealthStatusDegraded    = "degraded"
+	HealthStatusUnavailable = "unavailable"
+)
+
+// HealthCheck tells whether a dependency the service needs to serve
+// requests is reachable. An optional dependency, such as one only background
+// jobs use, degrades the report when it fails but keeps the service ready.
+type HealthCheck struct {
+	Name     string
+	Check    func(ctx context.Context) error
+	Optional bool
+}
+
+// HealthReport is the overall status with the outcome of every check,
+// keyed by check name.
+type HealthReport struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
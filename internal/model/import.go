@@ -0,0 +1,128 @@
+package model
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Entities that can be imported in bulk.
+const (
+	ImportEntityProject  = "project"
+	ImportEntityLocation = "location"
+	ImportEntityDevice   = "device"
+	ImportEntityWorkType = "work_type"
+	ImportEntityUser     = "user"
+)
+
+const (
+	ImportFormatCSV  = "csv"
+	ImportFormatXLSX = "xlsx"
+)
+
+// What an import did, or in a dry run would do, with a row.
+const (
+	ImportActionCreate    = "create"
+	ImportActionUpdate    = "update"
+	ImportActionUnchanged = "unchanged"
+	ImportActionError     = "error"
+)
+
+// ImportInput is a CSV or XLSX file whose first row names the columns. The
+// format is taken from the file name.
+type ImportInput struct {
+	Entity   string `validate:"required,oneof=project location device work_type user"`
+	FileName string `validate:"required"`
+	Data     []byte `validate:"required"`
+	DryRun   bool
+}
+
+// Import rows are matched to existing records by their key: the name for
+// projects, devices and work types, the code name for locations and users.
+// Column names in the file are the json names.
+type ImportProjectRow struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type ImportLocationRow struct {
+	Name     string `json:"name" validate:"required,max=100"`
+	CodeName string `json:"code_name" validate:"required,max=10"`
+}
+
+type ImportDeviceRow struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type ImportWorkTypeRow struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// ImportUserRow needs a password for new users only. Existing users keep
+// their password, a row giving one for them fails.
+type ImportUserRow struct {
+	Name     string `json:"name" validate:"required,max=100"`
+	CodeName string `json:"code_name" validate:"required,max=10"`
+	Username string `json:"username" validate:"required,max=100"`
+	Password string `json:"password" validate:"max=72"`
+	Role     string `json:"role" validate:"required"`
+}
+
+// ImportItem is a parsed row together with its row number in the file.
+type ImportItem[T any] struct {
+	Row   int
+	Value T
+}
+
+type ImportRowResult struct {
+	Row    int          `json:"row"`
+	Key    string       `json:"key"`
+	Action string       `json:"action"`
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// RowErrors describes why a row was rejected.
+func RowErrors(err error) []FieldError {
+	var (
+		fieldErrs validator.ValidationErrors
+		domainErr *DomainError
+	)
+
+	switch {
+	case errors.As(err, &fieldErrs):
+		return ValidationFieldErrors(fieldErrs)
+	case errors.As(err, &domainErr) && len(domainErr.Fields) > 0:
+		return domainErr.Fields
+	default:
+		return []FieldError{{Message: err.Error()}}
+	}
+}
+
+// ImportReport lists the outcome of every row. Nothing is committed when
+// it is a dry run or when any row failed.
+type ImportReport struct {
+	Entity    string             `json:"entity"`
+	DryRun    bool               `json:"dry_run"`
+	Committed bool               `json:"committed"`
+	Total     int                `json:"total"`
+	Created   int                `json:"created"`
+	Updated   int                `json:"updated"`
+	Unchanged int                `json:"unchanged"`
+	Failed    int                `json:"failed"`
+	Rows      []*ImportRowResult `json:"rows"`
+}
+
+// IImportRepository upserts all rows in one transaction, each row behind a
+// savepoint so one failure does not hide the outcome of the others. The
+// transaction is rolled back when rollback is set or any row failed.
+type IImportRepository interface {
+	UpsertProjects(ctx context.Context, items []ImportItem[ImportProjectRow], rollback bool) ([]*ImportRowResult, error)
+	UpsertLocations(ctx context.Context, items []ImportItem[ImportLocationRow], rollback bool) ([]*ImportRowResult, error)
+	UpsertDevices(ctx context.Context, items []ImportItem[ImportDeviceRow], rollback bool) ([]*ImportRowResult, error)
+	UpsertWorkTypes(ctx context.Context, items []ImportItem[ImportWorkTypeRow], rollback bool) ([]*ImportRowResult, error)
+	UpsertUsers(ctx context.Context, items []ImportItem[ImportUserRow], rollback bool) ([]*ImportRowResult, error)
+}
+
+type IImportUsecase interface {
+	Import(ctx context.Context, in ImportInput) (*ImportReport, error)
+}
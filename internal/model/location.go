@@ -11,7 +11,7 @@ type Location struct {
 	CodeName  string     `json:"code_name"`
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
-	DeletedAt *time.Time `json:"-"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 type CreateLocationInput struct {
@@ -25,18 +25,24 @@ type UpdateLocationInput struct {
 }
 
 type ILocationRepository interface {
-	FindAll(ctx context.Context, location Location) ([]*Location, error)
+	FindAll(ctx context.Context, location Location, page PageRequest) ([]*Location, *PageMeta, error)
 	FindByID(ctx context.Context, id int64) (*Location, error)
 	Create(ctx context.Context, location Location) (*Location, error)
 	Update(ctx context.Context, location Location) error
-	Delete(ctx context.Context, id int64) error
+	Delete(ctx context.Context, id int64, replaceWith *int64) (int64, error)
+	FindTrash(ctx context.Context, page PageRequest) ([]*Location, *PageMeta, error)
+	Restore(ctx context.Context, id int64) error
+	Purge(ctx context.Context, id int64) error
 	FindByName(ctx context.Context, name string) (*Location, error)
 }
 
 type ILocationUsecase interface {
-	FindAll(ctx context.Context, location Location) ([]*Location, error)
+	FindAll(ctx context.Context, location Location, page PageRequest) ([]*Location, *PageMeta, error)
 	FindByID(ctx context.Context, id int64) (*Location, error)
 	Create(ctx context.Context, in CreateLocationInput) (*Location, error)
 	Update(ctx context.Context, id int64, in UpdateLocationInput) error
-	Delete(ctx context.Context, id int64) error
+	Delete(ctx context.Context, id int64, replaceWith *int64) error
+	FindTrash(ctx context.Context, page PageRequest) ([]*Location, *PageMeta, error)
+	Restore(ctx context.Context, id int64) error
+	Purge(ctx context.Context, id int64) error
 }
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	LoginAttemptScopeUsername = "username"
+	LoginAttemptScopeIP       = "ip"
+)
+
+// ErrInvalidCredentials is returned for every failed login, whether the
+// username exists or not.
+var ErrInvalidCredentials error = &DomainError{Kind: ErrUnauthorized, Message: "username or password is wrong"}
+
+// LoginAttempt counts recent failed logins for one username or client IP.
+type LoginAttempt struct {
+	Scope         string     `gorm:"primaryKey" json:"scope"`
+	Key           string     `gorm:"primaryKey" json:"key"`
+	Failures      int        `json:"failures"`
+	LastFailureAt time.Time  `json:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until"`
+}
+
+// LoginThrottledError means the caller must wait before trying again.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, try again in %s", e.RetryAfter.Round(time.Second))
+}
+
+type ILoginAttemptRepository interface {
+	Find(ctx context.Context, scope, key string) (*LoginAttempt, error)
+	// RecordFailure bumps the failure count, starting over when the previous
+	// failure is older than resetBefore.
+	RecordFailure(ctx context.Context, scope, key string, resetBefore time.Time) (*LoginAttempt, error)
+	Lock(ctx context.Context, scope, key string, until time.Time) error
+	Reset(ctx context.Context, scope, key string) error
+}
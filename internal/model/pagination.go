@@ -0,0 +1,26 @@
+package model
+
+// PageRequest describes which slice of a list to return. Page mode uses
+// Page/Limit. Cursor mode is used when CursorMode is set: Cursor is the
+// opaque NextCursor from the previous response, empty for the first page.
+type PageRequest struct {
+	Page       int
+	Limit      int
+	CursorMode bool
+	Cursor     string
+	SortBy     string
+	SortDir    string
+}
+
+type PageMeta struct {
+	Total      int64  `json:"total"`
+	Page       int    `json:"page,omitempty"`
+	Limit      int    `json:"limit"`
+	TotalPages int    `json:"total_pages,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
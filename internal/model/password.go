@@ -0,0 +1,41 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// PasswordResetToken lets a user set a new password once, after an admin
+// forced a reset. Only the SHA-256 hash of the token is stored.
+type PasswordResetToken struct {
+	ID        int64      `gorm:"primaryKey" json:"id"`
+	UserID    int64      `json:"user_id"`
+	TokenHash string     `json:"-"`
+	CreatedBy *int64     `json:"created_by"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type ChangePasswordInput struct {
+	OldPassword string `json:"old_password" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,max=72"`
+}
+
+type ResetPasswordInput struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,max=72"`
+}
+
+type PasswordReset struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type IPasswordResetRepository interface {
+	// Create stores the token and invalidates the user's earlier unused ones.
+	Create(ctx context.Context, token PasswordResetToken) (*PasswordResetToken, error)
+	FindByHash(ctx context.Context, hash string) (*PasswordResetToken, error)
+	// MarkUsed reports false when the token was already used.
+	MarkUsed(ctx context.Context, id int64) (bool, error)
+}
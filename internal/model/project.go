@@ -10,7 +10,7 @@ type Project struct {
 	Name      string     `json:"name"`
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
-	DeletedAt *time.Time `json:"-"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 type CreateProjectInput struct {
@@ -23,17 +23,23 @@ type UpdateProjectInput struct {
 
 type IProjectRepository interface {
 	Create(ctx context.Context, project Project) (*Project, error)
-	FindAll(ctx context.Context, project Project) ([]*Project, error)
+	FindAll(ctx context.Context, project Project, page PageRequest) ([]*Project, *PageMeta, error)
 	FindByID(ctx context.Context, id int64) (*Project, error)
 	Update(ctx context.Context, project Project) error
-	Delete(ctx context.Context, id int64) error
+	Delete(ctx context.Context, id int64, replaceWith *int64) (int64, error)
+	FindTrash(ctx context.Context, page PageRequest) ([]*Project, *PageMeta, error)
+	Restore(ctx context.Context, id int64) error
+	Purge(ctx context.Context, id int64) error
 	FindByName(ctx context.Context, name string) (*Project, error)
 }
 
 type IProjectUsecase interface {
-	FindAll(ctx context.Context, project Project) ([]*Project, error)
+	FindAll(ctx context.Context, project Project, page PageRequest) ([]*Project, *PageMeta, error)
 	FindByID(ctx context.Context, id int64) (*Project, error)
 	Create(ctx context.Context, in CreateProjectInput) (*Project, error)
 	Update(ctx context.Context, id int64, in UpdateProjectInput) error
-	Delete(ctx context.Context, id int64) error
+	Delete(ctx context.Context, id int64, replaceWith *int64) error
+	FindTrash(ctx context.Context, page PageRequest) ([]*Project, *PageMeta, error)
+	Restore(ctx context.Context, id int64) error
+	Purge(ctx context.Context, id int64) error
 }
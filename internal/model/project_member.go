@@ -0,0 +1,63 @@
+package model
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+const ProjectScopeKey ContextAuthKey = "ProjectScope"
+
+type ProjectMember struct {
+	ProjectID int64     `gorm:"primaryKey" json:"project_id"`
+	UserID    int64     `gorm:"primaryKey" json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ProjectMemberInput struct {
+	UserID int64 `json:"user_id" validate:"required"`
+}
+
+// ProjectScope lists the projects whose tickets the caller may see. All is
+// set for callers with the project:all permission.
+type ProjectScope struct {
+	All        bool
+	ProjectIDs []int64
+}
+
+// ProjectScopeFromContext returns the caller's scope, or nil when the
+// request is not made on behalf of a user (background jobs, webhooks).
+func ProjectScopeFromContext(ctx context.Context) *ProjectScope {
+	scope, _ := ctx.Value(ProjectScopeKey).(*ProjectScope)
+	return scope
+}
+
+// WithGlobalScope returns a context whose queries see every project. It is
+// for system integrations acting on their own behalf rather than for the
+// user who issued their API key.
+func WithGlobalScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ProjectScopeKey, &ProjectScope{All: true})
+}
+
+// Allows reports whether a ticket of the project is visible. Tickets
+// without a project are only visible to callers with global access.
+func (s *ProjectScope) Allows(projectID *int64) bool {
+	if s == nil || s.All {
+		return true
+	}
+	return projectID != nil && slices.Contains(s.ProjectIDs, *projectID)
+}
+
+type IProjectMemberRepository interface {
+	FindMembers(ctx context.Context, projectID int64) ([]*User, error)
+	FindProjectIDsByUser(ctx context.Context, userID int64) ([]int64, error)
+	Add(ctx context.Context, member ProjectMember) error
+	Remove(ctx context.Context, projectID int64, userID int64) error
+}
+
+type IProjectMemberUsecase interface {
+	FindMembers(ctx context.Context, projectID int64) ([]*User, error)
+	Add(ctx context.Context, projectID int64, in ProjectMemberInput) error
+	Remove(ctx context.Context, projectID int64, userID int64) error
+	ResolveScope(ctx context.Context, claims *CustomClaims) (*ProjectScope, error)
+}
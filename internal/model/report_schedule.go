@@ -0,0 +1,120 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	ReportDeliverySent   = "SENT"
+	ReportDeliveryFailed = "FAILED"
+)
+
+type ReportSchedule struct {
+	ID             int64      `gorm:"primaryKey" json:"id"`
+	ProjectID      int64      `json:"project_id"`
+	Name           string     `json:"name"`
+	CronExpression string     `json:"cron_expression"`
+	Timezone       string     `json:"timezone"`
+	Period         string     `json:"period"`
+	Target         string     `json:"target"`
+	Template       string     `json:"template"`
+	Enabled        bool       `json:"enabled"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty"`
+}
+
+type ReportDelivery struct {
+	ID               int64      `gorm:"primaryKey" json:"id"`
+	ReportScheduleID int64      `json:"report_schedule_id"`
+	PeriodStart      time.Time  `json:"period_start"`
+	PeriodEnd        time.Time  `json:"period_end"`
+	Target           string     `json:"target"`
+	Message          string     `json:"message"`
+	Status           string     `json:"status"`
+	Error            *string    `json:"error"`
+	Attempts         int        `json:"attempts"`
+	SentAt           *time.Time `json:"sent_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// ReportScheduleRun claims one scheduled slot of a schedule, so that only
+// one instance sends the report when several run the scheduler.
+type ReportScheduleRun struct {
+	ReportScheduleID int64     `gorm:"primaryKey"`
+	ScheduledAt      time.Time `gorm:"primaryKey"`
+	CreatedAt        time.Time
+}
+
+// ReportStationSummary is one line of a recap: ticket counts of a station
+// over the report period. Open counts every unresolved ticket, however old.
+type ReportStationSummary struct {
+	LocationID   *int64 `json:"location_id"`
+	LocationName string `json:"location_name"`
+	New          int64  `json:"new"`
+	Closed       int64  `json:"closed"`
+	Open         int64  `json:"open"`
+}
+
+// ReportData is what a schedule template is rendered with.
+type ReportData struct {
+	ProjectName string
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	Stations    []*ReportStationSummary
+	TotalNew    int64
+	TotalClosed int64
+	TotalOpen   int64
+}
+
+type ReportScheduleInput struct {
+	ProjectID      int64  `json:"project_id" validate:"required"`
+	Name           string `json:"name" validate:"required,max=100"`
+	CronExpression string `json:"cron_expression" validate:"required,max=100"`
+	Timezone       string `json:"timezone" validate:"omitempty,max=50"`
+	Period         string `json:"period" validate:"omitempty,oneof=day week month"`
+	Target         string `json:"target" validate:"required,max=150"`
+	Template       string `json:"template"`
+	Enabled        *bool  `json:"enabled"`
+}
+
+// IMessageSender delivers a text message to a chat target such as a
+// WhatsApp group.
+type IMessageSender interface {
+	Send(ctx context.Context, target string, message string) error
+}
+
+type IReportScheduleRepository interface {
+	FindAll(ctx context.Context) ([]*ReportSchedule, error)
+	FindByID(ctx context.Context, id int64) (*ReportSchedule, error)
+	Create(ctx context.Context, schedule ReportSchedule) (*ReportSchedule, error)
+	Update(ctx context.Context, schedule ReportSchedule) error
+	Delete(ctx context.Context, id int64) error
+	FindTrash(ctx context.Context, page PageRequest) ([]*ReportSchedule, *PageMeta, error)
+	Restore(ctx context.Context, id int64) error
+	Purge(ctx context.Context, id int64) error
+	StationSummary(ctx context.Context, projectID int64, from time.Time, to time.Time) ([]*ReportStationSummary, error)
+	FindDeliveries(ctx context.Context, scheduleID int64, limit int) ([]*ReportDelivery, error)
+	FindDeliveryByID(ctx context.Context, id int64) (*ReportDelivery, error)
+	CreateDelivery(ctx context.Context, delivery ReportDelivery) (*ReportDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery ReportDelivery) error
+	ClaimRun(ctx context.Context, scheduleID int64, scheduledAt time.Time) (bool, error)
+}
+
+type IReportScheduleUsecase interface {
+	FindAll(ctx context.Context) ([]*ReportSchedule, error)
+	FindByID(ctx context.Context, id int64) (*ReportSchedule, error)
+	Create(ctx context.Context, in ReportScheduleInput) (*ReportSchedule, error)
+	Update(ctx context.Context, id int64, in ReportScheduleInput) error
+	Delete(ctx context.Context, id int64) error
+	FindTrash(ctx context.Context, page PageRequest) ([]*ReportSchedule, *PageMeta, error)
+	Restore(ctx context.Context, id int64) error
+	Purge(ctx context.Context, id int64) error
+	Run(ctx context.Context, id int64, periodEnd time.Time) (*ReportDelivery, error)
+	FindDeliveries(ctx context.Context, scheduleID int64) ([]*ReportDelivery, error)
+	Resend(ctx context.Context, deliveryID int64) (*ReportDelivery, error)
+	Start(ctx context.Context) error
+	Stop()
+}
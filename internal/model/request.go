@@ -0,0 +1,15 @@
+package model
+
+import "context"
+
+const RequestIDKey ContextAuthKey = "RequestID"
+
+// RequestIDFromContext returns the correlation ID of the HTTP request the
+// context belongs to, or "" for work not started by a request.
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(RequestIDKey).(string)
+	return id
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	PermissionTicketRead      = "ticket:read"
+	PermissionTicketWrite     = "ticket:write"
+	PermissionTicketClose     = "ticket:close"
+	PermissionTicketDelete    = "ticket:delete"
+	PermissionMasterDataRead  = "masterdata:read"
+	PermissionMasterDataWrite = "masterdata:write"
+	PermissionUserRead        = "user:read"
+	PermissionUserWrite       = "user:write"
+	PermissionStatsRead       = "stats:read"
+	PermissionReportManage    = "report:manage"
+	PermissionRoleManage      = "role:manage"
+	PermissionAPIKeyManage    = "apikey:manage"
+	PermissionProjectAll      = "project:all"
+	PermissionTrashPurge      = "trash:purge"
+	PermissionWhatsAppWebhook = "webhook:whatsapp"
+)
+
+type Role struct {
+	ID          int64     `gorm:"primaryKey" json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `gorm:"-" json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type Permission struct {
+	ID          int64  `gorm:"primaryKey" json:"id"`
+	Code        string `json:"code"`
+	Description string `json:"description"`
+}
+
+type RoleInput struct {
+	Name        string   `json:"name" validate:"required,max=50"`
+	Description string   `json:"description" validate:"max=255"`
+	Permissions []string `json:"permissions" validate:"dive,required"`
+}
+
+type IRoleRepository interface {
+	FindAll(ctx context.Context) ([]*Role, error)
+	FindByID(ctx context.Context, id int64) (*Role, error)
+	FindByName(ctx context.Context, name string) (*Role, error)
+	Create(ctx context.Context, role Role) (*Role, error)
+	Update(ctx context.Context, role Role) error
+	Delete(ctx context.Context, id int64) error
+	FindPermissions(ctx context.Context) ([]*Permission, error)
+}
+
+type IRoleUsecase interface {
+	FindAll(ctx context.Context) ([]*Role, error)
+	FindByID(ctx context.Context, id int64) (*Role, error)
+	Create(ctx context.Context, in RoleInput) (*Role, error)
+	Update(ctx context.Context, id int64, in RoleInput) error
+	Delete(ctx context.Context, id int64) error
+	FindPermissions(ctx context.Context) ([]*Permission, error)
+}
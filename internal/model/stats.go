@@ -0,0 +1,64 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	StatsByStatus     = "status"
+	StatsByProject    = "project"
+	StatsByLocation   = "location"
+	StatsByDevice     = "device"
+	StatsByPart       = "part"
+	StatsByWorkType   = "work_type"
+	StatsByTechnician = "technician"
+)
+
+// StatsFilter selects the tickets reported (trouble_date) in [From, To).
+type StatsFilter struct {
+	From       time.Time
+	To         time.Time
+	ProjectID  *int64
+	LocationID *int64
+	By         string `validate:"omitempty,oneof=status project location device part work_type technician"`
+	Interval   string `validate:"omitempty,oneof=day week month"`
+}
+
+// A ticket counts as resolved once it has a done date, regardless of the
+// free-form status text. MTTR is the mean of done minus trouble date/time.
+type StatsSummary struct {
+	Total       int64    `json:"total"`
+	Open        int64    `json:"open"`
+	Resolved    int64    `json:"resolved"`
+	MTTRMinutes *float64 `json:"mttr_minutes"`
+}
+
+type StatsGroup struct {
+	ID          *int64   `json:"id,omitempty"`
+	Key         string   `json:"key"`
+	Total       int64    `json:"total"`
+	Open        int64    `json:"open"`
+	Resolved    int64    `json:"resolved"`
+	MTTRMinutes *float64 `json:"mttr_minutes"`
+}
+
+type StatsTimeSeriesPoint struct {
+	Period      time.Time `json:"period"`
+	Reported    int64     `json:"reported"`
+	Resolved    int64     `json:"resolved"`
+	OpenBacklog int64     `json:"open_backlog"`
+	MTTRMinutes *float64  `json:"mttr_minutes"`
+}
+
+type IStatsRepository interface {
+	Summary(ctx context.Context, filter StatsFilter) (*StatsSummary, error)
+	Breakdown(ctx context.Context, filter StatsFilter) ([]*StatsGroup, error)
+	TimeSeries(ctx context.Context, filter StatsFilter) ([]*StatsTimeSeriesPoint, error)
+}
+
+type IStatsUsecase interface {
+	Summary(ctx context.Context, filter StatsFilter) (*StatsSummary, error)
+	Breakdown(ctx context.Context, filter StatsFilter) ([]*StatsGroup, error)
+	TimeSeries(ctx context.Context, filter StatsFilter) ([]*StatsTimeSeriesPoint, error)
+}
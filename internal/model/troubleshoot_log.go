@@ -2,6 +2,7 @@ package model
 
 import (
 	"context"
+	"io"
 	"time"
 )
 
@@ -27,23 +28,193 @@ type TroubleshootLog struct {
 	WhatsappMessage string     `json:"whatsapp_message"`
 	SheetID         string     `json:"sheet_id"`
 	SheetRow        *int       `json:"sheet_row"`
-	CreatedAt       time.Time  `json:"created_at"`
-	UpdatedAt       time.Time  `json:"updated_at"`
-	DeletedAt       *time.Time `json:"-"`
+	// SheetSyncPending queues the ticket for the spreadsheet, see
+	// ISheetSyncRepository.
+	SheetSyncPending   bool       `json:"-"`
+	SheetSyncClaimedAt *time.Time `json:"-"`
+	IsRecurring        bool       `json:"is_recurring"`
+	RecurrenceOfID     *int64     `json:"recurrence_of_id"`
+	RecurrenceCount    int        `json:"recurrence_count"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+	DeletedAt          *time.Time `json:"deleted_at,omitempty"`
+}
+
+const TicketStatusOpen = "OPEN"
+
+// Master-data columns of a ticket. Deleting a project, location, device or
+// work type is checked against the open tickets in its column.
+const (
+	TicketColumnProject  = "project_id"
+	TicketColumnLocation = "location_id"
+	TicketColumnDevice   = "device_id"
+	TicketColumnWorkType = "work_type_id"
+)
+
+// OpenTicketCount is the number of open tickets of a project and location.
+type OpenTicketCount struct {
+	ProjectID    *int64
+	ProjectName  string
+	LocationID   *int64
+	LocationName string
+	Count        int64
+}
+
+// TicketReference identifies an open ticket that blocks a delete.
+type TicketReference struct {
+	ID           int64  `json:"id"`
+	TicketNumber string `json:"ticket_number"`
+	Status       string `json:"status"`
+}
+
+// CreateTroubleshootLogInput is what a client may set on a new ticket. The
+// duration, recurrence, WhatsApp and sheet fields are set by the server.
+// TroubleTime defaults to the time of TroubleDate.
+type CreateTroubleshootLogInput struct {
+	TroubleDate  time.Time  `json:"trouble_date" validate:"required"`
+	TroubleTime  *time.Time `json:"trouble_time"`
+	DoneDate     *time.Time `json:"done_date"`
+	DoneTime     *time.Time `json:"done_time"`
+	UserID       *int64     `json:"user_id" validate:"omitnil,min=1"`
+	ProjectID    *int64     `json:"project_id" validate:"omitnil,min=1"`
+	LocationID   *int64     `json:"location_id" validate:"omitnil,min=1"`
+	DeviceID     *int64     `json:"device_id" validate:"omitnil,min=1"`
+	WorkTypeID   *int64     `json:"work_type_id" validate:"omitnil,min=1"`
+	DeviceNumber string     `json:"device_number" validate:"max=20"`
+	Part         string     `json:"part" validate:"max=100"`
+	Issue        string     `json:"issue" validate:"required"`
+	Solution     string     `json:"solution"`
+	Status       string     `json:"status" validate:"max=20"`
+}
+
+// UpdateTroubleshootLogInput changes only the fields that are sent.
+type UpdateTroubleshootLogInput struct {
+	TroubleDate  *time.Time `json:"trouble_date"`
+	TroubleTime  *time.Time `json:"trouble_time"`
+	DoneDate     *time.Time `json:"done_date"`
+	DoneTime     *time.Time `json:"done_time"`
+	UserID       *int64     `json:"user_id" validate:"omitnil,min=1"`
+	ProjectID    *int64     `json:"project_id" validate:"omitnil,min=1"`
+	LocationID   *int64     `json:"location_id" validate:"omitnil,min=1"`
+	DeviceID     *int64     `json:"device_id" validate:"omitnil,min=1"`
+	WorkTypeID   *int64     `json:"work_type_id" validate:"omitnil,min=1"`
+	DeviceNumber *string    `json:"device_number" validate:"omitnil,max=20"`
+	Part         *string    `json:"part" validate:"omitnil,max=100"`
+	Issue        *string    `json:"issue" validate:"omitnil,min=1"`
+	Solution     *string    `json:"solution"`
+	Status       *string    `json:"status" validate:"omitnil,min=1,max=20"`
+}
+
+// TroubleshootLogFilter narrows the ticket listing. Reporter matches the
+// WhatsApp sender, assignee is the technician in UserID. The *To dates are
+// exclusive.
+type TroubleshootLogFilter struct {
+	TicketNumber    string
+	Statuses        []string
+	ProjectID       *int64
+	LocationID      *int64
+	DeviceID        *int64
+	WorkTypeID      *int64
+	AssigneeID      *int64
+	Reporter        string
+	Part            string
+	TroubleDateFrom *time.Time
+	TroubleDateTo   *time.Time
+	DoneDateFrom    *time.Time
+	DoneDateTo      *time.Time
+	CreatedFrom     *time.Time
+	CreatedTo       *time.Time
+	SortBy          string `validate:"omitempty,oneof=id ticket_number trouble_date done_date status created_at updated_at"`
+	SortDir         string `validate:"omitempty,oneof=asc desc"`
+}
+
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatXLSX = "xlsx"
+	ExportFormatPDF  = "pdf"
+)
+
+type TroubleshootLogExportRow struct {
+	TroubleshootLog `gorm:"embedded"`
+	UserName        *string
+	ProjectName     *string
+	LocationName    *string
+	DeviceName      *string
+	WorkTypeName    *string
+}
+
+type RepeatOffenderFilter struct {
+	From        time.Time
+	To          time.Time
+	ProjectID   *int64
+	LocationID  *int64
+	PerLocation int `validate:"min=1,max=50"`
+}
+
+// RepeatOffender is a device and part combination that keeps failing at a
+// location.
+type RepeatOffender struct {
+	LocationID     *int64    `json:"location_id"`
+	LocationName   string    `json:"location_name"`
+	DeviceID       *int64    `json:"device_id"`
+	DeviceNumber   string    `json:"device_number"`
+	Part           string    `json:"part"`
+	Occurrences    int64     `json:"occurrences"`
+	Recurrences    int64     `json:"recurrences"`
+	FirstSeen      time.Time `json:"first_seen"`
+	LastSeen       time.Time `json:"last_seen"`
+	LatestTicketID int64     `json:"latest_ticket_id"`
+}
+
+type SearchTroubleshootLogInput struct {
+	Query string `validate:"required,min=2,max=200"`
+	Page  int    `validate:"min=1"`
+	Limit int    `validate:"min=1,max=100"`
+}
+
+// TroubleshootLogHighlight holds search snippets as HTML: the text is
+// escaped and the matched words are wrapped in <mark>.
+type TroubleshootLogHighlight struct {
+	Issue           string `json:"issue,omitempty"`
+	Solution        string `json:"solution,omitempty"`
+	Part            string `json:"part,omitempty"`
+	WhatsappMessage string `json:"whatsapp_message,omitempty"`
+}
+
+type TroubleshootLogSearchResult struct {
+	TroubleshootLog `gorm:"embedded"`
+	Rank            float64                  `json:"rank"`
+	Highlight       TroubleshootLogHighlight `gorm:"embedded;embeddedPrefix:highlight_" json:"highlight"`
 }
 
 type ITroubleshootLogRepository interface {
-	FindAll(ctx context.Context, log TroubleshootLog) ([]*TroubleshootLog, error)
+	FindAll(ctx context.Context, filter TroubleshootLogFilter, page PageRequest) ([]*TroubleshootLog, *PageMeta, error)
 	FindByID(ctx context.Context, id int64) (*TroubleshootLog, error)
 	Create(ctx context.Context, log TroubleshootLog) (*TroubleshootLog, error)
 	Update(ctx context.Context, log TroubleshootLog) error
 	Delete(ctx context.Context, id int64) error
+	FindTrash(ctx context.Context, page PageRequest) ([]*TroubleshootLog, *PageMeta, error)
+	Restore(ctx context.Context, id int64) error
+	Purge(ctx context.Context, id int64) error
+	Search(ctx context.Context, in SearchTroubleshootLogInput) ([]*TroubleshootLogSearchResult, error)
+	FindRecurrenceCandidates(ctx context.Context, log TroubleshootLog, since time.Time, minSimilarity float64) ([]*TroubleshootLog, error)
+	MarkRecurrence(ctx context.Context, id int64, previousID int64, count int) error
+	CountOpen(ctx context.Context) ([]*OpenTicketCount, error)
+	FindRepeatOffenders(ctx context.Context, filter RepeatOffenderFilter) ([]*RepeatOffender, error)
+	Export(ctx context.Context, filter TroubleshootLogFilter, fn func(row *TroubleshootLogExportRow) error) error
 }
 
 type ITroubleshootLogUsecase interface {
-	FindAll(ctx context.Context, log TroubleshootLog) ([]*TroubleshootLog, error)
+	FindAll(ctx context.Context, filter TroubleshootLogFilter, page PageRequest) ([]*TroubleshootLog, *PageMeta, error)
 	FindByID(ctx context.Context, id int64) (*TroubleshootLog, error)
-	Create(ctx context.Context, log TroubleshootLog) (*TroubleshootLog, error)
-	Update(ctx context.Context, id int64, log TroubleshootLog) error
+	Create(ctx context.Context, in CreateTroubleshootLogInput) (*TroubleshootLog, error)
+	Update(ctx context.Context, id int64, in UpdateTroubleshootLogInput) error
 	Delete(ctx context.Context, id int64) error
+	FindTrash(ctx context.Context, page PageRequest) ([]*TroubleshootLog, *PageMeta, error)
+	Restore(ctx context.Context, id int64) error
+	Purge(ctx context.Context, id int64) error
+	Search(ctx context.Context, in SearchTroubleshootLogInput) ([]*TroubleshootLogSearchResult, error)
+	DetectRecurrence(ctx context.Context, log *TroubleshootLog) error
+	FindRepeatOffenders(ctx context.Context, filter RepeatOffenderFilter) ([]*RepeatOffender, error)
+	Export(ctx context.Context, filter TroubleshootLogFilter, format string, w io.Writer) error
 }
@@ -2,6 +2,7 @@ package model
 
 import (
 	"context"
+	"slices"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -11,61 +12,89 @@ type ContextAuthKey string
 
 const BearerAuthKey ContextAuthKey = "BearerAuth"
 
+// CustomClaims carries the permissions of the user's role at the time the
+// token was issued, so checking a route does not need a database lookup.
+// Requests authenticated with an API key get claims built from the key:
+// APIKeyID is set, UserID is the user who issued it and Permissions are
+// the key's scopes.
 type CustomClaims struct {
-	UserID int64  `json:"user_id"`
-	Role   string `json:"role"`
+	UserID      int64    `json:"user_id"`
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+	APIKeyID    int64    `json:"api_key_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
+func (c *CustomClaims) HasPermission(permission string) bool {
+	return slices.Contains(c.Permissions, permission)
+}
+
 type User struct {
-	Id        int64      `json:"id"`
-	Name      string     `json:"name"`
-	CodeName  string     `json:"code_name"`
-	Username  string     `json:"username"`
-	Password  string     `json:"-"`
-	Role      string     `json:"role"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
-	DeletedAt *time.Time `json:"-"`
+	Id                int64      `json:"id"`
+	Name              string     `json:"name"`
+	CodeName          string     `json:"code_name"`
+	Username          string     `json:"username"`
+	Password          string     `json:"-"`
+	Role              string     `json:"role"`
+	PasswordChangedAt *time.Time `json:"password_changed_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+	DeletedAt         *time.Time `json:"deleted_at,omitempty"`
 }
 
 type IUserRepository interface {
-	FindAll(ctx context.Context, user User) ([]*User, error)
+	FindAll(ctx context.Context, user User, page PageRequest) ([]*User, *PageMeta, error)
 	FindByID(ctx context.Context, id int64) (*User, error)
 	FindByUsername(ctx context.Context, username string) (*User, error)
 	Create(ctx context.Context, user User) (*User, error)
 	Update(ctx context.Context, user User) error
+	UpdatePassword(ctx context.Context, id int64, passwordHash string) error
 	Delete(ctx context.Context, id int64) error
+	FindTrash(ctx context.Context, page PageRequest) ([]*User, *PageMeta, error)
+	Restore(ctx context.Context, id int64) error
+	Purge(ctx context.Context, id int64) error
 	FindByCodeName(ctx context.Context, codeName string) (*User, error)
 }
 
 type IUserUsecase interface {
-	FindAll(ctx context.Context, user User) ([]*User, error)
+	FindAll(ctx context.Context, user User, page PageRequest) ([]*User, *PageMeta, error)
 	FindByID(ctx context.Context, id int64) (*User, error)
-	Login(ctx context.Context, in LoginInput) (token string, err error)
-	Create(ctx context.Context, in CreateUserInput) (token string, err error)
+	Login(ctx context.Context, in LoginInput) (*TokenPair, error)
+	Refresh(ctx context.Context, in RefreshTokenInput) (*TokenPair, error)
+	Logout(ctx context.Context, in LogoutInput) error
+	IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error)
+	Create(ctx context.Context, in CreateUserInput) (*User, error)
 	Update(ctx context.Context, id int64, in UpdateUserInput) error
 	Delete(ctx context.Context, id int64) error
+	FindTrash(ctx context.Context, page PageRequest) ([]*User, *PageMeta, error)
+	Restore(ctx context.Context, id int64) error
+	Purge(ctx context.Context, id int64) error
+	ChangePassword(ctx context.Context, in ChangePasswordInput) error
+	IssuePasswordReset(ctx context.Context, userID int64) (*PasswordReset, error)
+	ResetPassword(ctx context.Context, in ResetPasswordInput) error
+	Unlock(ctx context.Context, id int64) error
 }
 
 type LoginInput struct {
 	Id       int64  `json:"id"`
 	Username string `json:"username" validate:"required"`
 	Password string `json:"password" validate:"required"`
+	IP       string `json:"-"`
 }
 
 type CreateUserInput struct {
 	Name     string `json:"name" validate:"required,max=100"`
 	CodeName string `json:"code_name" validate:"required,max=10"`
 	Username string `json:"username" validate:"required,max=100"`
-	Password string `json:"password" validate:"required,min=3,max=50"`
+	Password string `json:"password" validate:"required,max=72"`
 	Role     string `json:"role" validate:"required"`
 }
 
+// UpdateUserInput edits the profile only; passwords go through
+// ChangePassword or an admin reset.
 type UpdateUserInput struct {
 	Name     string `json:"name" validate:"required,max=100"`
 	CodeName string `json:"code_name" validate:"required,max=10"`
 	Username string `json:"username" validate:"required,min=3,max=100"`
-	Password string `json:"password" validate:"required,min=3,max=50"`
 	Role     string `json:"role" validate:"required"`
 }
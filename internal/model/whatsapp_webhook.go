@@ -1,9 +1,69 @@
 package model
 
+import (
+	"context"
+	"time"
+)
+
 type WhatsAppWebhookRequest struct {
-	Sender        string `json:"sender"`
-	Message       string `json:"message"`
-	QuotedMessage string `json:"quoted_message"`
-	GroupName     string `json:"group_name"`
-	Timestamp     string `json:"timestamp"`
+	Sender        string          `json:"sender"`
+	Message       string          `json:"message"`
+	QuotedMessage string          `json:"quoted_message"`
+	GroupName     string          `json:"group_name"`
+	Timestamp     string          `json:"timestamp"`
+	Media         []WhatsAppMedia `json:"media"`
+}
+
+// WhatsAppMedia is a photo or file sent along with a report. The gateway
+// either gives a URL to download it from or the content as base64.
+type WhatsAppMedia struct {
+	URL      string `json:"url"`
+	Base64   string `json:"base64"`
+	FileName string `json:"file_name"`
+	MimeType string `json:"mime_type"`
+}
+
+// WhatsAppMediaJob fetches one media item of a WhatsApp report and attaches
+// it to the ticket, in the background so the webhook does not wait on the
+// download. FailedAt is set once the job gave up.
+type WhatsAppMediaJob struct {
+	ID                int64 `gorm:"primaryKey"`
+	TroubleshootLogID int64
+	MediaIndex        int
+	URL               string
+	Base64            string
+	FileName          string
+	MimeType          string
+	Attempts          int
+	LastError         string
+	ClaimedAt         *time.Time
+	FailedAt          *time.Time
+	CreatedAt         time.Time
+}
+
+func (WhatsAppMediaJob) TableName() string {
+	return "whatsapp_media_jobs"
+}
+
+// Media returns the media item the job fetches.
+func (j *WhatsAppMediaJob) Media() WhatsAppMedia {
+	return WhatsAppMedia{
+		URL:      j.URL,
+		Base64:   j.Base64,
+		FileName: j.FileName,
+		MimeType: j.MimeType,
+	}
+}
+
+// IWhatsAppMediaJobRepository is the queue of media waiting to be fetched.
+// A job is claimed before it is processed, so only one instance works on
+// it. A claim older than staleBefore is taken over, the instance that held
+// it is assumed gone.
+type IWhatsAppMediaJobRepository interface {
+	Create(ctx context.Context, jobs []*WhatsAppMediaJob) error
+	FindPending(ctx context.Context, limit int) ([]*WhatsAppMediaJob, error)
+	Claim(ctx context.Context, id int64, staleBefore time.Time) (bool, error)
+	Complete(ctx context.Context, id int64) error
+	Retry(ctx context.Context, id int64, reason string) error
+	Fail(ctx context.Context, id int64, reason string) error
 }
@@ -10,7 +10,7 @@ type WorkType struct {
 	Name      string     `json:"name"`
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
-	DeletedAt *time.Time `json:"-"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 type CreateWorkTypeInput struct {
@@ -22,17 +22,23 @@ type UpdateWorkTypeInput struct {
 }
 
 type IWorkTypeRepository interface {
-	FindAll(ctx context.Context, workType WorkType) ([]*WorkType, error)
+	FindAll(ctx context.Context, workType WorkType, page PageRequest) ([]*WorkType, *PageMeta, error)
 	FindByID(ctx context.Context, id int64) (*WorkType, error)
 	Create(ctx context.Context, workType WorkType) (*WorkType, error)
 	Update(ctx context.Context, workType WorkType) error
-	Delete(ctx context.Context, id int64) error
+	Delete(ctx context.Context, id int64, replaceWith *int64) (int64, error)
+	FindTrash(ctx context.Context, page PageRequest) ([]*WorkType, *PageMeta, error)
+	Restore(ctx context.Context, id int64) error
+	Purge(ctx context.Context, id int64) error
 }
 
 type IWorkTypeUsecase interface {
-	FindAll(ctx context.Context, workType WorkType) ([]*WorkType, error)
+	FindAll(ctx context.Context, workType WorkType, page PageRequest) ([]*WorkType, *PageMeta, error)
 	FindByID(ctx context.Context, id int64) (*WorkType, error)
 	Create(ctx context.Context, in CreateWorkTypeInput) (*WorkType, error)
 	Update(ctx context.Context, id int64, in UpdateWorkTypeInput) error
-	Delete(ctx context.Context, id int64) error
+	Delete(ctx context.Context, id int64, replaceWith *int64) error
+	FindTrash(ctx context.Context, page PageRequest) ([]*WorkType, *PageMeta, error)
+	Restore(ctx context.Context, id int64) error
+	Purge(ctx context.Context, id int64) error
 }
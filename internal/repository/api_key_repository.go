@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type APIKeyRepo struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepo(db *gorm.DB) model.IAPIKeyRepository {
+	return &APIKeyRepo{
+		db: db,
+	}
+}
+
+func (r *APIKeyRepo) FindAll(ctx context.Context) ([]*model.APIKey, error) {
+	var keys []*model.APIKey
+
+	err := r.db.WithContext(ctx).Order("created_at DESC, id DESC").Find(&keys).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (r *APIKeyRepo) FindByID(ctx context.Context, id int64) (*model.APIKey, error) {
+	var key model.APIKey
+
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, model.NewNotFoundError("api key not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+func (r *APIKeyRepo) FindByHash(ctx context.Context, hash string) (*model.APIKey, error) {
+	var key model.APIKey
+
+	err := r.db.WithContext(ctx).Where("key_hash = ?", hash).First(&key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+func (r *APIKeyRepo) Create(ctx context.Context, key model.APIKey) (*model.APIKey, error) {
+	if err := r.db.WithContext(ctx).Create(&key).Error; err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+func (r *APIKeyRepo) Revoke(ctx context.Context, id int64) error {
+	now := time.Now()
+
+	return r.db.WithContext(ctx).
+		Model(&model.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"revoked_at": now,
+			"updated_at": now,
+		}).Error
+}
+
+func (r *APIKeyRepo) RevokeByCreator(ctx context.Context, userID int64) error {
+	now := time.Now()
+
+	return r.db.WithContext(ctx).
+		Model(&model.APIKey{}).
+		Where("created_by = ? AND revoked_at IS NULL", userID).
+		Updates(map[string]interface{}{
+			"revoked_at": now,
+			"updated_at": now,
+		}).Error
+}
+
+func (r *APIKeyRepo) TouchLastUsed(ctx context.Context, id int64, interval time.Duration) error {
+	now := time.Now()
+
+	return r.db.WithContext(ctx).
+		Model(&model.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, now.Add(-interval)).
+		UpdateColumn("last_used_at", now).Error
+}
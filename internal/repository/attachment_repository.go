@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type AttachmentRepo struct {
+	db *gorm.DB
+}
+
+func NewAttachmentRepo(db *gorm.DB) model.IAttachmentRepository {
+	return &AttachmentRepo{
+		db: db,
+	}
+}
+
+func (a *AttachmentRepo) FindByTroubleshootLogID(ctx context.Context, logID int64) ([]*model.Attachment, error) {
+	var attachments []*model.Attachment
+
+	err := a.db.WithContext(ctx).
+		Where("troubleshoot_log_id = ? AND deleted_at IS NULL", logID).
+		Order("created_at ASC").
+		Find(&attachments).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+// FindAllByTroubleshootLogID includes soft-deleted attachments, whose blobs
+// are kept until the ticket is purged.
+func (a *AttachmentRepo) FindAllByTroubleshootLogID(ctx context.Context, logID int64) ([]*model.Attachment, error) {
+	var attachments []*model.Attachment
+
+	err := a.db.WithContext(ctx).
+		Where("troubleshoot_log_id = ?", logID).
+		Find(&attachments).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+func (a *AttachmentRepo) FindByID(ctx context.Context, id int64) (*model.Attachment, error) {
+	var attachment model.Attachment
+
+	err := a.db.WithContext(ctx).
+		Where("id = ? AND deleted_at IS NULL", id).
+		First(&attachment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, model.NewNotFoundError("attachment not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &attachment, nil
+}
+
+func (a *AttachmentRepo) Create(ctx context.Context, attachment model.Attachment) (*model.Attachment, error) {
+	attachment.CreatedAt = time.Now()
+	attachment.UpdatedAt = time.Now()
+
+	if err := a.db.WithContext(ctx).Create(&attachment).Error; err != nil {
+		return nil, err
+	}
+
+	return &attachment, nil
+}
+
+func (a *AttachmentRepo) Delete(ctx context.Context, id int64) error {
+	return a.db.WithContext(ctx).
+		Model(&model.Attachment{}).
+		Where("id = ? AND deleted_at IS NULL", id).
+		Update("deleted_at", time.Now()).Error
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tubagusmf/log-troubleshoot-be/db"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+// newTestDB opens a fresh SQLite database with every migration applied.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	t.Cleanup(viper.Reset)
+	viper.Set("database.driver", db.DriverSQLite)
+	viper.Set("database.sqlite.path", filepath.Join(t.TempDir(), "test.db"))
+
+	gormDB := db.NewSQLite()
+
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	return gormDB
+}
+
+func createTestProject(t *testing.T, gormDB *gorm.DB, name string) int64 {
+	t.Helper()
+
+	project := model.Project{Name: name, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	if err := gormDB.Create(&project).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	return project.Id
+}
+
+func createTestTicket(t *testing.T, gormDB *gorm.DB, ticket model.TroubleshootLog) int64 {
+	t.Helper()
+
+	if ticket.TroubleDate.IsZero() {
+		ticket.TroubleDate = time.Now()
+		ticket.TroubleTime = ticket.TroubleDate
+	}
+	ticket.CreatedAt = time.Now()
+	ticket.UpdatedAt = time.Now()
+
+	created, err := NewTroubleshootLogRepo(gormDB).Create(context.Background(), ticket)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return created.ID
+}
+
+func withScope(projectIDs ...int64) context.Context {
+	return context.WithValue(context.Background(), model.ProjectScopeKey, &model.ProjectScope{ProjectIDs: projectIDs})
+}
+
+func assertErrorKind(t *testing.T, err error, kind error) {
+	t.Helper()
+
+	if !errors.Is(err, kind) {
+		t.Fatalf("error = %v, want %v", err, kind)
+	}
+}
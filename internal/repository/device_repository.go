@@ -19,9 +19,9 @@ func NewDeviceRepo(db *gorm.DB) model.IDeviceRepository {
 	}
 }
 
-func (d *DeviceRepo) FindAll(ctx context.Context, device model.Device) ([]*model.Device, error) {
-	var devices []*model.Device
+var deviceSortColumns = []string{"id", "name", "created_at", "updated_at"}
 
+func (d *DeviceRepo) FindAll(ctx context.Context, device model.Device, page model.PageRequest) ([]*model.Device, *model.PageMeta, error) {
 	query := d.db.WithContext(ctx).
 		Model(&model.Device{}).
 		Where("deleted_at IS NULL")
@@ -30,20 +30,15 @@ func (d *DeviceRepo) FindAll(ctx context.Context, device model.Device) ([]*model
 		query = query.Where("name LIKE ?", "%"+device.Name+"%")
 	}
 
-	err := query.Find(&devices).Error
-	if err != nil {
-		return nil, err
-	}
-
-	return devices, nil
+	return paginate(query, "devices", page, deviceSortColumns, func(d *model.Device) int64 { return d.Id })
 }
 
 func (d *DeviceRepo) FindByID(ctx context.Context, id int64) (*model.Device, error) {
 	var device model.Device
 
-	err := d.db.WithContext(ctx).First(&device, id).Error
+	err := d.db.WithContext(ctx).Where("deleted_at IS NULL").First(&device, id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, errors.New("device not found")
+		return nil, model.NewNotFoundError("device not found")
 	}
 	if err != nil {
 		return nil, err
@@ -58,7 +53,7 @@ func (d *DeviceRepo) Create(ctx context.Context, device model.Device) (*model.De
 
 	err := d.db.WithContext(ctx).Create(&device).Error
 	if err != nil {
-		return nil, err
+		return nil, translateError(err, "device")
 	}
 
 	return &device, nil
@@ -72,17 +67,26 @@ func (d *DeviceRepo) Update(ctx context.Context, device model.Device) error {
 		Where("id = ? AND deleted_at IS NULL", device.Id).
 		Updates(&device).Error
 	if err != nil {
-		return err
+		return translateError(err, "device")
 	}
 
 	return nil
 }
 
-func (d *DeviceRepo) Delete(ctx context.Context, id int64) error {
-	err := d.db.WithContext(ctx).Model(&model.Device{}).Where("id = ? AND deleted_at IS NULL", id).Update("deleted_at", time.Now()).Error
-	if err != nil {
-		return err
-	}
+// Delete moves the open tickets of the device to replaceWith, when given,
+// and returns how many were moved. See deleteMasterData.
+func (d *DeviceRepo) Delete(ctx context.Context, id int64, replaceWith *int64) (int64, error) {
+	return deleteMasterData(d.db.WithContext(ctx), &model.Device{}, "device", model.TicketColumnDevice, id, replaceWith)
+}
 
-	return nil
+func (d *DeviceRepo) FindTrash(ctx context.Context, page model.PageRequest) ([]*model.Device, *model.PageMeta, error) {
+	return findTrash(d.db.WithContext(ctx), "devices", page, deviceSortColumns, func(row *model.Device) int64 { return row.Id })
+}
+
+func (d *DeviceRepo) Restore(ctx context.Context, id int64) error {
+	return restoreDeleted(d.db.WithContext(ctx), &model.Device{}, id, "device")
+}
+
+func (d *DeviceRepo) Purge(ctx context.Context, id int64) error {
+	return purgeDeleted(d.db.WithContext(ctx), &model.Device{}, id, "device")
 }
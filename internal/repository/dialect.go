@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// The repositories run against Postgres or SQLite. Most queries are shared,
+// the helpers below cover where the two dialects differ.
+
+func isSQLite(db *gorm.DB) bool {
+	return db.Dialector.Name() == "sqlite"
+}
+
+// ilike returns a case-insensitive LIKE condition on column. SQLite's LIKE
+// already ignores case for ASCII.
+func ilike(db *gorm.DB, column string) string {
+	if isSQLite(db) {
+		return column + " LIKE ?"
+	}
+	return column + " ILIKE ?"
+}
+
+// sqliteTimestamp combines a DATE and a TIME column into the wall-clock
+// timestamp Postgres gets from date + time. SQLite stores both as
+// "2006-01-02 15:04:05-07:00" text, the offset is left out as in Postgres.
+func sqliteTimestamp(date, clock string) string {
+	return "datetime(substr(" + date + ", 1, 10) || ' ' || substr(" + clock + ", 12, 8))"
+}
+
+// sqliteWallClock is sqliteTimestamp for a single time argument.
+func sqliteWallClock(value string) string {
+	return "datetime(substr(" + value + ", 1, 19))"
+}
+
+// scanTime is a time.Time that also scans from text. SQLite returns
+// computed timestamps, such as MIN(trouble_date), as text because they have
+// no declared column type for the driver to parse them by.
+type scanTime struct {
+	time.Time
+}
+
+var scanTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+}
+
+func (t scanTime) Value() (driver.Value, error) {
+	return t.Time, nil
+}
+
+func (t *scanTime) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		t.Time = time.Time{}
+		return nil
+	case time.Time:
+		t.Time = v
+		return nil
+	case []byte:
+		return t.Scan(string(v))
+	case string:
+		for _, layout := range scanTimeLayouts {
+			if parsed, err := time.Parse(layout, v); err == nil {
+				t.Time = parsed
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid timestamp %q", v)
+	default:
+		return fmt.Errorf("cannot scan %T into a timestamp", value)
+	}
+}
+
+// trigramSimilarity is pg_trgm's similarity() for databases without the
+// extension: the share of distinct trigrams the two texts have in common.
+// Words are lower-cased and padded with two spaces in front and one behind.
+func trigramSimilarity(a, b string) float64 {
+	left, right := trigrams(a), trigrams(b)
+	if len(left) == 0 || len(right) == 0 {
+		return 0
+	}
+
+	common := 0
+	for trigram := range left {
+		if _, ok := right[trigram]; ok {
+			common++
+		}
+	}
+
+	return float64(common) / float64(len(left)+len(right)-common)
+}
+
+func trigrams(text string) map[string]struct{} {
+	set := map[string]struct{}{}
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = struct{}{}
+		}
+	}
+
+	return set
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+	"gorm.io/gorm"
+)
+
+// translateError turns constraint violations reported by the database into
+// domain errors. The connection must be opened with TranslateError.
+func translateError(err error, entity string) error {
+	switch {
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return model.NewConflictError("%s already exists", entity)
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return model.NewValidationError("%s refers to a record that does not exist", entity)
+	default:
+		return err
+	}
+}
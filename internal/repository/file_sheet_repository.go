@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+)
+
+// FileSheetRepository appends the spreadsheet rows to a file as tab
+// separated lines instead of a Google spreadsheet, for local development and
+// testing.
+type FileSheetRepository struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileSheetRepository(path string) (model.ISpreadsheetRepository, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	return &FileSheetRepository{path: path}, nil
+}
+
+func (r *FileSheetRepository) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (r *FileSheetRepository) AppendTroubleshoot(ctx context.Context, log *model.TroubleshootLog) (int, error) {
+	cells := make([]string, 0, 7)
+	for _, value := range sheetRow(log) {
+		// Keep one row per line whatever the issue text contains.
+		cells = append(cells, strings.NewReplacer("\t", " ", "\n", " ", "\r", " ").Replace(fmt.Sprint(value)))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	if _, err := fmt.Fprintln(file, strings.Join(cells, "\t")); err != nil {
+		return 0, err
+	}
+
+	return 0, nil
+}
@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
 
@@ -39,32 +41,64 @@ func NewGoogleSheetRepository(
 	}, nil
 }
 
+// Ping checks the spreadsheet can be read with the configured credentials.
+func (r *GoogleSheetRepository) Ping(ctx context.Context) error {
+	_, err := r.service.Spreadsheets.Get(r.spreadsheetID).
+		Fields("spreadsheetId").
+		Context(ctx).
+		Do()
+
+	return err
+}
+
 func (r *GoogleSheetRepository) AppendTroubleshoot(
 	ctx context.Context,
 	log *model.TroubleshootLog,
-) error {
-
-	values := [][]interface{}{
-		{
-			log.TroubleDate.Format("2006-01-02"),
-			log.TroubleTime.Format("15:04:05"),
-			log.Part,
-			log.DeviceNumber,
-			log.Issue,
-			log.Status,
-			log.WhatsappSender,
-		},
-	}
+) (int, error) {
 
 	valueRange := &sheets.ValueRange{
-		Values: values,
+		Values: [][]interface{}{sheetRow(log)},
 	}
 
-	_, err := r.service.Spreadsheets.Values.Append(
+	resp, err := r.service.Spreadsheets.Values.Append(
 		r.spreadsheetID,
 		fmt.Sprintf("%s!A:G", r.sheetName),
 		valueRange,
-	).ValueInputOption("RAW").Do()
+	).ValueInputOption("RAW").Context(ctx).Do()
+	if err != nil {
+		return 0, err
+	}
 
-	return err
+	if resp.Updates == nil {
+		return 0, nil
+	}
+
+	return rangeRow(resp.Updates.UpdatedRange), nil
+}
+
+// sheetRow is the row of a ticket, columns A to G.
+func sheetRow(log *model.TroubleshootLog) []interface{} {
+	return []interface{}{
+		log.TroubleDate.Format("2006-01-02"),
+		log.TroubleTime.Format("15:04:05"),
+		log.Part,
+		log.DeviceNumber,
+		log.Issue,
+		log.Status,
+		log.WhatsappSender,
+	}
+}
+
+// rangeRow returns the first row of an A1 range such as "Sheet1!A12:G12",
+// 0 when it has none.
+func rangeRow(a1 string) int {
+	cells := a1[strings.LastIndex(a1, "!")+1:]
+	cells, _, _ = strings.Cut(cells, ":")
+
+	row, err := strconv.Atoi(strings.TrimLeft(cells, "ABCDEFGHIJKLMNOPQRSTUVWXYZ$"))
+	if err != nil {
+		return 0
+	}
+
+	return row
 }
@@ -0,0 +1,25 @@
+package repository
+
+import "testing"
+
+func TestRangeRow(t *testing.T) {
+	tests := []struct {
+		a1   string
+		want int
+	}{
+		{a1: "Sheet1!A12:G12", want: 12},
+		{a1: "'Log Harian'!A7:G7", want: 7},
+		{a1: "Sheet1!$A$3", want: 3},
+		{a1: "A5:G5", want: 5},
+		{a1: "Sheet1!A:G", want: 0},
+		{a1: "", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a1, func(t *testing.T) {
+			if got := rangeRow(tt.a1); got != tt.want {
+				t.Errorf("rangeRow(%q) = %d, want %d", tt.a1, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type ImportRepo struct {
+	db *gorm.DB
+}
+
+func NewImportRepo(db *gorm.DB) model.IImportRepository {
+	return &ImportRepo{
+		db: db,
+	}
+}
+
+func (r *ImportRepo) UpsertProjects(ctx context.Context, items []model.ImportItem[model.ImportProjectRow], rollback bool) ([]*model.ImportRowResult, error) {
+	return upsertItems(ctx, r.db, items, rollback,
+		func(row *model.ImportProjectRow) string { return row.Name },
+		func(tx *gorm.DB, row *model.ImportProjectRow) (string, error) {
+			return upsertByName(tx, &model.Project{}, "project", row.Name)
+		})
+}
+
+func (r *ImportRepo) UpsertDevices(ctx context.Context, items []model.ImportItem[model.ImportDeviceRow], rollback bool) ([]*model.ImportRowResult, error) {
+	return upsertItems(ctx, r.db, items, rollback,
+		func(row *model.ImportDeviceRow) string { return row.Name },
+		func(tx *gorm.DB, row *model.ImportDeviceRow) (string, error) {
+			return upsertByName(tx, &model.Device{}, "device", row.Name)
+		})
+}
+
+func (r *ImportRepo) UpsertWorkTypes(ctx context.Context, items []model.ImportItem[model.ImportWorkTypeRow], rollback bool) ([]*model.ImportRowResult, error) {
+	return upsertItems(ctx, r.db, items, rollback,
+		func(row *model.ImportWorkTypeRow) string { return row.Name },
+		func(tx *gorm.DB, row *model.ImportWorkTypeRow) (string, error) {
+			return upsertByName(tx, &model.WorkType{}, "work type", row.Name)
+		})
+}
+
+func (r *ImportRepo) UpsertLocations(ctx context.Context, items []model.ImportItem[model.ImportLocationRow], rollback bool) ([]*model.ImportRowResult, error) {
+	return upsertItems(ctx, r.db, items, rollback,
+		func(row *model.ImportLocationRow) string { return row.CodeName },
+		func(tx *gorm.DB, row *model.ImportLocationRow) (string, error) {
+			var existing model.Location
+			err := tx.Where("code_name = ? AND deleted_at IS NULL", row.CodeName).Take(&existing).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				err = tx.Create(&model.Location{
+					Name:      row.Name,
+					CodeName:  row.CodeName,
+					CreatedAt: time.Now(),
+					UpdatedAt: time.Now(),
+				}).Error
+				return model.ImportActionCreate, translateError(err, "location")
+			}
+			if err != nil {
+				return "", err
+			}
+
+			if existing.Name == row.Name {
+				return model.ImportActionUnchanged, nil
+			}
+
+			err = tx.Model(&existing).Updates(map[string]interface{}{
+				"name":       row.Name,
+				"updated_at": time.Now(),
+			}).Error
+			return model.ImportActionUpdate, translateError(err, "location")
+		})
+}
+
+// UpsertUsers expects Password to hold a hash already.
+func (r *ImportRepo) UpsertUsers(ctx context.Context, items []model.ImportItem[model.ImportUserRow], rollback bool) ([]*model.ImportRowResult, error) {
+	return upsertItems(ctx, r.db, items, rollback,
+		func(row *model.ImportUserRow) string { return row.CodeName },
+		func(tx *gorm.DB, row *model.ImportUserRow) (string, error) {
+			var existing model.User
+			err := tx.Where("code_name = ? AND deleted_at IS NULL", row.CodeName).Take(&existing).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				if row.Password == "" {
+					return "", model.NewFieldError("password", "required", "password is required for new users")
+				}
+
+				err = tx.Create(&model.User{
+					Name:      row.Name,
+					CodeName:  row.CodeName,
+					Username:  row.Username,
+					Password:  row.Password,
+					Role:      row.Role,
+					CreatedAt: time.Now(),
+					UpdatedAt: time.Now(),
+				}).Error
+				return model.ImportActionCreate, translateError(err, "user")
+			}
+			if err != nil {
+				return "", err
+			}
+
+			changes := map[string]interface{}{}
+			if existing.Name != row.Name {
+				changes["name"] = row.Name
+			}
+			if existing.Username != row.Username {
+				changes["username"] = row.Username
+			}
+			if existing.Role != row.Role {
+				changes["role"] = row.Role
+			}
+			if row.Password != "" {
+				return "", model.NewFieldError("password", "excluded", "the password of an existing user cannot be changed by import")
+			}
+			if len(changes) == 0 {
+				return model.ImportActionUnchanged, nil
+			}
+
+			changes["updated_at"] = time.Now()
+			if err := tx.Model(&existing).Updates(changes).Error; err != nil {
+				return "", translateError(err, "user")
+			}
+
+			// Sessions carry the permissions of the old role, end them.
+			if _, ok := changes["role"]; ok {
+				err = tx.Model(&model.RefreshToken{}).
+					Where("user_id = ? AND revoked_at IS NULL", existing.Id).
+					Update("revoked_at", time.Now()).Error
+			}
+			return model.ImportActionUpdate, err
+		})
+}
+
+// upsertItems runs upsert for every item inside one transaction. Rows that
+// fail with a domain error are rolled back to their savepoint and reported,
+// any other error aborts the import.
+func upsertItems[T any](
+	ctx context.Context,
+	db *gorm.DB,
+	items []model.ImportItem[T],
+	rollback bool,
+	keyOf func(row *T) string,
+	upsert func(tx *gorm.DB, row *T) (string, error),
+) ([]*model.ImportRowResult, error) {
+	tx := db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	defer tx.Rollback()
+
+	results := make([]*model.ImportRowResult, 0, len(items))
+	failed := false
+
+	for i := range items {
+		item := &items[i]
+
+		if err := tx.SavePoint("import_row").Error; err != nil {
+			return nil, err
+		}
+
+		action, err := upsert(tx, &item.Value)
+		result := &model.ImportRowResult{
+			Row:    item.Row,
+			Key:    keyOf(&item.Value),
+			Action: action,
+		}
+
+		if err != nil {
+			var domainErr *model.DomainError
+			if !errors.As(err, &domainErr) {
+				return nil, err
+			}
+
+			if err := tx.RollbackTo("import_row").Error; err != nil {
+				return nil, err
+			}
+
+			failed = true
+			result.Action = model.ImportActionError
+			result.Errors = model.RowErrors(err)
+		}
+
+		results = append(results, result)
+	}
+
+	if rollback || failed {
+		return results, tx.Rollback().Error
+	}
+
+	return results, tx.Commit().Error
+}
+
+// upsertByName imports master data that is identified by its name alone.
+// Names match case-insensitively, a row that only differs in case renames
+// the record.
+func upsertByName(tx *gorm.DB, value interface{}, entity string, name string) (string, error) {
+	var existing struct {
+		ID   int64
+		Name string
+	}
+
+	err := tx.Model(value).
+		Select("id", "name").
+		Where("LOWER(name) = LOWER(?) AND deleted_at IS NULL", name).
+		Take(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		now := time.Now()
+		err = tx.Model(value).Create(map[string]interface{}{
+			"name":       name,
+			"created_at": now,
+			"updated_at": now,
+		}).Error
+		return model.ImportActionCreate, translateError(err, entity)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if existing.Name == name {
+		return model.ImportActionUnchanged, nil
+	}
+
+	err = tx.Model(value).
+		Where("id = ?", existing.ID).
+		Updates(map[string]interface{}{
+			"name":       name,
+			"updated_at": time.Now(),
+		}).Error
+	return model.ImportActionUpdate, translateError(err, entity)
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+)
+
+func projectItems(names ...string) []model.ImportItem[model.ImportProjectRow] {
+	items := make([]model.ImportItem[model.ImportProjectRow], len(names))
+	for i, name := range names {
+		items[i] = model.ImportItem[model.ImportProjectRow]{Row: i + 2, Value: model.ImportProjectRow{Name: name}}
+	}
+	return items
+}
+
+func assertActions(t *testing.T, results []*model.ImportRowResult, want ...string) {
+	t.Helper()
+
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+	for i, result := range results {
+		if result.Action != want[i] {
+			t.Errorf("row %d (%s): action %q, want %q", result.Row, result.Key, result.Action, want[i])
+		}
+	}
+}
+
+func TestImportUpsertProjects(t *testing.T) {
+	gormDB := newTestDB(t)
+	repo := NewImportRepo(gormDB)
+	ctx := context.Background()
+
+	projectNames := func() []string {
+		t.Helper()
+
+		var names []string
+		if err := gormDB.Model(&model.Project{}).Order("id").Pluck("name", &names).Error; err != nil {
+			t.Fatal(err)
+		}
+		return names
+	}
+
+	results, err := repo.UpsertProjects(ctx, projectItems("Proj A", "Proj B"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertActions(t, results, model.ImportActionCreate, model.ImportActionCreate)
+
+	// A dry run reports what would happen and changes nothing.
+	results, err = repo.UpsertProjects(ctx, projectItems("PROJ A", "Proj B", "Proj C"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertActions(t, results, model.ImportActionUpdate, model.ImportActionUnchanged, model.ImportActionCreate)
+	if names := projectNames(); len(names) != 2 || names[0] != "Proj A" {
+		t.Fatalf("after dry run projects = %v, want [Proj A Proj B]", names)
+	}
+
+	results, err = repo.UpsertProjects(ctx, projectItems("PROJ A", "Proj B", "Proj C"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertActions(t, results, model.ImportActionUpdate, model.ImportActionUnchanged, model.ImportActionCreate)
+	if names := projectNames(); len(names) != 3 || names[0] != "PROJ A" || names[2] != "Proj C" {
+		t.Fatalf("projects = %v, want [PROJ A Proj B Proj C]", names)
+	}
+}
+
+func TestImportUpsertLocationsKeyedByCodeName(t *testing.T) {
+	gormDB := newTestDB(t)
+	repo := NewImportRepo(gormDB)
+	ctx := context.Background()
+
+	items := []model.ImportItem[model.ImportLocationRow]{
+		{Row: 2, Value: model.ImportLocationRow{Name: "Station One", CodeName: "ST1"}},
+		{Row: 3, Value: model.ImportLocationRow{Name: "Station One Renamed", CodeName: "ST1"}},
+		{Row: 4, Value: model.ImportLocationRow{Name: "Station One Renamed", CodeName: "ST1"}},
+	}
+
+	results, err := repo.UpsertLocations(ctx, items, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertActions(t, results, model.ImportActionCreate, model.ImportActionUpdate, model.ImportActionUnchanged)
+
+	location, err := NewLocationRepo(gormDB).FindByName(ctx, "Station One Renamed")
+	if err != nil || location.CodeName != "ST1" {
+		t.Fatalf("FindByName() = %+v, %v", location, err)
+	}
+}
+
+func TestImportUpsertRollsBackOnRowError(t *testing.T) {
+	gormDB := newTestDB(t)
+	repo := NewImportRepo(gormDB)
+	ctx := context.Background()
+
+	items := []model.ImportItem[model.ImportUserRow]{
+		{Row: 2, Value: model.ImportUserRow{Name: "Tech One", CodeName: "TS01", Username: "tech1", Password: "hash", Role: "it_support"}},
+		{Row: 3, Value: model.ImportUserRow{Name: "Tech Two", CodeName: "TS02", Username: "tech2", Role: "it_support"}},
+		{Row: 4, Value: model.ImportUserRow{Name: "Tech Three", CodeName: "TS03", Username: "tech3", Password: "hash", Role: "no_such_role"}},
+	}
+
+	results, err := repo.UpsertUsers(ctx, items, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertActions(t, results, model.ImportActionCreate, model.ImportActionError, model.ImportActionError)
+
+	if errs := results[1].Errors; len(errs) != 1 || errs[0].Field != "password" || errs[0].Rule != "required" {
+		t.Errorf("row 3 errors = %+v, want password/required", errs)
+	}
+
+	var count int64
+	if err := gormDB.Model(&model.User{}).Where("code_name IN ?", []string{"TS01", "TS02", "TS03"}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Fatalf("%d imported users were kept, want the import rolled back", count)
+	}
+}
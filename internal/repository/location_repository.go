@@ -19,35 +19,30 @@ func NewLocationRepo(db *gorm.DB) model.ILocationRepository {
 	}
 }
 
-func (l *LocationRepo) FindAll(ctx context.Context, location model.Location) ([]*model.Location, error) {
-	var locations []*model.Location
+var locationSortColumns = []string{"id", "name", "code_name", "created_at", "updated_at"}
 
+func (l *LocationRepo) FindAll(ctx context.Context, location model.Location, page model.PageRequest) ([]*model.Location, *model.PageMeta, error) {
 	query := l.db.WithContext(ctx).
 		Model(&model.Location{}).
 		Where("deleted_at IS NULL")
 
 	if location.Name != "" {
-		query = query.Where("name ILIKE ?", "%"+location.Name+"%")
+		query = query.Where(ilike(query, "name"), "%"+location.Name+"%")
 	}
 
 	if location.CodeName != "" {
-		query = query.Where("code_name ILIKE ?", "%"+location.CodeName+"%")
+		query = query.Where(ilike(query, "code_name"), "%"+location.CodeName+"%")
 	}
 
-	err := query.Find(&locations).Error
-	if err != nil {
-		return nil, err
-	}
-
-	return locations, nil
+	return paginate(query, "locations", page, locationSortColumns, func(l *model.Location) int64 { return l.Id })
 }
 
 func (l *LocationRepo) FindByID(ctx context.Context, id int64) (*model.Location, error) {
 	var location model.Location
 
-	err := l.db.WithContext(ctx).First(&location, id).Error
+	err := l.db.WithContext(ctx).Where("deleted_at IS NULL").First(&location, id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, errors.New("location not found")
+		return nil, model.NewNotFoundError("location not found")
 	}
 	if err != nil {
 		return nil, err
@@ -62,7 +57,7 @@ func (l *LocationRepo) Create(ctx context.Context, location model.Location) (*mo
 
 	err := l.db.WithContext(ctx).Create(&location).Error
 	if err != nil {
-		return nil, err
+		return nil, translateError(err, "location")
 	}
 
 	return &location, nil
@@ -76,22 +71,16 @@ func (l *LocationRepo) Update(ctx context.Context, location model.Location) erro
 		Where("id = ? AND deleted_at IS NULL", location.Id).
 		Updates(&location).Error
 	if err != nil {
-		return err
+		return translateError(err, "location")
 	}
 
 	return nil
 }
 
-func (l *LocationRepo) Delete(ctx context.Context, id int64) error {
-	err := l.db.WithContext(ctx).
-		Model(&model.Location{}).
-		Where("id = ?", id).
-		Update("deleted_at", time.Now()).Error
-	if err != nil {
-		return err
-	}
-
-	return nil
+// Delete moves the open tickets of the location to replaceWith, when given,
+// and returns how many were moved. See deleteMasterData.
+func (l *LocationRepo) Delete(ctx context.Context, id int64, replaceWith *int64) (int64, error) {
+	return deleteMasterData(l.db.WithContext(ctx), &model.Location{}, "location", model.TicketColumnLocation, id, replaceWith)
 }
 
 func (r *LocationRepo) FindByName(ctx context.Context, name string) (*model.Location, error) {
@@ -102,8 +91,20 @@ func (r *LocationRepo) FindByName(ctx context.Context, name string) (*model.Loca
 		First(&location).Error
 
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, errors.New("location not found")
+		return nil, model.NewNotFoundError("location not found")
 	}
 
 	return &location, err
 }
+
+func (l *LocationRepo) FindTrash(ctx context.Context, page model.PageRequest) ([]*model.Location, *model.PageMeta, error) {
+	return findTrash(l.db.WithContext(ctx), "locations", page, locationSortColumns, func(row *model.Location) int64 { return row.Id })
+}
+
+func (l *LocationRepo) Restore(ctx context.Context, id int64) error {
+	return restoreDeleted(l.db.WithContext(ctx), &model.Location{}, id, "location")
+}
+
+func (l *LocationRepo) Purge(ctx context.Context, id int64) error {
+	return purgeDeleted(l.db.WithContext(ctx), &model.Location{}, id, "location")
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type LoginAttemptRepo struct {
+	db *gorm.DB
+}
+
+func NewLoginAttemptRepo(db *gorm.DB) model.ILoginAttemptRepository {
+	return &LoginAttemptRepo{
+		db: db,
+	}
+}
+
+func (r *LoginAttemptRepo) Find(ctx context.Context, scope, key string) (*model.LoginAttempt, error) {
+	var attempt model.LoginAttempt
+
+	err := r.db.WithContext(ctx).
+		Where("scope = ? AND key = ?", scope, key).
+		First(&attempt).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &attempt, nil
+}
+
+func (r *LoginAttemptRepo) RecordFailure(ctx context.Context, scope, key string, resetBefore time.Time) (*model.LoginAttempt, error) {
+	var attempt model.LoginAttempt
+
+	// A single upsert keeps concurrent failures from losing counts.
+	err := r.db.WithContext(ctx).Raw(`
+		INSERT INTO login_attempts (scope, key, failures, last_failure_at)
+		VALUES (?, ?, 1, ?)
+		ON CONFLICT (scope, key) DO UPDATE SET
+			failures = CASE
+				WHEN login_attempts.last_failure_at < ? THEN 1
+				ELSE login_attempts.failures + 1
+			END,
+			locked_until = CASE
+				WHEN login_attempts.last_failure_at < ? THEN NULL
+				ELSE login_attempts.locked_until
+			END,
+			last_failure_at = EXCLUDED.last_failure_at
+		RETURNING scope, key, failures, last_failure_at, locked_until`,
+		scope, key, time.Now(), resetBefore, resetBefore,
+	).Scan(&attempt).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &attempt, nil
+}
+
+func (r *LoginAttemptRepo) Lock(ctx context.Context, scope, key string, until time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&model.LoginAttempt{}).
+		Where("scope = ? AND key = ?", scope, key).
+		Update("locked_until", until).Error
+}
+
+func (r *LoginAttemptRepo) Reset(ctx context.Context, scope, key string) error {
+	return r.db.WithContext(ctx).
+		Where("scope = ? AND key = ?", scope, key).
+		Delete(&model.LoginAttempt{}).Error
+}
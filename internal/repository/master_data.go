@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// deleteMasterData soft-deletes a master-data row that tickets refer to
+// through column. While open tickets still use the row the delete is refused
+// and the blocking tickets are listed, with replaceWith they are moved over
+// to that row first. Closed tickets keep pointing at the deleted row. It
+// returns how many tickets were moved.
+//
+// Everything runs in one transaction. The row is locked for update, so a
+// concurrent delete waits, and so do ticket writes that refer to it through
+// their foreign key, the open tickets are only read once they are done. The
+// replacement is share-locked so it cannot be deleted while tickets are
+// moved to it. SQLite has no row locks but runs one writing transaction at
+// a time.
+func deleteMasterData(
+	query *gorm.DB,
+	value interface{},
+	entity string,
+	column string,
+	id int64,
+	replaceWith *int64,
+) (int64, error) {
+	if !slices.Contains(ticketReferenceColumns, column) {
+		return 0, fmt.Errorf("unknown ticket reference column %q", column)
+	}
+
+	if replaceWith != nil && *replaceWith == id {
+		return 0, model.NewFieldError("replace_with", "ne", fmt.Sprintf("replacement must be another %s", entity))
+	}
+
+	var moved int64
+
+	err := query.Transaction(func(tx *gorm.DB) error {
+		if err := lockLive(tx, value, id, "UPDATE"); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return model.NewNotFoundError("%s not found", entity)
+			}
+			return err
+		}
+
+		openTickets := func() *gorm.DB {
+			return tx.Model(&model.TroubleshootLog{}).
+				Where(column+" = ? AND done_date IS NULL AND deleted_at IS NULL", id)
+		}
+
+		if replaceWith == nil {
+			var refs []*model.TicketReference
+			err := openTickets().
+				Select("id", "ticket_number", "status").
+				Order("id ASC").
+				Find(&refs).Error
+			if err != nil {
+				return err
+			}
+
+			if len(refs) > 0 {
+				return &model.DomainError{
+					Kind:    model.ErrConflict,
+					Message: fmt.Sprintf("%s is still used by %d open tickets, reassign them with replace_with", entity, len(refs)),
+					Details: refs,
+				}
+			}
+		} else {
+			if err := lockLive(tx, value, *replaceWith, "SHARE"); err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return model.NewFieldError("replace_with", "exists", fmt.Sprintf("replacement %s does not exist", entity))
+				}
+				return err
+			}
+
+			res := openTickets().Updates(map[string]interface{}{
+				column:       *replaceWith,
+				"updated_at": time.Now(),
+			})
+			if res.Error != nil {
+				return translateError(res.Error, "troubleshoot log")
+			}
+			moved = res.RowsAffected
+		}
+
+		return tx.Model(value).
+			Where("id = ?", id).
+			Update("deleted_at", time.Now()).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return moved, nil
+}
+
+// lockLive locks a row that is not soft-deleted with the given strength,
+// UPDATE or SHARE, and fails with gorm.ErrRecordNotFound without one.
+func lockLive(tx *gorm.DB, value interface{}, id int64, strength string) error {
+	var row struct{ ID int64 }
+
+	return tx.Model(value).
+		Clauses(clause.Locking{Strength: strength}).
+		Select("id").
+		Where("id = ? AND deleted_at IS NULL", id).
+		Take(&row).Error
+}
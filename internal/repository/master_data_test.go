@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+)
+
+func TestDeleteMasterData(t *testing.T) {
+	gormDB := newTestDB(t)
+	repo := NewLocationRepo(gormDB)
+	tickets := NewTroubleshootLogRepo(gormDB)
+	ctx := context.Background()
+
+	create := func(name string, codeName string) int64 {
+		t.Helper()
+
+		location, err := repo.Create(ctx, model.Location{Name: name, CodeName: codeName})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return location.Id
+	}
+
+	old := create("Station One", "ST1")
+	replacement := create("Station Two", "ST2")
+	gone := create("Station Three", "ST3")
+	if _, err := repo.Delete(ctx, gone, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	closed := time.Now()
+	openTicket := createTestTicket(t, gormDB, model.TroubleshootLog{LocationID: &old, Status: model.TicketStatusOpen})
+	closedTicket := createTestTicket(t, gormDB, model.TroubleshootLog{LocationID: &old, DoneDate: &closed})
+
+	t.Run("open tickets block the delete", func(t *testing.T) {
+		_, err := repo.Delete(ctx, old, nil)
+
+		var domainErr *model.DomainError
+		if !errors.As(err, &domainErr) || domainErr.Kind != model.ErrConflict {
+			t.Fatalf("Delete() error = %v, want a conflict", err)
+		}
+		refs, ok := domainErr.Details.([]*model.TicketReference)
+		if !ok || len(refs) != 1 || refs[0].ID != openTicket {
+			t.Fatalf("Details = %v, want ticket %d", domainErr.Details, openTicket)
+		}
+
+		if _, err := repo.FindByID(ctx, old); err != nil {
+			t.Fatalf("location was deleted: %v", err)
+		}
+	})
+
+	t.Run("replacement must be live and another row", func(t *testing.T) {
+		for _, replaceWith := range []int64{old, gone, 999} {
+			_, err := repo.Delete(ctx, old, &replaceWith)
+			assertErrorKind(t, err, model.ErrValidation)
+		}
+	})
+
+	t.Run("missing rows", func(t *testing.T) {
+		for _, id := range []int64{gone, 999} {
+			_, err := repo.Delete(ctx, id, nil)
+			assertErrorKind(t, err, model.ErrNotFound)
+		}
+	})
+
+	t.Run("reassign and delete", func(t *testing.T) {
+		moved, err := repo.Delete(ctx, old, &replacement)
+		if err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if moved != 1 {
+			t.Fatalf("Delete() moved %d tickets, want 1", moved)
+		}
+
+		for id, want := range map[int64]int64{openTicket: replacement, closedTicket: old} {
+			ticket, err := tickets.FindByID(model.WithGlobalScope(ctx), id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *ticket.LocationID != want {
+				t.Fatalf("ticket %d location = %d, want %d", id, *ticket.LocationID, want)
+			}
+		}
+
+		_, err = repo.FindByID(ctx, old)
+		assertErrorKind(t, err, model.ErrNotFound)
+	})
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+)
+
+// FileMessageSender appends outgoing messages to a file instead of sending
+// them, for local development and testing.
+type FileMessageSender struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileMessageSender(path string) (model.IMessageSender, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	return &FileMessageSender{path: path}, nil
+}
+
+func (s *FileMessageSender) Send(ctx context.Context, target string, message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = fmt.Fprintf(file, "=== %s to %s\n%s\n\n", time.Now().Format(time.RFC3339), target, message)
+	return err
+}
+
+// HTTPMessageSender posts messages to a WhatsApp gateway as
+// {"target": "...", "message": "..."}.
+type HTTPMessageSender struct {
+	client *http.Client
+	url    string
+	token  string
+}
+
+func NewHTTPMessageSender(url string, token string) model.IMessageSender {
+	return &HTTPMessageSender{
+		client: &http.Client{Timeout: 30 * time.Second},
+		url:    url,
+		token:  token,
+	}
+}
+
+func (s *HTTPMessageSender) Send(ctx context.Context, target string, message string) error {
+	body, err := json.Marshal(map[string]string{
+		"target":  target,
+		"message": message,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("message gateway responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// paginate counts the filtered query and fetches one page of it, sorted by
+// one of the sortable columns, ascending unless asked otherwise.
+func paginate[T any](
+	query *gorm.DB,
+	table string,
+	page model.PageRequest,
+	sortable []string,
+	idOf func(*T) int64,
+) ([]*T, *model.PageMeta, error) {
+
+	sortBy := "id"
+	if slices.Contains(sortable, page.SortBy) {
+		sortBy = page.SortBy
+	}
+
+	sortDir := "ASC"
+	if strings.EqualFold(page.SortDir, "desc") {
+		sortDir = "DESC"
+	}
+
+	return paginateBy(query, table, page, []string{sortBy}, sortDir, idOf)
+}
+
+// paginateBy pages through the query ordered by the columns and then id.
+// NULLs sort last in both directions. In cursor mode the cursor carries the
+// sort values and id of the last row, so the next page seeks past them
+// without looking the row up again, even when it was deleted meanwhile.
+func paginateBy[T any](
+	query *gorm.DB,
+	table string,
+	page model.PageRequest,
+	columns []string,
+	sortDir string,
+	idOf func(*T) int64,
+) ([]*T, *model.PageMeta, error) {
+
+	var rows []*T
+	var total int64
+
+	fields, err := sortFields[T](query, columns)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	if page.Limit <= 0 {
+		page.Limit = model.DefaultPageLimit
+	}
+	if page.Limit > model.MaxPageLimit {
+		page.Limit = model.MaxPageLimit
+	}
+
+	meta := &model.PageMeta{
+		Total: total,
+		Limit: page.Limit,
+	}
+
+	if page.CursorMode {
+		if page.Cursor != "" {
+			values, lastID, err := decodeCursor(page.Cursor, fields)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			condition, args := seekCondition(table, columns, values, lastID, sortDir)
+			query = query.Where(condition, args...)
+		}
+
+		query = query.Limit(page.Limit + 1)
+	} else {
+		if page.Page <= 0 {
+			page.Page = 1
+		}
+
+		meta.Page = page.Page
+		meta.TotalPages = int((total + int64(page.Limit) - 1) / int64(page.Limit))
+		query = query.Limit(page.Limit).Offset((page.Page - 1) * page.Limit)
+	}
+
+	for _, column := range columns {
+		query = query.Order(table + "." + column + " " + sortDir + " NULLS LAST")
+	}
+	if err := query.Order(table + ".id " + sortDir).Find(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+
+	if page.CursorMode && len(rows) > page.Limit {
+		rows = rows[:page.Limit]
+
+		last := rows[len(rows)-1]
+		meta.NextCursor, err = encodeCursor(query, fields, last, idOf(last))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return rows, meta, nil
+}
+
+// sortFields looks up the struct fields of the sort columns, which the
+// cursor reads its values from and decodes them into.
+func sortFields[T any](query *gorm.DB, columns []string) ([]*schema.Field, error) {
+	stmt := &gorm.Statement{DB: query}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, err
+	}
+
+	fields := make([]*schema.Field, len(columns))
+	for i, column := range columns {
+		fields[i] = stmt.Schema.LookUpField(column)
+		if fields[i] == nil {
+			return nil, fmt.Errorf("cannot sort %s by %s", stmt.Schema.Table, column)
+		}
+	}
+
+	return fields, nil
+}
+
+// seekCondition selects the rows after the cursor row in (columns..., id)
+// order. A row is later when it ties on the leading columns and sorts after
+// on the next one, or ties on all of them and has a later id. With NULLs
+// last, NULL sorts after every value and no value sorts after NULL.
+func seekCondition(table string, columns []string, values []interface{}, lastID int64, sortDir string) (string, []interface{}) {
+	op := ">"
+	if sortDir == "DESC" {
+		op = "<"
+	}
+
+	var (
+		clauses []string
+		args    []interface{}
+		ties    []string
+		tieArgs []interface{}
+	)
+
+	for i, column := range columns {
+		column = table + "." + column
+
+		if values[i] == nil {
+			ties = append(ties, column+" IS NULL")
+			continue
+		}
+
+		clauses = append(clauses, strings.Join(append(slices.Clone(ties), "("+column+" "+op+" ? OR "+column+" IS NULL)"), " AND "))
+		args = append(append(args, tieArgs...), values[i])
+
+		ties = append(ties, column+" = ?")
+		tieArgs = append(tieArgs, values[i])
+	}
+
+	clauses = append(clauses, strings.Join(append(ties, table+".id "+op+" ?"), " AND "))
+	args = append(append(args, tieArgs...), lastID)
+
+	return "((" + strings.Join(clauses, ") OR (") + "))", args
+}
+
+type pageCursor struct {
+	Values []json.RawMessage `json:"v"`
+	ID     int64             `json:"id"`
+}
+
+// encodeCursor stores the zero value of a sort field as NULL. A field that is
+// not a pointer reads NULL as its zero value, e.g. a ticket without a ticket
+// number, and seeking past "" instead of NULL would return the same page
+// again. The sortable string and time columns hold no zero values otherwise.
+func encodeCursor(query *gorm.DB, fields []*schema.Field, row interface{}, id int64) (string, error) {
+	cursor := pageCursor{ID: id}
+
+	for _, field := range fields {
+		value, zero := field.ValueOf(query.Statement.Context, reflect.ValueOf(row))
+		if zero {
+			value = nil
+		}
+
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		cursor.Values = append(cursor.Values, raw)
+	}
+
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor returns the sort values typed like their fields, so they are
+// bound the same way the driver stored them, and nil for NULL.
+func decodeCursor(cursor string, fields []*schema.Field) ([]interface{}, int64, error) {
+	errInvalid := model.NewFieldError("cursor", "cursor", "invalid cursor")
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, 0, errInvalid
+	}
+
+	var decoded pageCursor
+	if err := json.Unmarshal(data, &decoded); err != nil || len(decoded.Values) != len(fields) {
+		return nil, 0, errInvalid
+	}
+
+	values := make([]interface{}, len(fields))
+	for i, field := range fields {
+		if string(decoded.Values[i]) == "null" {
+			continue
+		}
+
+		value := reflect.New(field.FieldType)
+		if err := json.Unmarshal(decoded.Values[i], value.Interface()); err != nil {
+			return nil, 0, errInvalid
+		}
+
+		values[i] = reflect.Indirect(value.Elem()).Interface()
+	}
+
+	return values, decoded.ID, nil
+}
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	gormDB := newTestDB(t)
+
+	fields, err := sortFields[model.TroubleshootLog](gormDB, []string{"ticket_number", "done_date"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doneDate := time.Date(2026, time.October, 19, 8, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		row  model.TroubleshootLog
+		want []interface{}
+	}{
+		{
+			name: "values",
+			row:  model.TroubleshootLog{ID: 7, TicketNumber: "TS-0007", DoneDate: &doneDate},
+			want: []interface{}{"TS-0007", doneDate},
+		},
+		{
+			name: "null",
+			row:  model.TroubleshootLog{ID: 8, TicketNumber: "TS-0008"},
+			want: []interface{}{"TS-0008", nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := encodeCursor(gormDB, fields, &tt.row, tt.row.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			values, id, err := decodeCursor(cursor, fields)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if id != tt.row.ID {
+				t.Errorf("id = %d, want %d", id, tt.row.ID)
+			}
+			if len(values) != len(tt.want) || values[0] != tt.want[0] {
+				t.Fatalf("values = %v, want %v", values, tt.want)
+			}
+			if got, _ := values[1].(time.Time); tt.want[1] != nil && !got.Equal(tt.want[1].(time.Time)) || tt.want[1] == nil && values[1] != nil {
+				t.Errorf("done_date = %v, want %v", values[1], tt.want[1])
+			}
+		})
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	gormDB := newTestDB(t)
+
+	fields, err := sortFields[model.TroubleshootLog](gormDB, []string{"done_date"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encode := func(json string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(json))
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "not base64", cursor: "not a cursor!"},
+		{name: "not json", cursor: encode("{")},
+		{name: "too few values", cursor: encode(`{"v":[],"id":1}`)},
+		{name: "too many values", cursor: encode(`{"v":[null,null],"id":1}`)},
+		{name: "wrong type", cursor: encode(`{"v":["yesterday"],"id":1}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := decodeCursor(tt.cursor, fields)
+			assertErrorKind(t, err, model.ErrValidation)
+		})
+	}
+}
+
+// TestCursorPagination walks every sort column in both directions and
+// compares the cursor pages with one unpaged query. Tickets share sort
+// values and some are NULL, so the pages rely on the id tiebreak and on
+// NULLs sorting last.
+func TestCursorPagination(t *testing.T) {
+	gormDB := newTestDB(t)
+	repo := NewTroubleshootLogRepo(gormDB)
+	ctx := context.Background()
+
+	day := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 9 {
+		ticket := model.TroubleshootLog{
+			TroubleDate: day.AddDate(0, 0, i%3),
+			TroubleTime: day.AddDate(0, 0, i%3).Add(time.Duration(i%2) * time.Hour),
+			Status:      []string{"OPEN", "CLOSED", "IN_PROGRESS"}[i%3],
+		}
+		if i%4 != 0 {
+			doneDate := day.AddDate(0, 0, 5+i%2)
+			ticket.DoneDate = &doneDate
+			ticket.DoneTime = &doneDate
+		}
+		createTestTicket(t, gormDB, ticket)
+	}
+
+	for sortBy := range troubleshootLogSortColumns {
+		for _, sortDir := range []string{"asc", "desc"} {
+			t.Run(sortBy+" "+sortDir, func(t *testing.T) {
+				filter := model.TroubleshootLogFilter{SortBy: sortBy, SortDir: sortDir}
+
+				all, _, err := repo.FindAll(ctx, filter, model.PageRequest{Limit: model.MaxPageLimit})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var want, got []int64
+				for _, ticket := range all {
+					want = append(want, ticket.ID)
+				}
+
+				// Bounded, a cursor that does not advance would loop forever.
+				page := model.PageRequest{CursorMode: true, Limit: 2}
+				for range len(want) {
+					tickets, meta, err := repo.FindAll(ctx, filter, page)
+					if err != nil {
+						t.Fatal(err)
+					}
+					for _, ticket := range tickets {
+						got = append(got, ticket.ID)
+					}
+
+					if meta.NextCursor == "" {
+						break
+					}
+					page.Cursor = meta.NextCursor
+				}
+
+				if !slices.Equal(got, want) {
+					t.Fatalf("cursor pages = %v, want %v", got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestCursorSurvivesDeletedRow(t *testing.T) {
+	gormDB := newTestDB(t)
+	repo := NewTroubleshootLogRepo(gormDB)
+	ctx := context.Background()
+
+	var ids []int64
+	for range 4 {
+		ids = append(ids, createTestTicket(t, gormDB, model.TroubleshootLog{}))
+	}
+
+	filter := model.TroubleshootLogFilter{SortBy: "id", SortDir: "asc"}
+
+	first, meta, err := repo.FindAll(ctx, filter, model.PageRequest{CursorMode: true, Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first) != 2 || meta.NextCursor == "" {
+		t.Fatalf("first page = %d tickets, cursor %q", len(first), meta.NextCursor)
+	}
+
+	// The last row of the page is deleted before the next page is read.
+	if err := repo.Delete(ctx, first[1].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	second, _, err := repo.FindAll(ctx, filter, model.PageRequest{CursorMode: true, Cursor: meta.NextCursor, Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(second) != 2 || second[0].ID != ids[2] || second[1].ID != ids[3] {
+		t.Fatalf("second page = %v, want tickets %v", second, ids[2:])
+	}
+}
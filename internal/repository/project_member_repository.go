@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ProjectMemberRepo struct {
+	db *gorm.DB
+}
+
+func NewProjectMemberRepo(db *gorm.DB) model.IProjectMemberRepository {
+	return &ProjectMemberRepo{
+		db: db,
+	}
+}
+
+func (r *ProjectMemberRepo) FindMembers(ctx context.Context, projectID int64) ([]*model.User, error) {
+	var users []*model.User
+
+	err := r.db.WithContext(ctx).
+		Joins("JOIN project_members ON project_members.user_id = users.id").
+		Where("project_members.project_id = ? AND users.deleted_at IS NULL", projectID).
+		Order("users.name ASC").
+		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (r *ProjectMemberRepo) FindProjectIDsByUser(ctx context.Context, userID int64) ([]int64, error) {
+	var ids []int64
+
+	err := r.db.WithContext(ctx).
+		Model(&model.ProjectMember{}).
+		Where("user_id = ?", userID).
+		Pluck("project_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (r *ProjectMemberRepo) Add(ctx context.Context, member model.ProjectMember) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&member).Error
+}
+
+func (r *ProjectMemberRepo) Remove(ctx context.Context, projectID int64, userID int64) error {
+	return r.db.WithContext(ctx).
+		Where("project_id = ? AND user_id = ?", projectID, userID).
+		Delete(&model.ProjectMember{}).Error
+}
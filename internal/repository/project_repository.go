@@ -19,27 +19,23 @@ func NewProjectRepo(db *gorm.DB) model.IProjectRepository {
 	}
 }
 
-func (p *ProjectRepo) FindAll(ctx context.Context, project model.Project) ([]*model.Project, error) {
-	var projects []*model.Project
+var projectSortColumns = []string{"id", "name", "created_at", "updated_at"}
+
+func (p *ProjectRepo) FindAll(ctx context.Context, project model.Project, page model.PageRequest) ([]*model.Project, *model.PageMeta, error) {
 	query := p.db.WithContext(ctx).Model(&model.Project{}).Where("deleted_at IS NULL")
 
 	if project.Name != "" {
 		query = query.Where("name LIKE ?", "%"+project.Name+"%")
 	}
 
-	err := query.Find(&projects).Error
-	if err != nil {
-		return nil, err
-	}
-
-	return projects, nil
+	return paginate(query, "projects", page, projectSortColumns, func(p *model.Project) int64 { return p.Id })
 }
 
 func (p *ProjectRepo) FindByID(ctx context.Context, id int64) (*model.Project, error) {
 	var project model.Project
-	err := p.db.WithContext(ctx).First(&project, id).Error
+	err := p.db.WithContext(ctx).Where("deleted_at IS NULL").First(&project, id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, errors.New("project not found")
+		return nil, model.NewNotFoundError("project not found")
 	}
 	if err != nil {
 		return nil, err
@@ -52,7 +48,7 @@ func (p *ProjectRepo) Create(ctx context.Context, project model.Project) (*model
 	project.UpdatedAt = time.Now()
 	err := p.db.WithContext(ctx).Create(&project).Error
 	if err != nil {
-		return nil, err
+		return nil, translateError(err, "project")
 	}
 
 	return &project, nil
@@ -63,17 +59,15 @@ func (p *ProjectRepo) Update(ctx context.Context, project model.Project) error {
 
 	err := p.db.WithContext(ctx).Model(&model.Project{}).Where("id = ? AND deleted_at IS NULL", project.Id).Updates(&project).Error
 	if err != nil {
-		return err
+		return translateError(err, "project")
 	}
 	return nil
 }
 
-func (p *ProjectRepo) Delete(ctx context.Context, id int64) error {
-	err := p.db.WithContext(ctx).Model(&model.Project{}).Where("id = ?", id).Update("deleted_at", time.Now()).Error
-	if err != nil {
-		return err
-	}
-	return nil
+// Delete moves the open tickets of the project to replaceWith, when given,
+// and returns how many were moved. See deleteMasterData.
+func (p *ProjectRepo) Delete(ctx context.Context, id int64, replaceWith *int64) (int64, error) {
+	return deleteMasterData(p.db.WithContext(ctx), &model.Project{}, "project", model.TicketColumnProject, id, replaceWith)
 }
 
 func (r *ProjectRepo) FindByName(ctx context.Context, name string) (*model.Project, error) {
@@ -84,8 +78,20 @@ func (r *ProjectRepo) FindByName(ctx context.Context, name string) (*model.Proje
 		First(&project).Error
 
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, errors.New("project not found")
+		return nil, model.NewNotFoundError("project not found")
 	}
 
 	return &project, err
 }
+
+func (p *ProjectRepo) FindTrash(ctx context.Context, page model.PageRequest) ([]*model.Project, *model.PageMeta, error) {
+	return findTrash(p.db.WithContext(ctx), "projects", page, projectSortColumns, func(row *model.Project) int64 { return row.Id })
+}
+
+func (p *ProjectRepo) Restore(ctx context.Context, id int64) error {
+	return restoreDeleted(p.db.WithContext(ctx), &model.Project{}, id, "project")
+}
+
+func (p *ProjectRepo) Purge(ctx context.Context, id int64) error {
+	return purgeDeleted(p.db.WithContext(ctx), &model.Project{}, id, "project")
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// applyProjectScope limits a query to the projects the caller may see.
+// Queries made outside a user request are not limited.
+func applyProjectScope(ctx context.Context, query *gorm.DB, column string) *gorm.DB {
+	scope := model.ProjectScopeFromContext(ctx)
+	if scope == nil || scope.All {
+		return query
+	}
+
+	if len(scope.ProjectIDs) == 0 {
+		return query.Where("1 = 0")
+	}
+
+	return query.Where(column+" IN ?", scope.ProjectIDs)
+}
+
+// projectScopeCondition is applyProjectScope for raw SQL with named
+// arguments. It returns an " AND ..." fragment to append, possibly empty.
+func projectScopeCondition(ctx context.Context, column string, args map[string]interface{}) string {
+	scope := model.ProjectScopeFromContext(ctx)
+	if scope == nil || scope.All {
+		return ""
+	}
+
+	if len(scope.ProjectIDs) == 0 {
+		return " AND 1 = 0"
+	}
+
+	args["scope_project_ids"] = scope.ProjectIDs
+	return " AND " + column + " IN @scope_project_ids"
+}
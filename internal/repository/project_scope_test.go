@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+)
+
+func TestProjectScope(t *testing.T) {
+	gormDB := newTestDB(t)
+	repo := NewTroubleshootLogRepo(gormDB)
+
+	projectA := createTestProject(t, gormDB, "Proj A")
+	projectB := createTestProject(t, gormDB, "Proj B")
+
+	ticketA := createTestTicket(t, gormDB, model.TroubleshootLog{ProjectID: &projectA, Issue: "a"})
+	ticketB := createTestTicket(t, gormDB, model.TroubleshootLog{ProjectID: &projectB, Issue: "b"})
+	ticketNone := createTestTicket(t, gormDB, model.TroubleshootLog{Issue: "none"})
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want []int64
+	}{
+		{name: "background job", ctx: context.Background(), want: []int64{ticketA, ticketB, ticketNone}},
+		{name: "global scope", ctx: model.WithGlobalScope(context.Background()), want: []int64{ticketA, ticketB, ticketNone}},
+		{name: "one project", ctx: withScope(projectA), want: []int64{ticketA}},
+		{name: "two projects", ctx: withScope(projectA, projectB), want: []int64{ticketA, ticketB}},
+		{name: "no membership", ctx: withScope(), want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tickets, meta, err := repo.FindAll(tt.ctx, model.TroubleshootLogFilter{SortBy: "id", SortDir: "asc"}, model.PageRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []int64
+			for _, ticket := range tickets {
+				got = append(got, ticket.ID)
+			}
+			if !slices.Equal(got, tt.want) || meta.Total != int64(len(tt.want)) {
+				t.Fatalf("FindAll() = %v (total %d), want %v", got, meta.Total, tt.want)
+			}
+
+			for _, id := range []int64{ticketA, ticketB, ticketNone} {
+				_, err := repo.FindByID(tt.ctx, id)
+				if visible := slices.Contains(tt.want, id); visible && err != nil {
+					t.Errorf("FindByID(%d) error = %v", id, err)
+				} else if !visible {
+					assertErrorKind(t, err, model.ErrNotFound)
+				}
+			}
+		})
+	}
+}
+
+func TestProjectScopeTrash(t *testing.T) {
+	gormDB := newTestDB(t)
+	repo := NewTroubleshootLogRepo(gormDB)
+	ctx := context.Background()
+
+	projectA := createTestProject(t, gormDB, "Proj A")
+	projectB := createTestProject(t, gormDB, "Proj B")
+
+	ticketA := createTestTicket(t, gormDB, model.TroubleshootLog{ProjectID: &projectA})
+	ticketB := createTestTicket(t, gormDB, model.TroubleshootLog{ProjectID: &projectB})
+	for _, id := range []int64{ticketA, ticketB} {
+		if err := repo.Delete(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	trash, _, err := repo.FindTrash(withScope(projectA), model.PageRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trash) != 1 || trash[0].ID != ticketA {
+		t.Fatalf("FindTrash() = %v, want only ticket %d", trash, ticketA)
+	}
+
+	assertErrorKind(t, repo.Restore(withScope(projectA), ticketB), model.ErrNotFound)
+	assertErrorKind(t, repo.Purge(withScope(projectA), ticketB), model.ErrNotFound)
+
+	if err := repo.Restore(withScope(projectB), ticketB); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if _, err := repo.FindByID(ctx, ticketB); err != nil {
+		t.Fatalf("restored ticket: %v", err)
+	}
+}
+
+func TestProjectScopeCondition(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		want     string
+		wantArgs []int64
+	}{
+		{name: "background job", ctx: context.Background(), want: ""},
+		{name: "global scope", ctx: model.WithGlobalScope(context.Background()), want: ""},
+		{name: "no membership", ctx: withScope(), want: " AND 1 = 0"},
+		{name: "projects", ctx: withScope(1, 2), want: " AND t.project_id IN @scope_project_ids", wantArgs: []int64{1, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := map[string]interface{}{}
+
+			if got := projectScopeCondition(tt.ctx, "t.project_id", args); got != tt.want {
+				t.Errorf("projectScopeCondition() = %q, want %q", got, tt.want)
+			}
+
+			got, _ := args["scope_project_ids"].([]int64)
+			if !slices.Equal(got, tt.wantArgs) {
+				t.Errorf("scope_project_ids = %v, want %v", got, tt.wantArgs)
+			}
+		})
+	}
+}
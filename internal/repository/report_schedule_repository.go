@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReportScheduleRepo struct {
+	db *gorm.DB
+}
+
+func NewReportScheduleRepo(db *gorm.DB) model.IReportScheduleRepository {
+	return &ReportScheduleRepo{
+		db: db,
+	}
+}
+
+// FindAll, like the other finders, only returns schedules of the projects
+// in the caller's scope.
+func (r *ReportScheduleRepo) FindAll(ctx context.Context) ([]*model.ReportSchedule, error) {
+	var schedules []*model.ReportSchedule
+
+	err := applyProjectScope(ctx, r.db.WithContext(ctx), "project_id").
+		Where("deleted_at IS NULL").
+		Order("id ASC").
+		Find(&schedules).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return schedules, nil
+}
+
+func (r *ReportScheduleRepo) FindByID(ctx context.Context, id int64) (*model.ReportSchedule, error) {
+	var schedule model.ReportSchedule
+
+	err := applyProjectScope(ctx, r.db.WithContext(ctx), "project_id").
+		Where("id = ? AND deleted_at IS NULL", id).
+		First(&schedule).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, model.NewNotFoundError("report schedule not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &schedule, nil
+}
+
+func (r *ReportScheduleRepo) Create(ctx context.Context, schedule model.ReportSchedule) (*model.ReportSchedule, error) {
+	schedule.CreatedAt = time.Now()
+	schedule.UpdatedAt = time.Now()
+
+	if err := r.db.WithContext(ctx).Create(&schedule).Error; err != nil {
+		return nil, translateError(err, "report schedule")
+	}
+
+	return &schedule, nil
+}
+
+func (r *ReportScheduleRepo) Update(ctx context.Context, schedule model.ReportSchedule) error {
+	schedule.UpdatedAt = time.Now()
+
+	// Select the columns so that enabled=false and an empty template are saved.
+	err := r.db.WithContext(ctx).
+		Model(&model.ReportSchedule{}).
+		Where("id = ? AND deleted_at IS NULL", schedule.ID).
+		Select("project_id", "name", "cron_expression", "timezone", "period", "target", "template", "enabled", "updated_at").
+		Updates(&schedule).Error
+
+	return translateError(err, "report schedule")
+}
+
+func (r *ReportScheduleRepo) Delete(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).
+		Model(&model.ReportSchedule{}).
+		Where("id = ? AND deleted_at IS NULL", id).
+		Update("deleted_at", time.Now()).Error
+}
+
+func (r *ReportScheduleRepo) StationSummary(ctx context.Context, projectID int64, from time.Time, to time.Time) ([]*model.ReportStationSummary, error) {
+	var stations []*model.ReportStationSummary
+
+	reportedAt, closedAt := "t.trouble_date + t.trouble_time", "t.done_date + COALESCE(t.done_time, '00:00')"
+	start, end, startDate := "@from", "@to", "@from::date"
+	if isSQLite(r.db) {
+		reportedAt = sqliteTimestamp("t.trouble_date", "t.trouble_time")
+		closedAt = sqliteTimestamp("t.done_date", "COALESCE(t.done_time, '0000-01-01 00:00:00')")
+		start, end, startDate = sqliteWallClock("@from"), sqliteWallClock("@to"), "substr(@from, 1, 10)"
+	}
+
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT *
+		FROM (
+			SELECT
+				t.location_id,
+				COALESCE(MAX(l.name), '-') AS location_name,
+				COUNT(*) FILTER (WHERE `+reportedAt+` >= `+start+` AND `+reportedAt+` < `+end+`) AS new,
+				COUNT(*) FILTER (WHERE `+closedAt+` >= `+start+` AND `+closedAt+` < `+end+`) AS closed,
+				COUNT(*) FILTER (WHERE t.done_date IS NULL) AS open
+			FROM troubleshoot_logs t
+			LEFT JOIN locations l ON l.id = t.location_id
+			WHERE t.deleted_at IS NULL
+				AND t.project_id = @project_id
+				AND (t.done_date IS NULL OR t.done_date >= `+startDate+`)
+			GROUP BY t.location_id
+		) s
+		WHERE s.new > 0 OR s.closed > 0 OR s.open > 0
+		ORDER BY s.location_name`, map[string]interface{}{
+		"project_id": projectID,
+		"from":       from,
+		"to":         to,
+	}).Scan(&stations).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return stations, nil
+}
+
+func (r *ReportScheduleRepo) FindDeliveries(ctx context.Context, scheduleID int64, limit int) ([]*model.ReportDelivery, error) {
+	var deliveries []*model.ReportDelivery
+
+	err := r.db.WithContext(ctx).
+		Where("report_schedule_id = ?", scheduleID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (r *ReportScheduleRepo) FindDeliveryByID(ctx context.Context, id int64) (*model.ReportDelivery, error) {
+	var delivery model.ReportDelivery
+
+	err := r.db.WithContext(ctx).First(&delivery, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, model.NewNotFoundError("report delivery not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+func (r *ReportScheduleRepo) CreateDelivery(ctx context.Context, delivery model.ReportDelivery) (*model.ReportDelivery, error) {
+	delivery.CreatedAt = time.Now()
+	delivery.UpdatedAt = time.Now()
+
+	if err := r.db.WithContext(ctx).Create(&delivery).Error; err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+func (r *ReportScheduleRepo) UpdateDelivery(ctx context.Context, delivery model.ReportDelivery) error {
+	delivery.UpdatedAt = time.Now()
+
+	return r.db.WithContext(ctx).
+		Model(&model.ReportDelivery{}).
+		Where("id = ?", delivery.ID).
+		Select("status", "error", "attempts", "sent_at", "updated_at").
+		Updates(&delivery).Error
+}
+
+// ClaimRun records the slot and reports whether this call recorded it. It
+// is false when another instance claimed the slot first.
+func (r *ReportScheduleRepo) ClaimRun(ctx context.Context, scheduleID int64, scheduledAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.ReportScheduleRun{
+			ReportScheduleID: scheduleID,
+			ScheduledAt:      scheduledAt,
+			CreatedAt:        time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+var reportScheduleSortColumns = []string{"id", "name", "created_at", "updated_at"}
+
+func (r *ReportScheduleRepo) FindTrash(ctx context.Context, page model.PageRequest) ([]*model.ReportSchedule, *model.PageMeta, error) {
+	query := applyProjectScope(ctx, r.db.WithContext(ctx), "project_id")
+	return findTrash(query, "report_schedules", page, reportScheduleSortColumns, func(row *model.ReportSchedule) int64 { return row.ID })
+}
+
+func (r *ReportScheduleRepo) Restore(ctx context.Context, id int64) error {
+	query := applyProjectScope(ctx, r.db.WithContext(ctx), "project_id")
+	return restoreDeleted(query, &model.ReportSchedule{}, id, "report schedule")
+}
+
+// Purge removes the schedule together with its delivery history.
+func (r *ReportScheduleRepo) Purge(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("report_schedule_id = ? AND EXISTS (SELECT 1 FROM report_schedules WHERE id = ? AND deleted_at IS NOT NULL)", id, id).
+			Delete(&model.ReportDelivery{}).Error
+		if err != nil {
+			return err
+		}
+
+		return purgeDeleted(applyProjectScope(ctx, tx, "project_id"), &model.ReportSchedule{}, id, "report schedule")
+	})
+}
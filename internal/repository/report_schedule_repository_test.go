@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+)
+
+func TestClaimRun(t *testing.T) {
+	gormDB := newTestDB(t)
+	repo := NewReportScheduleRepo(gormDB)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, gormDB, "Proj A")
+
+	createSchedule := func(name string) int64 {
+		t.Helper()
+
+		schedule, err := repo.Create(ctx, model.ReportSchedule{
+			ProjectID:      projectID,
+			Name:           name,
+			CronExpression: "0 8 * * *",
+			Timezone:       "Asia/Jakarta",
+			Period:         "day",
+			Target:         "group",
+			Enabled:        true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return schedule.ID
+	}
+
+	daily := createSchedule("daily")
+	other := createSchedule("other")
+
+	slot := time.Date(2026, time.October, 19, 1, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		scheduleID int64
+		slot       time.Time
+		want       bool
+	}{
+		{name: "first claim", scheduleID: daily, slot: slot, want: true},
+		{name: "same slot again", scheduleID: daily, slot: slot, want: false},
+		{name: "next slot", scheduleID: daily, slot: slot.AddDate(0, 0, 1), want: true},
+		{name: "same slot of another schedule", scheduleID: other, slot: slot, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claimed, err := repo.ClaimRun(ctx, tt.scheduleID, tt.slot)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if claimed != tt.want {
+				t.Fatalf("ClaimRun() = %v, want %v", claimed, tt.want)
+			}
+		})
+	}
+
+	t.Run("concurrent claims", func(t *testing.T) {
+		next := slot.AddDate(0, 0, 2)
+
+		var (
+			wg      sync.WaitGroup
+			mu      sync.Mutex
+			claimed int
+		)
+		for range 5 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				ok, err := repo.ClaimRun(ctx, daily, next)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+				if ok {
+					claimed++
+				}
+			}()
+		}
+		wg.Wait()
+
+		if claimed != 1 {
+			t.Fatalf("%d instances claimed the slot, want 1", claimed)
+		}
+	})
+}
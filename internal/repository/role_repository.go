@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type RoleRepo struct {
+	db *gorm.DB
+}
+
+func NewRoleRepo(db *gorm.DB) model.IRoleRepository {
+	return &RoleRepo{
+		db: db,
+	}
+}
+
+type rolePermissionRow struct {
+	RoleID int64
+	Code   string
+}
+
+func (r *RoleRepo) FindAll(ctx context.Context) ([]*model.Role, error) {
+	var roles []*model.Role
+
+	if err := r.db.WithContext(ctx).Order("name ASC").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+
+	if err := r.loadPermissions(ctx, roles); err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+func (r *RoleRepo) FindByID(ctx context.Context, id int64) (*model.Role, error) {
+	return r.findOne(ctx, "id = ?", id)
+}
+
+func (r *RoleRepo) FindByName(ctx context.Context, name string) (*model.Role, error) {
+	return r.findOne(ctx, "name = ?", name)
+}
+
+func (r *RoleRepo) findOne(ctx context.Context, query string, arg interface{}) (*model.Role, error) {
+	var role model.Role
+
+	err := r.db.WithContext(ctx).Where(query, arg).First(&role).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, model.NewNotFoundError("role not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.loadPermissions(ctx, []*model.Role{&role}); err != nil {
+		return nil, err
+	}
+
+	return &role, nil
+}
+
+func (r *RoleRepo) loadPermissions(ctx context.Context, roles []*model.Role) error {
+	if len(roles) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*model.Role, len(roles))
+	ids := make([]int64, 0, len(roles))
+	for _, role := range roles {
+		role.Permissions = []string{}
+		byID[role.ID] = role
+		ids = append(ids, role.ID)
+	}
+
+	var rows []rolePermissionRow
+	err := r.db.WithContext(ctx).
+		Table("role_permissions").
+		Select("role_permissions.role_id, permissions.code").
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
+		Where("role_permissions.role_id IN ?", ids).
+		Order("permissions.code").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		byID[row.RoleID].Permissions = append(byID[row.RoleID].Permissions, row.Code)
+	}
+
+	return nil
+}
+
+func (r *RoleRepo) Create(ctx context.Context, role model.Role) (*model.Role, error) {
+	role.CreatedAt = time.Now()
+	role.UpdatedAt = time.Now()
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&role).Error; err != nil {
+			return translateError(err, "role")
+		}
+
+		return replaceRolePermissions(tx, role.ID, role.Permissions)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &role, nil
+}
+
+func (r *RoleRepo) Update(ctx context.Context, role model.Role) error {
+	role.UpdatedAt = time.Now()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.Role{}).
+			Where("id = ?", role.ID).
+			Select("name", "description", "updated_at").
+			Updates(&role).Error
+		if err != nil {
+			return translateError(err, "role")
+		}
+
+		return replaceRolePermissions(tx, role.ID, role.Permissions)
+	})
+}
+
+func (r *RoleRepo) Delete(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).Delete(&model.Role{}, id).Error
+}
+
+func (r *RoleRepo) FindPermissions(ctx context.Context) ([]*model.Permission, error) {
+	var permissions []*model.Permission
+
+	if err := r.db.WithContext(ctx).Order("code ASC").Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+func replaceRolePermissions(tx *gorm.DB, roleID int64, codes []string) error {
+	var permissions []*model.Permission
+	if len(codes) > 0 {
+		if err := tx.Where("code IN ?", codes).Find(&permissions).Error; err != nil {
+			return err
+		}
+	}
+
+	known := make(map[string]bool, len(permissions))
+	for _, permission := range permissions {
+		known[permission.Code] = true
+	}
+
+	for _, code := range codes {
+		if !known[code] {
+			return model.NewFieldError("permissions", "oneof", fmt.Sprintf("unknown permission %q", code))
+		}
+	}
+
+	if err := tx.Exec("DELETE FROM role_permissions WHERE role_id = ?", roleID).Error; err != nil {
+		return err
+	}
+
+	for _, permission := range permissions {
+		err := tx.Exec("INSERT INTO role_permissions (role_id, permission_id) VALUES (?, ?)", roleID, permission.ID).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
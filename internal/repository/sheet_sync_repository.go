@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type sheetSyncRepository struct {
+	db *gorm.DB
+}
+
+func NewSheetSyncRepo(db *gorm.DB) model.ISheetSyncRepository {
+	return &sheetSyncRepository{db: db}
+}
+
+// pending selects the queued tickets. A ticket deleted while it waits stays
+// queued and is appended once restored.
+func (r *sheetSyncRepository) pending(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Model(&model.TroubleshootLog{}).
+		Where("sheet_sync_pending = ? AND deleted_at IS NULL", true)
+}
+
+// FindPending returns the oldest queued tickets first, so the sheet keeps
+// the order they came in.
+func (r *sheetSyncRepository) FindPending(ctx context.Context, limit int) ([]*model.TroubleshootLog, error) {
+	var logs []*model.TroubleshootLog
+
+	err := r.pending(ctx).
+		Order("created_at ASC, id ASC").
+		Limit(limit).
+		Find(&logs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
+
+func (r *sheetSyncRepository) CountPending(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.pending(ctx).Count(&count).Error
+	return count, err
+}
+
+// Claim marks the ticket as being appended and reports whether this call
+// claimed it. The columns are written without touching updated_at, the
+// ticket itself did not change.
+func (r *sheetSyncRepository) Claim(ctx context.Context, id int64, staleBefore time.Time) (bool, error) {
+	result := r.pending(ctx).
+		Where("id = ? AND (sheet_sync_claimed_at IS NULL OR sheet_sync_claimed_at < ?)", id, staleBefore).
+		UpdateColumn("sheet_sync_claimed_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (r *sheetSyncRepository) MarkSynced(ctx context.Context, id int64, sheetRow int) error {
+	columns := map[string]interface{}{
+		"sheet_sync_pending":    false,
+		"sheet_sync_claimed_at": nil,
+	}
+	if sheetRow > 0 {
+		columns["sheet_row"] = sheetRow
+	}
+
+	return r.db.WithContext(ctx).
+		Model(&model.TroubleshootLog{}).
+		Where("id = ?", id).
+		UpdateColumns(columns).Error
+}
+
+// Release gives the claim up after a failed append, the next pass retries.
+func (r *sheetSyncRepository) Release(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).
+		Model(&model.TroubleshootLog{}).
+		Where("id = ?", id).
+		UpdateColumn("sheet_sync_claimed_at", nil).Error
+}
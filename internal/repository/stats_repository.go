@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+
+	"gorm.io/gorm"
+)
+
+const statsResolvedExpr = "t.done_date IS NOT NULL"
+
+var (
+	statsRepairExpr       = "EXTRACT(EPOCH FROM ((t.done_date + COALESCE(t.done_time, '00:00')) - (t.trouble_date + t.trouble_time))) / 60"
+	sqliteStatsRepairExpr = "(julianday(" + sqliteTimestamp("t.done_date", "COALESCE(t.done_time, '0000-01-01 00:00:00')") + ") - julianday(" + sqliteTimestamp("t.trouble_date", "t.trouble_time") + ")) * 1440"
+)
+
+type statsDimension struct {
+	id   string
+	key  string
+	join string
+}
+
+var statsDimensions = map[string]statsDimension{
+	model.StatsByStatus:     {key: "COALESCE(t.status, '')"},
+	model.StatsByPart:       {key: "COALESCE(t.part, '')"},
+	model.StatsByProject:    {id: "t.project_id", key: "COALESCE(d.name, '')", join: "LEFT JOIN projects d ON d.id = t.project_id"},
+	model.StatsByLocation:   {id: "t.location_id", key: "COALESCE(d.name, '')", join: "LEFT JOIN locations d ON d.id = t.location_id"},
+	model.StatsByDevice:     {id: "t.device_id", key: "COALESCE(d.name, '')", join: "LEFT JOIN devices d ON d.id = t.device_id"},
+	model.StatsByWorkType:   {id: "t.work_type_id", key: "COALESCE(d.name, '')", join: "LEFT JOIN work_types d ON d.id = t.work_type_id"},
+	model.StatsByTechnician: {id: "t.user_id", key: "COALESCE(d.name, '')", join: "LEFT JOIN users d ON d.id = t.user_id"},
+}
+
+var statsIntervals = map[string]string{
+	"day":   "1 day",
+	"week":  "1 week",
+	"month": "1 month",
+}
+
+// sqliteStatsIntervals are the SQLite date modifiers that truncate to the
+// start of an interval, weeks starting on Monday as in Postgres, and step
+// to the next one.
+var sqliteStatsIntervals = map[string]struct{ trunc, step string }{
+	"day":   {trunc: "'start of day'", step: "'+1 day'"},
+	"week":  {trunc: "'start of day', '-6 days', 'weekday 1'", step: "'+7 days'"},
+	"month": {trunc: "'start of month'", step: "'+1 month'"},
+}
+
+type StatsRepo struct {
+	db *gorm.DB
+}
+
+func NewStatsRepo(db *gorm.DB) model.IStatsRepository {
+	return &StatsRepo{
+		db: db,
+	}
+}
+
+func (s *StatsRepo) repairExpr() string {
+	if isSQLite(s.db) {
+		return sqliteStatsRepairExpr
+	}
+	return statsRepairExpr
+}
+
+// statsWhere returns the shared WHERE clause for troubleshoot_logs aliased as
+// t. Callers add the trouble_date range themselves because the time series
+// needs a wider window than the reported range. The caller's project scope
+// is applied too.
+func statsWhere(ctx context.Context, filter model.StatsFilter) (string, map[string]interface{}) {
+	conditions := []string{"t.deleted_at IS NULL"}
+	args := map[string]interface{}{
+		"from": filter.From,
+		"to":   filter.To,
+	}
+
+	if filter.ProjectID != nil {
+		conditions = append(conditions, "t.project_id = @project_id")
+		args["project_id"] = *filter.ProjectID
+	}
+
+	if filter.LocationID != nil {
+		conditions = append(conditions, "t.location_id = @location_id")
+		args["location_id"] = *filter.LocationID
+	}
+
+	return strings.Join(conditions, " AND ") + projectScopeCondition(ctx, "t.project_id", args), args
+}
+
+func (s *StatsRepo) Summary(ctx context.Context, filter model.StatsFilter) (*model.StatsSummary, error) {
+	var summary model.StatsSummary
+
+	where, args := statsWhere(ctx, filter)
+
+	err := s.db.WithContext(ctx).Raw(`
+		SELECT
+			COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE NOT (`+statsResolvedExpr+`)) AS open,
+			COUNT(*) FILTER (WHERE `+statsResolvedExpr+`) AS resolved,
+			AVG(`+s.repairExpr()+`) FILTER (WHERE `+statsResolvedExpr+`) AS mttr_minutes
+		FROM troubleshoot_logs t
+		WHERE `+where+` AND t.trouble_date >= @from AND t.trouble_date < @to`, args).
+		Scan(&summary).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &summary, nil
+}
+
+func (s *StatsRepo) Breakdown(ctx context.Context, filter model.StatsFilter) ([]*model.StatsGroup, error) {
+	var groups []*model.StatsGroup
+
+	dimension, ok := statsDimensions[filter.By]
+	if !ok {
+		return nil, model.NewValidationError("unknown stats dimension")
+	}
+
+	where, args := statsWhere(ctx, filter)
+
+	idSelect, groupBy := "NULL::bigint AS id", dimension.key
+	if isSQLite(s.db) {
+		idSelect = "NULL AS id"
+	}
+	if dimension.id != "" {
+		idSelect = dimension.id + " AS id"
+		groupBy = dimension.id + ", " + dimension.key
+	}
+
+	err := s.db.WithContext(ctx).Raw(`
+		SELECT
+			`+idSelect+`,
+			`+dimension.key+` AS key,
+			COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE NOT (`+statsResolvedExpr+`)) AS open,
+			COUNT(*) FILTER (WHERE `+statsResolvedExpr+`) AS resolved,
+			AVG(`+s.repairExpr()+`) FILTER (WHERE `+statsResolvedExpr+`) AS mttr_minutes
+		FROM troubleshoot_logs t
+		`+dimension.join+`
+		WHERE `+where+` AND t.trouble_date >= @from AND t.trouble_date < @to
+		GROUP BY `+groupBy+`
+		ORDER BY total DESC, key ASC`, args).
+		Scan(&groups).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// TimeSeries buckets tickets by period. The open backlog of a period counts
+// every ticket reported before the period ended and not resolved by then, so
+// it includes tickets reported before From.
+func (s *StatsRepo) TimeSeries(ctx context.Context, filter model.StatsFilter) ([]*model.StatsTimeSeriesPoint, error) {
+	var points []*struct {
+		model.StatsTimeSeriesPoint `gorm:"embedded"`
+		Period                     scanTime
+	}
+
+	step, ok := statsIntervals[filter.Interval]
+	if !ok {
+		return nil, model.NewValidationError("unknown stats interval")
+	}
+
+	where, args := statsWhere(ctx, filter)
+	args["unit"] = filter.Interval
+	args["step"] = step
+
+	periods := `
+		WITH periods AS (
+			SELECT p AS period_start, p + @step::interval AS period_end
+			FROM generate_series(date_trunc(@unit, @from::timestamp), @to::timestamp - interval '1 second', @step::interval) AS p
+		)`
+	if isSQLite(s.db) {
+		interval := sqliteStatsIntervals[filter.Interval]
+		start := "datetime(" + sqliteWallClock("@from") + ", " + interval.trunc + ")"
+		periods = `
+		WITH RECURSIVE periods(period_start, period_end) AS (
+			SELECT ` + start + `, datetime(` + start + `, ` + interval.step + `)
+			UNION ALL
+			SELECT period_end, datetime(period_end, ` + interval.step + `)
+			FROM periods
+			WHERE period_end < ` + sqliteWallClock("@to") + `
+		)`
+	}
+
+	err := s.db.WithContext(ctx).Raw(periods+`
+		SELECT
+			p.period_start AS period,
+			COUNT(t.id) FILTER (WHERE t.trouble_date >= p.period_start AND t.trouble_date < p.period_end) AS reported,
+			COUNT(t.id) FILTER (WHERE t.done_date >= p.period_start AND t.done_date < p.period_end) AS resolved,
+			COUNT(t.id) FILTER (WHERE t.trouble_date < p.period_end AND (t.done_date IS NULL OR t.done_date >= p.period_end)) AS open_backlog,
+			AVG(`+s.repairExpr()+`) FILTER (WHERE t.done_date >= p.period_start AND t.done_date < p.period_end) AS mttr_minutes
+		FROM periods p
+		LEFT JOIN troubleshoot_logs t ON `+where+`
+			AND t.trouble_date < p.period_end
+			AND (t.done_date IS NULL OR t.done_date >= p.period_start)
+		GROUP BY p.period_start
+		ORDER BY p.period_start`, args).
+		Scan(&points).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var series []*model.StatsTimeSeriesPoint
+	for _, point := range points {
+		point.StatsTimeSeriesPoint.Period = point.Period.Time
+		series = append(series, &point.StatsTimeSeriesPoint)
+	}
+
+	return series, nil
+}
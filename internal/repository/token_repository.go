@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TokenRepo struct {
+	db *gorm.DB
+}
+
+func NewTokenRepo(db *gorm.DB) model.ITokenRepository {
+	return &TokenRepo{
+		db: db,
+	}
+}
+
+func (r *TokenRepo) CreateRefreshToken(ctx context.Context, token model.RefreshToken) (*model.RefreshToken, error) {
+	if err := r.db.WithContext(ctx).Create(&token).Error; err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func (r *TokenRepo) FindRefreshTokenByHash(ctx context.Context, hash string) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+
+	err := r.db.WithContext(ctx).Where("token_hash = ?", hash).First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func (r *TokenRepo) RotateRefreshToken(ctx context.Context, oldID int64, next model.RefreshToken) (*model.RefreshToken, bool, error) {
+	rotated := false
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Only one caller can flip revoked_at, so a token raced by two
+		// refreshes is rotated once and the loser is treated as a reuse.
+		res := tx.Model(&model.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", oldID).
+			Update("revoked_at", time.Now())
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Create(&next).Error; err != nil {
+			return err
+		}
+
+		rotated = true
+		return tx.Model(&model.RefreshToken{}).
+			Where("id = ?", oldID).
+			Update("replaced_by_id", next.ID).Error
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if !rotated {
+		return nil, false, nil
+	}
+
+	return &next, true, nil
+}
+
+func (r *TokenRepo) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	return r.db.WithContext(ctx).
+		Model(&model.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *TokenRepo) RevokeUserRefreshTokens(ctx context.Context, userID int64) error {
+	return r.db.WithContext(ctx).
+		Model(&model.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *TokenRepo) RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.RevokedAccessToken{JTI: jti, ExpiresAt: expiresAt}).Error
+}
+
+func (r *TokenRepo) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	var count int64
+
+	err := r.db.WithContext(ctx).
+		Model(&model.RevokedAccessToken{}).
+		Where("jti = ?", jti).
+		Count(&count).Error
+
+	return count > 0, err
+}
+
+func (r *TokenRepo) DeleteExpired(ctx context.Context) error {
+	now := time.Now()
+
+	if err := r.db.WithContext(ctx).
+		Where("expires_at < ?", now).
+		Delete(&model.RevokedAccessToken{}).Error; err != nil {
+		return err
+	}
+
+	return r.db.WithContext(ctx).
+		Where("expires_at < ?", now).
+		Delete(&model.RefreshToken{}).Error
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+	"gorm.io/gorm"
+)
+
+// findTrash pages through the soft-deleted rows of a table. Besides the
+// entity's own sort columns the trash can be sorted by deleted_at.
+func findTrash[T any](
+	query *gorm.DB,
+	table string,
+	page model.PageRequest,
+	sortable []string,
+	idOf func(*T) int64,
+) ([]*T, *model.PageMeta, error) {
+	query = query.Model(new(T)).Where("deleted_at IS NOT NULL")
+	return paginate(query, table, page, slices.Concat(sortable, []string{"deleted_at"}), idOf)
+}
+
+// restoreDeleted clears deleted_at of a row in the trash. It fails with a
+// conflict when a live row took the same unique value in the meantime.
+func restoreDeleted(query *gorm.DB, value interface{}, id int64, entity string) error {
+	res := query.
+		Model(value).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"updated_at": time.Now(),
+		})
+	if res.Error != nil {
+		return translateError(res.Error, entity)
+	}
+	if res.RowsAffected == 0 {
+		return model.NewNotFoundError("%s not found in trash", entity)
+	}
+
+	return nil
+}
+
+// purgeDeleted removes a row in the trash for good. Rows that other records
+// still refer to are kept.
+func purgeDeleted(query *gorm.DB, value interface{}, id int64, entity string) error {
+	res := query.
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Delete(value)
+	if errors.Is(res.Error, gorm.ErrForeignKeyViolated) {
+		return model.NewConflictError("%s is still referenced by other records", entity)
+	}
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return model.NewNotFoundError("%s not found in trash", entity)
+	}
+
+	return nil
+}
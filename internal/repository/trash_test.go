@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+)
+
+func TestTrash(t *testing.T) {
+	gormDB := newTestDB(t)
+	repo := NewLocationRepo(gormDB)
+	ctx := context.Background()
+
+	create := func(name string, codeName string) int64 {
+		t.Helper()
+
+		location, err := repo.Create(ctx, model.Location{Name: name, CodeName: codeName})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return location.Id
+	}
+
+	deleted := create("Station One", "ST1")
+	referenced := create("Station Two", "ST2")
+	closed := time.Now()
+	createTestTicket(t, gormDB, model.TroubleshootLog{LocationID: &referenced, DoneDate: &closed})
+
+	for _, id := range []int64{deleted, referenced} {
+		if _, err := repo.Delete(ctx, id, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	live, _, err := repo.FindAll(ctx, model.Location{}, model.PageRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(live) != 0 {
+		t.Fatalf("FindAll() = %v, want no live locations", live)
+	}
+
+	trash, meta, err := repo.FindTrash(ctx, model.PageRequest{SortBy: "deleted_at"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Total != 2 || trash[0].Id != deleted || trash[1].Id != referenced {
+		t.Fatalf("FindTrash() = %v, want locations %d and %d", trash, deleted, referenced)
+	}
+
+	t.Run("restore conflicts with a live duplicate", func(t *testing.T) {
+		duplicate := create("Station One Again", "ST1")
+		assertErrorKind(t, repo.Restore(ctx, deleted), model.ErrConflict)
+
+		if _, err := repo.Delete(ctx, duplicate, nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.Restore(ctx, deleted); err != nil {
+			t.Fatalf("Restore() error = %v", err)
+		}
+		if _, err := repo.FindByID(ctx, deleted); err != nil {
+			t.Fatalf("restored location: %v", err)
+		}
+	})
+
+	t.Run("live rows are not in the trash", func(t *testing.T) {
+		assertErrorKind(t, repo.Restore(ctx, deleted), model.ErrNotFound)
+		assertErrorKind(t, repo.Purge(ctx, deleted), model.ErrNotFound)
+	})
+
+	t.Run("purge keeps referenced rows", func(t *testing.T) {
+		assertErrorKind(t, repo.Purge(ctx, referenced), model.ErrConflict)
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		if _, err := repo.Delete(ctx, deleted, nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.Purge(ctx, deleted); err != nil {
+			t.Fatalf("Purge() error = %v", err)
+		}
+		assertErrorKind(t, repo.Restore(ctx, deleted), model.ErrNotFound)
+	})
+}
@@ -2,6 +2,11 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"html"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
@@ -17,63 +22,154 @@ func NewTroubleshootLogRepo(db *gorm.DB) model.ITroubleshootLogRepository {
 	return &troubleshootLogRepository{db: db}
 }
 
-func (r *troubleshootLogRepository) FindAll(ctx context.Context, log model.TroubleshootLog) ([]*model.TroubleshootLog, error) {
-	var logs []*model.TroubleshootLog
+var troubleshootLogSortColumns = map[string][]string{
+	"id":            {"id"},
+	"ticket_number": {"ticket_number"},
+	"trouble_date":  {"trouble_date", "trouble_time"},
+	"done_date":     {"done_date", "done_time"},
+	"status":        {"status"},
+	"created_at":    {"created_at"},
+	"updated_at":    {"updated_at"},
+}
 
-	query := r.db.WithContext(ctx).
-		Model(&model.TroubleshootLog{}).
-		Where("deleted_at IS NULL")
+// FindAll pages through the filtered tickets, newest first unless the
+// filter sorts otherwise.
+func (r *troubleshootLogRepository) FindAll(ctx context.Context, filter model.TroubleshootLogFilter, page model.PageRequest) ([]*model.TroubleshootLog, *model.PageMeta, error) {
+	query := applyTroubleshootLogFilter(r.db.WithContext(ctx).Model(&model.TroubleshootLog{}), filter)
+	query = applyProjectScope(ctx, query, "troubleshoot_logs.project_id")
+
+	sortColumns, sortDir := troubleshootLogSort(filter)
+	return paginateBy(query, "troubleshoot_logs", page, sortColumns, sortDir, func(row *model.TroubleshootLog) int64 { return row.ID })
+}
+
+// applyTroubleshootLogFilter qualifies every column with the table name so
+// the filter also works on queries that join the master-data tables.
+func applyTroubleshootLogFilter(query *gorm.DB, filter model.TroubleshootLogFilter) *gorm.DB {
+	query = query.Where("troubleshoot_logs.deleted_at IS NULL")
 
-	if log.TicketNumber != "" {
-		query = query.Where("ticket_number ILIKE ?", "%"+log.TicketNumber+"%")
+	if filter.TicketNumber != "" {
+		query = query.Where(ilike(query, "troubleshoot_logs.ticket_number"), "%"+filter.TicketNumber+"%")
 	}
 
-	if log.Status != "" {
-		query = query.Where("status = ?", log.Status)
+	if len(filter.Statuses) > 0 {
+		query = query.Where("troubleshoot_logs.status IN ?", filter.Statuses)
 	}
 
-	if log.UserID != nil {
-		query = query.Where("user_id = ?", *log.UserID)
+	if filter.AssigneeID != nil {
+		query = query.Where("troubleshoot_logs.user_id = ?", *filter.AssigneeID)
 	}
 
-	if log.ProjectID != nil {
-		query = query.Where("project_id = ?", *log.ProjectID)
+	if filter.ProjectID != nil {
+		query = query.Where("troubleshoot_logs.project_id = ?", *filter.ProjectID)
 	}
 
-	if err := query.
-		Order("created_at DESC").
-		Find(&logs).Error; err != nil {
-		return nil, err
+	if filter.LocationID != nil {
+		query = query.Where("troubleshoot_logs.location_id = ?", *filter.LocationID)
 	}
 
-	return logs, nil
+	if filter.DeviceID != nil {
+		query = query.Where("troubleshoot_logs.device_id = ?", *filter.DeviceID)
+	}
+
+	if filter.WorkTypeID != nil {
+		query = query.Where("troubleshoot_logs.work_type_id = ?", *filter.WorkTypeID)
+	}
+
+	if filter.Reporter != "" {
+		query = query.Where(ilike(query, "troubleshoot_logs.whatsapp_sender"), "%"+filter.Reporter+"%")
+	}
+
+	if filter.Part != "" {
+		query = query.Where(ilike(query, "troubleshoot_logs.part"), "%"+filter.Part+"%")
+	}
+
+	query = whereTimeRange(query, "troubleshoot_logs.trouble_date", filter.TroubleDateFrom, filter.TroubleDateTo)
+	query = whereTimeRange(query, "troubleshoot_logs.done_date", filter.DoneDateFrom, filter.DoneDateTo)
+	query = whereTimeRange(query, "troubleshoot_logs.created_at", filter.CreatedFrom, filter.CreatedTo)
+
+	return query
+}
+
+func troubleshootLogSort(filter model.TroubleshootLogFilter) ([]string, string) {
+	sortDir := "DESC"
+	if strings.EqualFold(filter.SortDir, "asc") {
+		sortDir = "ASC"
+	}
+
+	sortColumns, ok := troubleshootLogSortColumns[filter.SortBy]
+	if !ok {
+		sortColumns = troubleshootLogSortColumns["created_at"]
+	}
+
+	return sortColumns, sortDir
+}
+
+func applyTroubleshootLogSort(query *gorm.DB, filter model.TroubleshootLogFilter) *gorm.DB {
+	sortColumns, sortDir := troubleshootLogSort(filter)
+
+	for _, column := range append(sortColumns, "id") {
+		query = query.Order("troubleshoot_logs." + column + " " + sortDir + " NULLS LAST")
+	}
+
+	return query
+}
+
+func whereTimeRange(query *gorm.DB, column string, from *time.Time, to *time.Time) *gorm.DB {
+	if from != nil {
+		query = query.Where(column+" >= ?", *from)
+	}
+
+	if to != nil {
+		query = query.Where(column+" < ?", *to)
+	}
+
+	return query
 }
 
 func (r *troubleshootLogRepository) FindByID(ctx context.Context, id int64) (*model.TroubleshootLog, error) {
 	var log model.TroubleshootLog
 
-	if err := r.db.WithContext(ctx).
-		Where("id = ? AND deleted_at IS NULL", id).
-		First(&log).Error; err != nil {
+	query := r.db.WithContext(ctx).Where("id = ? AND deleted_at IS NULL", id)
+
+	err := applyProjectScope(ctx, query, "project_id").First(&log).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, model.NewNotFoundError("troubleshoot log not found")
+	}
+	if err != nil {
 		return nil, err
 	}
 
 	return &log, nil
 }
 
+// Create inserts the ticket. Without a ticket number the column is left
+// NULL, which its unique constraint allows for any number of tickets.
 func (r *troubleshootLogRepository) Create(ctx context.Context, log model.TroubleshootLog) (*model.TroubleshootLog, error) {
-	if err := r.db.WithContext(ctx).Create(&log).Error; err != nil {
-		return nil, err
+	query := r.db.WithContext(ctx)
+	if log.TicketNumber == "" {
+		query = query.Omit("TicketNumber")
+	}
+
+	if err := query.Create(&log).Error; err != nil {
+		return nil, translateError(err, "troubleshoot log")
 	}
 
 	return &log, nil
 }
 
+// Update writes every editable column of log, zero values included. The
+// usecase merges the input into the stored ticket first, so a field the
+// client did not send keeps its value.
 func (r *troubleshootLogRepository) Update(ctx context.Context, log model.TroubleshootLog) error {
-	return r.db.WithContext(ctx).
+	err := r.db.WithContext(ctx).
 		Model(&model.TroubleshootLog{}).
 		Where("id = ? AND deleted_at IS NULL", log.ID).
-		Updates(log).Error
+		Select("trouble_date", "trouble_time", "done_date", "done_time", "duration",
+			"user_id", "project_id", "location_id", "device_id", "work_type_id",
+			"device_number", "part", "issue", "solution", "status", "updated_at").
+		Updates(&log).Error
+
+	return translateError(err, "troubleshoot log")
 }
 
 func (r *troubleshootLogRepository) Delete(ctx context.Context, id int64) error {
@@ -82,3 +178,391 @@ func (r *troubleshootLogRepository) Delete(ctx context.Context, id int64) error
 		Where("id = ?", id).
 		Update("deleted_at", time.Now()).Error
 }
+
+// ts_headline marks matches with these private use characters instead of
+// <mark>, so the text around them can be HTML-escaped first.
+const (
+	searchMarkStart = "\ue000"
+	searchMarkStop  = "\ue001"
+)
+
+var searchHeadlineOptions = "StartSel=" + searchMarkStart + ", StopSel=" + searchMarkStop + ", MaxWords=30, MinWords=10, MaxFragments=2"
+
+var searchHighlightReplacer = strings.NewReplacer(searchMarkStart, "<mark>", searchMarkStop, "</mark>")
+
+// Search ranks tickets with full-text search first. When the query has no
+// full-text match (typos, partial device codes) it falls back to trigram
+// similarity so a near match is still found. Highlights are HTML-escaped
+// with the matches wrapped in <mark>.
+func (r *troubleshootLogRepository) Search(ctx context.Context, in model.SearchTroubleshootLogInput) ([]*model.TroubleshootLogSearchResult, error) {
+	results, err := r.search(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		result.Highlight = model.TroubleshootLogHighlight{
+			Issue:           escapeHighlight(result.Highlight.Issue),
+			Solution:        escapeHighlight(result.Highlight.Solution),
+			Part:            escapeHighlight(result.Highlight.Part),
+			WhatsappMessage: escapeHighlight(result.Highlight.WhatsappMessage),
+		}
+	}
+
+	return results, nil
+}
+
+// escapeHighlight escapes the stored text, which comes straight from
+// WhatsApp, and only then turns the match markers into <mark> tags.
+func escapeHighlight(text string) string {
+	return searchHighlightReplacer.Replace(html.EscapeString(text))
+}
+
+func (r *troubleshootLogRepository) search(ctx context.Context, in model.SearchTroubleshootLogInput) ([]*model.TroubleshootLogSearchResult, error) {
+	var results []*model.TroubleshootLogSearchResult
+
+	args := map[string]interface{}{
+		"q":       in.Query,
+		"options": searchHeadlineOptions,
+		"limit":   in.Limit,
+		"offset":  (in.Page - 1) * in.Limit,
+	}
+	scope := projectScopeCondition(ctx, "t.project_id", args)
+
+	if isSQLite(r.db) {
+		return r.searchLike(ctx, in, args, scope)
+	}
+
+	err := r.db.WithContext(ctx).Raw(`
+		WITH q AS (SELECT websearch_to_tsquery('indonesian', @q) || websearch_to_tsquery('simple', @q) AS query)
+		SELECT t.*,
+			ts_rank_cd(t.search_vector, q.query) AS rank,
+			ts_headline('indonesian', coalesce(t.issue, ''), q.query, @options) AS highlight_issue,
+			ts_headline('indonesian', coalesce(t.solution, ''), q.query, @options) AS highlight_solution,
+			ts_headline('simple', coalesce(t.part, ''), q.query, @options) AS highlight_part,
+			ts_headline('indonesian', coalesce(t.whatsapp_message, ''), q.query, @options) AS highlight_whatsapp_message
+		FROM troubleshoot_logs t, q
+		WHERE t.deleted_at IS NULL AND t.search_vector @@ q.query`+scope+`
+		ORDER BY rank DESC, t.created_at DESC
+		LIMIT @limit OFFSET @offset`, args).
+		Scan(&results).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) > 0 || in.Page > 1 {
+		return results, nil
+	}
+
+	err = r.db.WithContext(ctx).Raw(`
+		SELECT t.*,
+			GREATEST(
+				word_similarity(@q, coalesce(t.issue, '')),
+				word_similarity(@q, coalesce(t.solution, '')),
+				word_similarity(@q, coalesce(t.part, ''))
+			) AS rank,
+			left(t.issue, 200) AS highlight_issue,
+			left(t.solution, 200) AS highlight_solution,
+			t.part AS highlight_part
+		FROM troubleshoot_logs t
+		WHERE t.deleted_at IS NULL`+scope+`
+			AND (@q <% t.issue OR @q <% t.solution OR @q <% t.part)
+		ORDER BY rank DESC, t.created_at DESC
+		LIMIT @limit`, args).
+		Scan(&results).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// searchLike is Search for SQLite, which has neither full-text search nor
+// trigrams. Every word of the query must appear in the issue, part,
+// solution or WhatsApp message, ranked with the weights of the Postgres
+// search vector.
+func (r *troubleshootLogRepository) searchLike(
+	ctx context.Context,
+	in model.SearchTroubleshootLogInput,
+	args map[string]interface{},
+	scope string,
+) ([]*model.TroubleshootLogSearchResult, error) {
+
+	var (
+		results    []*model.TroubleshootLogSearchResult
+		conditions []string
+		ranks      []string
+	)
+
+	for i, word := range strings.Fields(in.Query) {
+		name := fmt.Sprintf("word_%d", i)
+		args[name] = "%" + word + "%"
+
+		conditions = append(conditions, fmt.Sprintf(
+			"(COALESCE(t.issue, '') LIKE @%[1]s OR COALESCE(t.part, '') LIKE @%[1]s OR COALESCE(t.solution, '') LIKE @%[1]s OR COALESCE(t.whatsapp_message, '') LIKE @%[1]s)", name))
+		ranks = append(ranks, fmt.Sprintf(
+			"(COALESCE(t.issue, '') LIKE @%[1]s) + (COALESCE(t.part, '') LIKE @%[1]s) + 0.4 * (COALESCE(t.solution, '') LIKE @%[1]s) + 0.2 * (COALESCE(t.whatsapp_message, '') LIKE @%[1]s)", name))
+	}
+	if len(conditions) == 0 {
+		return results, nil
+	}
+
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT t.*,
+			`+strings.Join(ranks, " + ")+` AS rank,
+			substr(t.issue, 1, 200) AS highlight_issue,
+			substr(t.solution, 1, 200) AS highlight_solution,
+			t.part AS highlight_part
+		FROM troubleshoot_logs t
+		WHERE t.deleted_at IS NULL`+scope+`
+			AND `+strings.Join(conditions, " AND ")+`
+		ORDER BY rank DESC, t.created_at DESC
+		LIMIT @limit OFFSET @offset`, args).
+		Scan(&results).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// FindRecurrenceCandidates returns earlier tickets for the same device and
+// part whose issue text is similar to the given ticket, newest first.
+func (r *troubleshootLogRepository) FindRecurrenceCandidates(
+	ctx context.Context,
+	log model.TroubleshootLog,
+	since time.Time,
+	minSimilarity float64,
+) ([]*model.TroubleshootLog, error) {
+
+	var logs []*model.TroubleshootLog
+
+	query := r.db.WithContext(ctx).
+		Model(&model.TroubleshootLog{}).
+		Where("deleted_at IS NULL AND id < ? AND trouble_date >= ?", log.ID, since).
+		Where("LOWER(COALESCE(part, '')) = LOWER(?)", log.Part)
+	if !isSQLite(r.db) {
+		query = query.Where("similarity(COALESCE(issue, ''), ?) >= ?", log.Issue, minSimilarity)
+	}
+
+	switch {
+	case log.DeviceID != nil && log.DeviceNumber != "":
+		query = query.Where("(device_id = ? OR LOWER(device_number) = LOWER(?))", *log.DeviceID, log.DeviceNumber)
+	case log.DeviceID != nil:
+		query = query.Where("device_id = ?", *log.DeviceID)
+	case log.DeviceNumber != "":
+		query = query.Where("LOWER(device_number) = LOWER(?)", log.DeviceNumber)
+	default:
+		return nil, nil
+	}
+
+	if log.LocationID != nil {
+		query = query.Where("location_id = ?", *log.LocationID)
+	}
+
+	if err := query.
+		Order("trouble_date DESC, trouble_time DESC, id DESC").
+		Find(&logs).Error; err != nil {
+		return nil, err
+	}
+
+	if isSQLite(r.db) {
+		logs = slices.DeleteFunc(logs, func(candidate *model.TroubleshootLog) bool {
+			return trigramSimilarity(candidate.Issue, log.Issue) < minSimilarity
+		})
+	}
+
+	return logs, nil
+}
+
+func (r *troubleshootLogRepository) MarkRecurrence(ctx context.Context, id int64, previousID int64, count int) error {
+	return r.db.WithContext(ctx).
+		Model(&model.TroubleshootLog{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"is_recurring":     true,
+			"recurrence_of_id": previousID,
+			"recurrence_count": count,
+		}).Error
+}
+
+var ticketReferenceColumns = []string{
+	model.TicketColumnProject,
+	model.TicketColumnLocation,
+	model.TicketColumnDevice,
+	model.TicketColumnWorkType,
+}
+
+// CountOpen counts the open tickets of every project and location. It is
+// not project scoped, the counts are exported as metrics for operators.
+func (r *troubleshootLogRepository) CountOpen(ctx context.Context) ([]*model.OpenTicketCount, error) {
+	var counts []*model.OpenTicketCount
+
+	err := r.db.WithContext(ctx).
+		Table("troubleshoot_logs t").
+		Select("t.project_id, COALESCE(MAX(p.name), '') AS project_name, t.location_id, COALESCE(MAX(l.name), '') AS location_name, COUNT(*) AS count").
+		Joins("LEFT JOIN projects p ON p.id = t.project_id").
+		Joins("LEFT JOIN locations l ON l.id = t.location_id").
+		Where("t.done_date IS NULL AND t.deleted_at IS NULL").
+		Group("t.project_id, t.location_id").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// FindRepeatOffenders groups recurring tickets by location, device and part
+// and keeps the worst PerLocation groups of each location.
+func (r *troubleshootLogRepository) FindRepeatOffenders(ctx context.Context, filter model.RepeatOffenderFilter) ([]*model.RepeatOffender, error) {
+	var rows []*struct {
+		model.RepeatOffender `gorm:"embedded"`
+		FirstSeen            scanTime
+		LastSeen             scanTime
+	}
+
+	conditions := "t.deleted_at IS NULL AND t.trouble_date >= @from AND t.trouble_date < @to"
+	args := map[string]interface{}{
+		"from":         filter.From,
+		"to":           filter.To,
+		"per_location": filter.PerLocation,
+	}
+
+	if filter.ProjectID != nil {
+		conditions += " AND t.project_id = @project_id"
+		args["project_id"] = *filter.ProjectID
+	}
+
+	if filter.LocationID != nil {
+		conditions += " AND t.location_id = @location_id"
+		args["location_id"] = *filter.LocationID
+	}
+
+	conditions += projectScopeCondition(ctx, "t.project_id", args)
+
+	err := r.db.WithContext(ctx).Raw(`
+		WITH groups AS (
+			SELECT
+				t.location_id,
+				COALESCE(MAX(l.name), '') AS location_name,
+				t.device_id,
+				MAX(t.device_number) AS device_number,
+				MAX(t.part) AS part,
+				COUNT(*) AS occurrences,
+				COUNT(*) FILTER (WHERE t.is_recurring) AS recurrences,
+				MIN(t.trouble_date) AS first_seen,
+				MAX(t.trouble_date) AS last_seen,
+				MAX(t.id) AS latest_ticket_id
+			FROM troubleshoot_logs t
+			LEFT JOIN locations l ON l.id = t.location_id
+			WHERE `+conditions+`
+				AND (t.device_id IS NOT NULL OR COALESCE(t.device_number, '') <> '')
+			GROUP BY t.location_id, t.device_id, LOWER(t.device_number), LOWER(t.part)
+			HAVING COUNT(*) FILTER (WHERE t.is_recurring) > 0
+		), ranked AS (
+			SELECT g.*, ROW_NUMBER() OVER (PARTITION BY g.location_id ORDER BY g.recurrences DESC, g.occurrences DESC, g.last_seen DESC) AS rank
+			FROM groups g
+		)
+		SELECT * FROM ranked
+		WHERE rank <= @per_location
+		ORDER BY location_name, rank`, args).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var offenders []*model.RepeatOffender
+	for _, row := range rows {
+		row.RepeatOffender.FirstSeen = row.FirstSeen.Time
+		row.RepeatOffender.LastSeen = row.LastSeen.Time
+		offenders = append(offenders, &row.RepeatOffender)
+	}
+
+	return offenders, nil
+}
+
+// Export streams every ticket matching the filter, with master-data names
+// resolved, to fn one row at a time.
+func (r *troubleshootLogRepository) Export(
+	ctx context.Context,
+	filter model.TroubleshootLogFilter,
+	fn func(row *model.TroubleshootLogExportRow) error,
+) error {
+
+	query := r.db.WithContext(ctx).
+		Table("troubleshoot_logs").
+		Select(`troubleshoot_logs.*,
+			users.name AS user_name,
+			projects.name AS project_name,
+			locations.name AS location_name,
+			devices.name AS device_name,
+			work_types.name AS work_type_name`).
+		Joins("LEFT JOIN users ON users.id = troubleshoot_logs.user_id").
+		Joins("LEFT JOIN projects ON projects.id = troubleshoot_logs.project_id").
+		Joins("LEFT JOIN locations ON locations.id = troubleshoot_logs.location_id").
+		Joins("LEFT JOIN devices ON devices.id = troubleshoot_logs.device_id").
+		Joins("LEFT JOIN work_types ON work_types.id = troubleshoot_logs.work_type_id")
+	query = applyProjectScope(ctx, query, "troubleshoot_logs.project_id")
+
+	rows, err := applyTroubleshootLogSort(applyTroubleshootLogFilter(query, filter), filter).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row model.TroubleshootLogExportRow
+		if err := r.db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+var troubleshootLogTrashSortColumns = []string{"id", "ticket_number", "trouble_date", "created_at", "updated_at"}
+
+func (r *troubleshootLogRepository) FindTrash(ctx context.Context, page model.PageRequest) ([]*model.TroubleshootLog, *model.PageMeta, error) {
+	query := applyProjectScope(ctx, r.db.WithContext(ctx), "project_id")
+	return findTrash(query, "troubleshoot_logs", page, troubleshootLogTrashSortColumns, func(row *model.TroubleshootLog) int64 { return row.ID })
+}
+
+func (r *troubleshootLogRepository) Restore(ctx context.Context, id int64) error {
+	query := applyProjectScope(ctx, r.db.WithContext(ctx), "project_id")
+	return restoreDeleted(query, &model.TroubleshootLog{}, id, "troubleshoot log")
+}
+
+// Purge removes the ticket and its attachment rows. Later tickets that were
+// flagged as a recurrence of it keep their flag but lose the link.
+func (r *troubleshootLogRepository) Purge(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var log model.TroubleshootLog
+		err := applyProjectScope(ctx, tx, "project_id").
+			Where("id = ? AND deleted_at IS NOT NULL", id).
+			First(&log).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.NewNotFoundError("troubleshoot log not found in trash")
+		}
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&model.TroubleshootLog{}).
+			Where("recurrence_of_id = ?", id).
+			Update("recurrence_of_id", nil).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Where("troubleshoot_log_id = ?", id).Delete(&model.Attachment{}).Error; err != nil {
+			return err
+		}
+
+		return purgeDeleted(tx, &model.TroubleshootLog{}, id, "troubleshoot log")
+	})
+}
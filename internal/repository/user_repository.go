@@ -25,7 +25,7 @@ func (u *UserRepo) Create(ctx context.Context, user model.User) (newUser *model.
 	user.UpdatedAt = time.Now()
 	err = u.db.WithContext(ctx).Create(&user).Error
 	if err != nil {
-		return nil, err
+		return nil, translateError(err, "user")
 	}
 
 	return &user, nil
@@ -33,9 +33,9 @@ func (u *UserRepo) Create(ctx context.Context, user model.User) (newUser *model.
 
 func (u *UserRepo) FindByID(ctx context.Context, id int64) (*model.User, error) {
 	var user model.User
-	err := u.db.WithContext(ctx).First(&user, id).Error
+	err := u.db.WithContext(ctx).Where("deleted_at IS NULL").First(&user, id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, errors.New("user not found")
+		return nil, model.NewNotFoundError("user not found")
 	}
 	if err != nil {
 		return nil, err
@@ -47,7 +47,7 @@ func (u *UserRepo) FindByUsername(ctx context.Context, username string) (*model.
 	var user model.User
 	err := u.db.WithContext(ctx).First(&user, "username = ?", username).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, errors.New("user not found")
+		return nil, model.NewNotFoundError("user not found")
 	}
 	if err != nil {
 		return nil, err
@@ -55,20 +55,24 @@ func (u *UserRepo) FindByUsername(ctx context.Context, username string) (*model.
 	return &user, nil
 }
 
-func (u *UserRepo) FindAll(ctx context.Context, user model.User) ([]*model.User, error) {
-	var users []*model.User
+var userSortColumns = []string{"id", "name", "code_name", "username", "role", "created_at", "updated_at"}
+
+func (u *UserRepo) FindAll(ctx context.Context, user model.User, page model.PageRequest) ([]*model.User, *model.PageMeta, error) {
 	query := u.db.WithContext(ctx).Model(&model.User{}).Where("deleted_at IS NULL")
 
 	if user.Username != "" {
 		query = query.Where("username LIKE ?", "%"+user.Username+"%")
 	}
 
-	err := query.Find(&users).Error
-	if err != nil {
-		return nil, err
+	if user.CodeName != "" {
+		query = query.Where(ilike(query, "code_name"), "%"+user.CodeName+"%")
+	}
+
+	if user.Role != "" {
+		query = query.Where("role = ?", user.Role)
 	}
 
-	return users, nil
+	return paginate(query, "users", page, userSortColumns, func(u *model.User) int64 { return u.Id })
 }
 
 func (u *UserRepo) Update(ctx context.Context, user model.User) error {
@@ -77,10 +81,31 @@ func (u *UserRepo) Update(ctx context.Context, user model.User) error {
 	err := u.db.WithContext(ctx).
 		Model(&model.User{}).
 		Where("id = ? AND deleted_at IS NULL", user.Id).
+		Omit("password", "password_changed_at").
 		Updates(user).Error
 
 	if err != nil {
-		return err
+		return translateError(err, "user")
+	}
+	return nil
+}
+
+func (u *UserRepo) UpdatePassword(ctx context.Context, id int64, passwordHash string) error {
+	now := time.Now()
+
+	res := u.db.WithContext(ctx).
+		Model(&model.User{}).
+		Where("id = ? AND deleted_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"password":            passwordHash,
+			"password_changed_at": now,
+			"updated_at":          now,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return model.NewNotFoundError("user not found")
 	}
 	return nil
 }
@@ -104,10 +129,22 @@ func (r *UserRepo) FindByCodeName(ctx context.Context, codeName string) (*model.
 
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, model.NewNotFoundError("user not found")
 		}
 		return nil, err
 	}
 
 	return &user, nil
 }
+
+func (u *UserRepo) FindTrash(ctx context.Context, page model.PageRequest) ([]*model.User, *model.PageMeta, error) {
+	return findTrash(u.db.WithContext(ctx), "users", page, userSortColumns, func(row *model.User) int64 { return row.Id })
+}
+
+func (u *UserRepo) Restore(ctx context.Context, id int64) error {
+	return restoreDeleted(u.db.WithContext(ctx), &model.User{}, id, "user")
+}
+
+func (u *UserRepo) Purge(ctx context.Context, id int64) error {
+	return purgeDeleted(u.db.WithContext(ctx), &model.User{}, id, "user")
+}
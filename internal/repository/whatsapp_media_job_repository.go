@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type whatsAppMediaJobRepository struct {
+	db *gorm.DB
+}
+
+func NewWhatsAppMediaJobRepo(db *gorm.DB) model.IWhatsAppMediaJobRepository {
+	return &whatsAppMediaJobRepository{db: db}
+}
+
+func (r *whatsAppMediaJobRepository) Create(ctx context.Context, jobs []*model.WhatsAppMediaJob) error {
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	return r.db.WithContext(ctx).Create(&jobs).Error
+}
+
+func (r *whatsAppMediaJobRepository) pending(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Model(&model.WhatsAppMediaJob{}).
+		Where("failed_at IS NULL")
+}
+
+// FindPending returns the oldest jobs first, so the attachments of a ticket
+// are added in the order they were sent.
+func (r *whatsAppMediaJobRepository) FindPending(ctx context.Context, limit int) ([]*model.WhatsAppMediaJob, error) {
+	var jobs []*model.WhatsAppMediaJob
+
+	err := r.pending(ctx).
+		Order("created_at ASC, id ASC").
+		Limit(limit).
+		Find(&jobs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+// Claim marks the job as being processed and reports whether this call
+// claimed it.
+func (r *whatsAppMediaJobRepository) Claim(ctx context.Context, id int64, staleBefore time.Time) (bool, error) {
+	result := r.pending(ctx).
+		Where("id = ? AND (claimed_at IS NULL OR claimed_at < ?)", id, staleBefore).
+		Update("claimed_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (r *whatsAppMediaJobRepository) Complete(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).Delete(&model.WhatsAppMediaJob{}, id).Error
+}
+
+// Retry gives the claim up after a failed attempt, the next pass tries again.
+func (r *whatsAppMediaJobRepository) Retry(ctx context.Context, id int64, reason string) error {
+	return r.db.WithContext(ctx).
+		Model(&model.WhatsAppMediaJob{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"attempts":   gorm.Expr("attempts + 1"),
+			"last_error": reason,
+			"claimed_at": nil,
+		}).Error
+}
+
+// Fail stops the job for good. The row is kept with its error for whoever
+// looks into the missing attachment.
+func (r *whatsAppMediaJobRepository) Fail(ctx context.Context, id int64, reason string) error {
+	return r.db.WithContext(ctx).
+		Model(&model.WhatsAppMediaJob{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"attempts":   gorm.Expr("attempts + 1"),
+			"last_error": reason,
+			"claimed_at": nil,
+			"failed_at":  time.Now(),
+		}).Error
+}
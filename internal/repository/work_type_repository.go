@@ -19,30 +19,26 @@ func NewWorkTypeRepo(db *gorm.DB) model.IWorkTypeRepository {
 	}
 }
 
-func (w *WorkTypeRepo) FindAll(ctx context.Context, workType model.WorkType) ([]*model.WorkType, error) {
-	var workTypes []*model.WorkType
+var workTypeSortColumns = []string{"id", "name", "created_at", "updated_at"}
 
+func (w *WorkTypeRepo) FindAll(ctx context.Context, workType model.WorkType, page model.PageRequest) ([]*model.WorkType, *model.PageMeta, error) {
 	query := w.db.WithContext(ctx).
 		Model(&model.WorkType{}).
 		Where("deleted_at IS NULL")
 
 	if workType.Name != "" {
-		query = query.Where("name ILIKE ?", "%"+workType.Name+"%")
+		query = query.Where(ilike(query, "name"), "%"+workType.Name+"%")
 	}
 
-	if err := query.Find(&workTypes).Error; err != nil {
-		return nil, err
-	}
-
-	return workTypes, nil
+	return paginate(query, "work_types", page, workTypeSortColumns, func(w *model.WorkType) int64 { return w.Id })
 }
 
 func (w *WorkTypeRepo) FindByID(ctx context.Context, id int64) (*model.WorkType, error) {
 	var workType model.WorkType
 
-	err := w.db.WithContext(ctx).First(&workType, id).Error
+	err := w.db.WithContext(ctx).Where("deleted_at IS NULL").First(&workType, id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, errors.New("work type not found")
+		return nil, model.NewNotFoundError("work type not found")
 	}
 	if err != nil {
 		return nil, err
@@ -56,7 +52,7 @@ func (w *WorkTypeRepo) Create(ctx context.Context, workType model.WorkType) (*mo
 	workType.UpdatedAt = time.Now()
 
 	if err := w.db.WithContext(ctx).Create(&workType).Error; err != nil {
-		return nil, err
+		return nil, translateError(err, "work type")
 	}
 
 	return &workType, nil
@@ -67,16 +63,26 @@ func (w *WorkTypeRepo) Update(ctx context.Context, workType model.WorkType) erro
 
 	err := w.db.WithContext(ctx).Model(&model.WorkType{}).Where("id = ? AND deleted_at IS NULL", workType.Id).Updates(&workType).Error
 	if err != nil {
-		return err
+		return translateError(err, "work type")
 	}
 
 	return nil
 }
 
-func (w *WorkTypeRepo) Delete(ctx context.Context, id int64) error {
-	err := w.db.WithContext(ctx).Model(&model.WorkType{}).Where("id = ? AND deleted_at IS NULL", id).Update("deleted_at", time.Now()).Error
-	if err != nil {
-		return err
-	}
-	return nil
+// Delete moves the open tickets of the work type to replaceWith, when given,
+// and returns how many were moved. See deleteMasterData.
+func (w *WorkTypeRepo) Delete(ctx context.Context, id int64, replaceWith *int64) (int64, error) {
+	return deleteMasterData(w.db.WithContext(ctx), &model.WorkType{}, "work type", model.TicketColumnWorkType, id, replaceWith)
+}
+
+func (w *WorkTypeRepo) FindTrash(ctx context.Context, page model.PageRequest) ([]*model.WorkType, *model.PageMeta, error) {
+	return findTrash(w.db.WithContext(ctx), "work_types", page, workTypeSortColumns, func(row *model.WorkType) int64 { return row.Id })
+}
+
+func (w *WorkTypeRepo) Restore(ctx context.Context, id int64) error {
+	return restoreDeleted(w.db.WithContext(ctx), &model.WorkType{}, id, "work type")
+}
+
+func (w *WorkTypeRepo) Purge(ctx context.Context, id int64) error {
+	return purgeDeleted(w.db.WithContext(ctx), &model.WorkType{}, id, "work type")
 }
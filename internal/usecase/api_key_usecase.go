@@ -0,0 +1,203 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/helper"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+)
+
+// apiKeyTouchInterval limits how often last_used_at is written for a busy key.
+const apiKeyTouchInterval = time.Minute
+
+type APIKeyUsecase struct {
+	apiKeyRepo model.IAPIKeyRepository
+	roleRepo   model.IRoleRepository
+	userRepo   model.IUserRepository
+}
+
+func NewAPIKeyUsecase(apiKeyRepo model.IAPIKeyRepository, roleRepo model.IRoleRepository, userRepo model.IUserRepository) model.IAPIKeyUsecase {
+	return &APIKeyUsecase{
+		apiKeyRepo: apiKeyRepo,
+		roleRepo:   roleRepo,
+		userRepo:   userRepo,
+	}
+}
+
+func (a *APIKeyUsecase) FindAll(ctx context.Context) ([]*model.APIKey, error) {
+	if !hasPermission(ctx, model.PermissionAPIKeyManage) {
+		return nil, errForbidden(model.PermissionAPIKeyManage)
+	}
+
+	keys, err := a.apiKeyRepo.FindAll(ctx)
+	if err != nil {
+		logrus.WithContext(ctx).Error("Failed to fetch api keys: ", err)
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (a *APIKeyUsecase) Create(ctx context.Context, in model.CreateAPIKeyInput) (*model.IssuedAPIKey, error) {
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
+		"name":   in.Name,
+		"scopes": in.Scopes,
+	})
+
+	claims, ok := ctx.Value(model.BearerAuthKey).(*model.CustomClaims)
+	if !ok || claims == nil || !claims.HasPermission(model.PermissionAPIKeyManage) {
+		return nil, errForbidden(model.PermissionAPIKeyManage)
+	}
+
+	if err := v.StructCtx(ctx, in); err != nil {
+		log.Error("Validation error: ", err)
+		return nil, err
+	}
+
+	if in.ExpiresAt != nil && !in.ExpiresAt.After(time.Now()) {
+		return nil, model.NewFieldError("expires_at", "gt", "expires_at must be in the future")
+	}
+
+	if err := a.validateScopes(ctx, claims, in.Scopes); err != nil {
+		return nil, err
+	}
+
+	prefix, err := helper.RandomToken(6)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := helper.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	// The prefix must not contain the separator used to split it back out.
+	prefix = model.APIKeyPrefix + strings.ReplaceAll(prefix, "_", "-")
+	key := prefix + "_" + secret
+
+	created, err := a.apiKeyRepo.Create(ctx, model.APIKey{
+		Name:      in.Name,
+		Prefix:    prefix,
+		KeyHash:   helper.HashToken(key),
+		Scopes:    in.Scopes,
+		CreatedBy: &claims.UserID,
+		ExpiresAt: in.ExpiresAt,
+	})
+	if err != nil {
+		log.Error("Failed to create api key: ", err)
+		return nil, err
+	}
+
+	log.WithField("id", created.ID).Info("API key issued")
+	return &model.IssuedAPIKey{
+		APIKey: *created,
+		Key:    key,
+	}, nil
+}
+
+// validateScopes accepts only known permission codes the issuer holds, so a
+// key can never grant more than its creator has.
+func (a *APIKeyUsecase) validateScopes(ctx context.Context, claims *model.CustomClaims, scopes []string) error {
+	permissions, err := a.roleRepo.FindPermissions(ctx)
+	if err != nil {
+		return err
+	}
+
+	known := make(map[string]bool, len(permissions))
+	for _, p := range permissions {
+		known[p.Code] = true
+	}
+
+	for _, scope := range scopes {
+		if !known[scope] {
+			return model.NewFieldError("scopes", "oneof", fmt.Sprintf("unknown scope %q", scope))
+		}
+		if !claims.HasPermission(scope) {
+			return model.NewForbiddenError("cannot grant scope %q you do not have", scope)
+		}
+	}
+
+	return nil
+}
+
+func (a *APIKeyUsecase) Revoke(ctx context.Context, id int64) error {
+	if !hasPermission(ctx, model.PermissionAPIKeyManage) {
+		return errForbidden(model.PermissionAPIKeyManage)
+	}
+
+	if _, err := a.apiKeyRepo.FindByID(ctx, id); err != nil {
+		return err
+	}
+
+	if err := a.apiKeyRepo.Revoke(ctx, id); err != nil {
+		logrus.WithContext(ctx).WithField("id", id).Error("Failed to revoke api key: ", err)
+		return err
+	}
+
+	logrus.WithContext(ctx).WithField("id", id).Info("API key revoked")
+	return nil
+}
+
+// Authenticate resolves a presented key into claims carrying its scopes.
+// A key acts for its creator, so it is refused once the creator is deleted
+// and only keeps the scopes the creator's role still grants.
+func (a *APIKeyUsecase) Authenticate(ctx context.Context, key string) (*model.CustomClaims, error) {
+	errInvalid := model.NewUnauthorizedError("invalid api key")
+
+	if !strings.HasPrefix(key, model.APIKeyPrefix) {
+		return nil, errInvalid
+	}
+
+	apiKey, err := a.apiKeyRepo.FindByHash(ctx, helper.HashToken(key))
+	if err != nil {
+		return nil, err
+	}
+
+	if apiKey == nil || apiKey.RevokedAt != nil {
+		return nil, errInvalid
+	}
+
+	if apiKey.ExpiresAt != nil && time.Now().After(*apiKey.ExpiresAt) {
+		return nil, errInvalid
+	}
+
+	if apiKey.CreatedBy == nil {
+		return nil, errInvalid
+	}
+
+	creator, err := a.userRepo.FindByID(ctx, *apiKey.CreatedBy)
+	if errors.Is(err, model.ErrNotFound) || (err == nil && creator == nil) {
+		return nil, errInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := a.roleRepo.FindByName(ctx, creator.Role)
+	if errors.Is(err, model.ErrNotFound) {
+		return nil, errInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.apiKeyRepo.TouchLastUsed(ctx, apiKey.ID, apiKeyTouchInterval); err != nil {
+		logrus.WithContext(ctx).WithField("id", apiKey.ID).Warn("Failed to record api key use: ", err)
+	}
+
+	scopes := slices.DeleteFunc(slices.Clone(apiKey.Scopes), func(scope string) bool {
+		return !slices.Contains(role.Permissions, scope)
+	})
+
+	return &model.CustomClaims{
+		UserID:      creator.Id,
+		Role:        "api_key",
+		Permissions: scopes,
+		APIKeyID:    apiKey.ID,
+	}, nil
+}
@@ -0,0 +1,212 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/config"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/helper"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+)
+
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+type AttachmentUsecase struct {
+	attachmentRepo   model.IAttachmentRepository
+	troubleshootRepo model.ITroubleshootLogRepository
+	storage          model.IFileStorage
+}
+
+func NewAttachmentUsecase(
+	attachmentRepo model.IAttachmentRepository,
+	troubleshootRepo model.ITroubleshootLogRepository,
+	storage model.IFileStorage,
+) model.IAttachmentUsecase {
+	return &AttachmentUsecase{
+		attachmentRepo:   attachmentRepo,
+		troubleshootRepo: troubleshootRepo,
+		storage:          storage,
+	}
+}
+
+func (a *AttachmentUsecase) FindByTroubleshootLogID(ctx context.Context, logID int64) ([]*model.Attachment, error) {
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
+		"troubleshoot_log_id": logID,
+	})
+
+	if _, err := a.troubleshootRepo.FindByID(ctx, logID); err != nil {
+		log.Error("Failed to fetch troubleshoot log: ", err)
+		return nil, model.NewNotFoundError("troubleshoot log not found")
+	}
+
+	attachments, err := a.attachmentRepo.FindByTroubleshootLogID(ctx, logID)
+	if err != nil {
+		log.Error("Failed to fetch attachments: ", err)
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+func (a *AttachmentUsecase) Upload(ctx context.Context, in model.UploadAttachmentInput) (*model.Attachment, error) {
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
+		"troubleshoot_log_id": in.TroubleshootLogID,
+		"file_name":           in.FileName,
+		"size":                len(in.Data),
+	})
+
+	if err := v.StructCtx(ctx, in); err != nil {
+		log.Error("Validation error: ", err)
+		return nil, err
+	}
+
+	if int64(len(in.Data)) > config.AttachmentMaxSize() {
+		return nil, model.NewFieldError("file", "max", fmt.Sprintf("file is too large, max %d bytes", config.AttachmentMaxSize()))
+	}
+
+	// The declared type from the client is not trusted, sniff the content.
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(in.Data))
+	if !slices.Contains(config.AttachmentAllowedTypes(), contentType) {
+		return nil, model.NewFieldError("file", "content_type", fmt.Sprintf("content type %s is not allowed", contentType))
+	}
+
+	if strings.HasPrefix(contentType, "image/") {
+		if err := helper.CheckImagePixels(in.Data, config.AttachmentMaxPixels()); errors.Is(err, helper.ErrImageTooLarge) {
+			return nil, model.NewFieldError("file", "max_pixels", fmt.Sprintf("image is too large, max %d pixels", config.AttachmentMaxPixels()))
+		}
+	}
+
+	if _, err := a.troubleshootRepo.FindByID(ctx, in.TroubleshootLogID); err != nil {
+		log.Error("Failed to fetch troubleshoot log: ", err)
+		return nil, model.NewNotFoundError("troubleshoot log not found")
+	}
+
+	fileName := sanitizeFileName(in.FileName)
+	key := fmt.Sprintf("troubleshoot-logs/%d/%d-%s", in.TroubleshootLogID, time.Now().UnixNano(), fileName)
+
+	if err := a.storage.Put(ctx, key, contentType, in.Data); err != nil {
+		log.Error("Failed to store attachment: ", err)
+		return nil, err
+	}
+
+	var thumbnailKey *string
+	if strings.HasPrefix(contentType, "image/") {
+		thumbnail, err := helper.GenerateThumbnail(in.Data, config.AttachmentMaxPixels())
+		if err != nil {
+			log.Warn("Failed to generate thumbnail: ", err)
+		} else {
+			thumbKey := key + ".thumb.jpg"
+			if err := a.storage.Put(ctx, thumbKey, "image/jpeg", thumbnail); err != nil {
+				log.Warn("Failed to store thumbnail: ", err)
+			} else {
+				thumbnailKey = &thumbKey
+			}
+		}
+	}
+
+	attachment, err := a.attachmentRepo.Create(ctx, model.Attachment{
+		TroubleshootLogID: in.TroubleshootLogID,
+		FileName:          fileName,
+		ContentType:       contentType,
+		Size:              int64(len(in.Data)),
+		StorageKey:        key,
+		ThumbnailKey:      thumbnailKey,
+		Source:            in.Source,
+	})
+	if err != nil {
+		log.Error("Failed to create attachment: ", err)
+		removeBlobs(ctx, a.storage, key, thumbnailKey)
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+func (a *AttachmentUsecase) Open(ctx context.Context, logID int64, id int64, thumbnail bool) (*model.Attachment, io.ReadCloser, error) {
+	attachment, err := a.findForLog(ctx, logID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key := attachment.StorageKey
+	if thumbnail {
+		if attachment.ThumbnailKey == nil {
+			return nil, nil, model.NewNotFoundError("attachment has no thumbnail")
+		}
+		key = *attachment.ThumbnailKey
+	}
+
+	file, err := a.storage.Get(ctx, key)
+	if err != nil {
+		logrus.WithContext(ctx).WithField("key", key).Error("Failed to read attachment: ", err)
+		return nil, nil, err
+	}
+
+	return attachment, file, nil
+}
+
+func (a *AttachmentUsecase) Delete(ctx context.Context, logID int64, id int64) error {
+	if _, err := a.findForLog(ctx, logID, id); err != nil {
+		return err
+	}
+
+	if err := a.attachmentRepo.Delete(ctx, id); err != nil {
+		logrus.WithContext(ctx).WithField("id", id).Error("Failed to delete attachment: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (a *AttachmentUsecase) findForLog(ctx context.Context, logID int64, id int64) (*model.Attachment, error) {
+	if _, err := a.troubleshootRepo.FindByID(ctx, logID); err != nil {
+		return nil, err
+	}
+
+	attachment, err := a.attachmentRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if attachment.TroubleshootLogID != logID {
+		return nil, model.NewNotFoundError("attachment not found")
+	}
+
+	return attachment, nil
+}
+
+func removeBlobs(ctx context.Context, storage model.IFileStorage, key string, thumbnailKey *string) {
+	if err := storage.Delete(ctx, key); err != nil {
+		logrus.WithContext(ctx).WithField("key", key).Warn("Failed to remove orphaned attachment: ", err)
+	}
+
+	if thumbnailKey != nil {
+		if err := storage.Delete(ctx, *thumbnailKey); err != nil {
+			logrus.WithContext(ctx).WithField("key", *thumbnailKey).Warn("Failed to remove orphaned thumbnail: ", err)
+		}
+	}
+}
+
+func sanitizeFileName(name string) string {
+	name = unsafeFileNameChars.ReplaceAllString(path.Base(strings.ReplaceAll(name, "\\", "/")), "_")
+	name = strings.Trim(name, "._")
+	if name == "" {
+		return "file"
+	}
+
+	if len(name) > 100 {
+		name = name[len(name)-100:]
+	}
+
+	return name
+}
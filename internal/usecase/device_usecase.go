@@ -2,7 +2,6 @@ package usecase
 
 import (
 	"context"
-	"errors"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -19,22 +18,22 @@ func NewDeviceUsecase(deviceRepo model.IDeviceRepository) model.IDeviceUsecase {
 	}
 }
 
-func (d *DeviceUsecase) FindAll(ctx context.Context, device model.Device) ([]*model.Device, error) {
-	log := logrus.WithFields(logrus.Fields{
+func (d *DeviceUsecase) FindAll(ctx context.Context, device model.Device, page model.PageRequest) ([]*model.Device, *model.PageMeta, error) {
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
 		"filter": device,
 	})
 
-	devices, err := d.deviceRepo.FindAll(ctx, device)
+	devices, meta, err := d.deviceRepo.FindAll(ctx, device, page)
 	if err != nil {
 		log.Error("Failed to fetch devices: ", err)
-		return nil, err
+		return nil, nil, err
 	}
 
-	return devices, nil
+	return devices, meta, nil
 }
 
 func (d *DeviceUsecase) FindByID(ctx context.Context, id int64) (*model.Device, error) {
-	log := logrus.WithFields(logrus.Fields{
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
 		"id": id,
 	})
 
@@ -45,19 +44,19 @@ func (d *DeviceUsecase) FindByID(ctx context.Context, id int64) (*model.Device,
 	}
 
 	if device == nil {
-		return nil, errors.New("device not found")
+		return nil, model.NewNotFoundError("device not found")
 	}
 
 	return device, nil
 }
 
 func (d *DeviceUsecase) Create(ctx context.Context, in model.CreateDeviceInput) (*model.Device, error) {
-	log := logrus.WithFields(logrus.Fields{
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
 		"in": in,
 	})
 
-	if !isAdmin(ctx) {
-		return nil, errors.New("forbidden: admin only")
+	if !hasPermission(ctx, model.PermissionMasterDataWrite) {
+		return nil, errForbidden(model.PermissionMasterDataWrite)
 	}
 
 	if err := v.StructCtx(ctx, in); err != nil {
@@ -79,20 +78,20 @@ func (d *DeviceUsecase) Create(ctx context.Context, in model.CreateDeviceInput)
 
 	if createdDevice == nil {
 		log.Error("Device not created")
-		return nil, errors.New("device not created")
+		return nil, model.NewInternalError("device not created")
 	}
 
 	return createdDevice, nil
 }
 
 func (d *DeviceUsecase) Update(ctx context.Context, id int64, in model.UpdateDeviceInput) error {
-	log := logrus.WithFields(logrus.Fields{
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
 		"id": id,
 		"in": in,
 	})
 
-	if !isAdmin(ctx) {
-		return errors.New("forbidden: admin only")
+	if !hasPermission(ctx, model.PermissionMasterDataWrite) {
+		return errForbidden(model.PermissionMasterDataWrite)
 	}
 
 	if err := v.StructCtx(ctx, in); err != nil {
@@ -108,7 +107,7 @@ func (d *DeviceUsecase) Update(ctx context.Context, id int64, in model.UpdateDev
 
 	if existingDevice == nil || (existingDevice.DeletedAt != nil && !existingDevice.DeletedAt.IsZero()) {
 		log.Error("Device is deleted or does not exist")
-		return errors.New("device is deleted or does not exist")
+		return model.NewNotFoundError("device is deleted or does not exist")
 	}
 
 	device := model.Device{
@@ -126,32 +125,71 @@ func (d *DeviceUsecase) Update(ctx context.Context, id int64, in model.UpdateDev
 	return nil
 }
 
-func (d *DeviceUsecase) Delete(ctx context.Context, id int64) error {
-	log := logrus.WithFields(logrus.Fields{
+// Delete refuses while open tickets still use the device, unless replaceWith
+// names another device to move them to.
+func (d *DeviceUsecase) Delete(ctx context.Context, id int64, replaceWith *int64) error {
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
 		"id": id,
 	})
 
-	if !isAdmin(ctx) {
-		return errors.New("forbidden: admin only")
+	if !hasPermission(ctx, model.PermissionMasterDataWrite) {
+		return errForbidden(model.PermissionMasterDataWrite)
 	}
 
-	device, err := d.deviceRepo.FindByID(ctx, id)
+	moved, err := d.deviceRepo.Delete(ctx, id, replaceWith)
 	if err != nil {
-		log.Error("Failed to fetch device for deletion: ", err)
+		log.Error("Failed to delete device: ", err)
 		return err
 	}
 
-	if device == nil {
-		log.Error("Device not found")
-		return errors.New("device not found")
+	if replaceWith != nil {
+		log.WithFields(logrus.Fields{
+			"replace_with": *replaceWith,
+			"tickets":      moved,
+		}).Info("Reassigned open tickets before delete")
 	}
 
-	err = d.deviceRepo.Delete(ctx, id)
+	log.Info("Successfully deleted device with ID: ", id)
+	return nil
+}
+
+func (d *DeviceUsecase) FindTrash(ctx context.Context, page model.PageRequest) ([]*model.Device, *model.PageMeta, error) {
+	if !hasPermission(ctx, model.PermissionMasterDataWrite) {
+		return nil, nil, errForbidden(model.PermissionMasterDataWrite)
+	}
+
+	devices, meta, err := d.deviceRepo.FindTrash(ctx, page)
 	if err != nil {
-		log.Error("Failed to delete device: ", err)
+		logrus.WithContext(ctx).Error("Failed to fetch deleted devices: ", err)
+		return nil, nil, err
+	}
+
+	return devices, meta, nil
+}
+
+func (d *DeviceUsecase) Restore(ctx context.Context, id int64) error {
+	if !hasPermission(ctx, model.PermissionMasterDataWrite) {
+		return errForbidden(model.PermissionMasterDataWrite)
+	}
+
+	if err := d.deviceRepo.Restore(ctx, id); err != nil {
+		logrus.WithContext(ctx).WithField("id", id).Error("Failed to restore device: ", err)
 		return err
 	}
 
-	log.Info("Successfully deleted device with ID: ", id)
+	return nil
+}
+
+func (d *DeviceUsecase) Purge(ctx context.Context, id int64) error {
+	if !hasPermission(ctx, model.PermissionTrashPurge) {
+		return errForbidden(model.PermissionTrashPurge)
+	}
+
+	if err := d.deviceRepo.Purge(ctx, id); err != nil {
+		logrus.WithContext(ctx).WithField("id", id).Error("Failed to purge device: ", err)
+		return err
+	}
+
+	logrus.WithContext(ctx).WithField("id", id).Info("Purged device")
 	return nil
 }
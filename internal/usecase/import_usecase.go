@@ -0,0 +1,331 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/config"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/helper"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"github.com/xuri/excelize/v2"
+)
+
+type ImportUsecase struct {
+	importRepo model.IImportRepository
+	roleRepo   model.IRoleRepository
+	userRepo   model.IUserRepository
+}
+
+func NewImportUsecase(importRepo model.IImportRepository, roleRepo model.IRoleRepository, userRepo model.IUserRepository) model.IImportUsecase {
+	return &ImportUsecase{
+		importRepo: importRepo,
+		roleRepo:   roleRepo,
+		userRepo:   userRepo,
+	}
+}
+
+// Import validates every row first and only writes the valid ones. When a
+// row is invalid the valid rows are still run, so the report shows what
+// they would do, but nothing is committed.
+func (u *ImportUsecase) Import(ctx context.Context, in model.ImportInput) (*model.ImportReport, error) {
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
+		"entity":    in.Entity,
+		"file_name": in.FileName,
+		"dry_run":   in.DryRun,
+	})
+
+	if err := v.StructCtx(ctx, in); err != nil {
+		return nil, err
+	}
+
+	permission := model.PermissionMasterDataWrite
+	if in.Entity == model.ImportEntityUser {
+		permission = model.PermissionUserWrite
+	}
+	if !hasPermission(ctx, permission) {
+		return nil, errForbidden(permission)
+	}
+
+	records, err := readImportFile(in.FileName, in.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) < 2 {
+		return nil, model.NewFieldError("file", "required", "file has no rows below the header")
+	}
+	if rows := len(records) - 1; rows > config.ImportMaxRows() {
+		return nil, model.NewFieldError("file", "max", fmt.Sprintf("file has %d rows, max %d", rows, config.ImportMaxRows()))
+	}
+
+	var results []*model.ImportRowResult
+	switch in.Entity {
+	case model.ImportEntityProject:
+		results, err = importRows(ctx, records, in.DryRun, func(row *model.ImportProjectRow) string { return row.Name }, nil, u.importRepo.UpsertProjects)
+	case model.ImportEntityLocation:
+		results, err = importRows(ctx, records, in.DryRun, func(row *model.ImportLocationRow) string { return row.CodeName }, nil, u.importRepo.UpsertLocations)
+	case model.ImportEntityDevice:
+		results, err = importRows(ctx, records, in.DryRun, func(row *model.ImportDeviceRow) string { return row.Name }, nil, u.importRepo.UpsertDevices)
+	case model.ImportEntityWorkType:
+		results, err = importRows(ctx, records, in.DryRun, func(row *model.ImportWorkTypeRow) string { return row.Name }, nil, u.importRepo.UpsertWorkTypes)
+	case model.ImportEntityUser:
+		results, err = importRows(ctx, records, in.DryRun, func(row *model.ImportUserRow) string { return row.CodeName }, u.prepareUserRow(), u.importRepo.UpsertUsers)
+	}
+	if err != nil {
+		log.Error("Failed to import: ", err)
+		return nil, err
+	}
+
+	report := &model.ImportReport{
+		Entity: in.Entity,
+		DryRun: in.DryRun,
+		Total:  len(results),
+		Rows:   results,
+	}
+	for _, result := range results {
+		switch result.Action {
+		case model.ImportActionCreate:
+			report.Created++
+		case model.ImportActionUpdate:
+			report.Updated++
+		case model.ImportActionUnchanged:
+			report.Unchanged++
+		default:
+			report.Failed++
+		}
+	}
+	report.Committed = !in.DryRun && report.Failed == 0
+
+	log.WithFields(logrus.Fields{
+		"committed": report.Committed,
+		"created":   report.Created,
+		"updated":   report.Updated,
+		"failed":    report.Failed,
+	}).Info("Import finished")
+
+	return report, nil
+}
+
+// prepareUserRow checks the role and password policy and replaces the
+// password with its hash. Roles are looked up once per import. The caller
+// must be allowed to assign the role, and for an existing user whose role
+// changes the current role as well, the same as when editing a user.
+// Existing users keep their password, it is changed by the user or through
+// a password reset.
+func (u *ImportUsecase) prepareUserRow() func(ctx context.Context, row *model.ImportUserRow) error {
+	roles := map[string]error{}
+
+	checkRole := func(ctx context.Context, field string, name string) error {
+		err, checked := roles[name]
+		if !checked {
+			var role *model.Role
+			role, err = u.roleRepo.FindByName(ctx, name)
+			if errors.Is(err, model.ErrNotFound) {
+				err = model.NewFieldError(field, "exists", fmt.Sprintf("role %s does not exist", name))
+			} else if err == nil {
+				if checkRoleAssignment(ctx, role) != nil {
+					err = model.NewFieldError(field, "role_manage", fmt.Sprintf("%s permission required to assign role %s", model.PermissionRoleManage, name))
+				}
+			}
+			roles[name] = err
+		}
+		return err
+	}
+
+	return func(ctx context.Context, row *model.ImportUserRow) error {
+		if err := checkRole(ctx, "role", row.Role); err != nil {
+			return err
+		}
+
+		existing, err := u.userRepo.FindByCodeName(ctx, row.CodeName)
+		if err != nil && !errors.Is(err, model.ErrNotFound) {
+			return err
+		}
+
+		if existing != nil {
+			if row.Password != "" {
+				return model.NewFieldError("password", "excluded", "the password of an existing user cannot be changed by import, issue a password reset")
+			}
+
+			if existing.Role != row.Role {
+				return checkRole(ctx, "role", existing.Role)
+			}
+
+			return nil
+		}
+
+		if row.Password == "" {
+			return nil
+		}
+
+		if err := helper.ValidatePassword(row.Password); err != nil {
+			return model.NewFieldError("password", "password", err.Error())
+		}
+
+		row.Password, err = helper.HashRequestPassword(row.Password)
+		return err
+	}
+}
+
+// importRows decodes the records below the header into rows, rejects the
+// invalid ones and upserts the rest. Keys are compared case-insensitively to
+// catch a record that appears twice in the file.
+func importRows[T any](
+	ctx context.Context,
+	records [][]string,
+	dryRun bool,
+	keyOf func(row *T) string,
+	prepare func(ctx context.Context, row *T) error,
+	upsert func(ctx context.Context, items []model.ImportItem[T], rollback bool) ([]*model.ImportRowResult, error),
+) ([]*model.ImportRowResult, error) {
+	columns, err := importColumns(reflect.TypeFor[T](), records[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		rejected []*model.ImportRowResult
+		items    []model.ImportItem[T]
+		seen     = map[string]int{}
+	)
+
+	for i, record := range records[1:] {
+		rowNumber := i + 2
+		if isBlankRecord(record) {
+			continue
+		}
+
+		var row T
+		value := reflect.ValueOf(&row).Elem()
+		for field, column := range columns {
+			if column < len(record) {
+				value.Field(field).SetString(strings.TrimSpace(record[column]))
+			}
+		}
+
+		key := keyOf(&row)
+		err := v.StructCtx(ctx, row)
+		if err == nil {
+			if first, ok := seen[strings.ToLower(key)]; ok {
+				err = model.NewValidationError("duplicate of row %d", first)
+			}
+		}
+		if err == nil && prepare != nil {
+			err = prepare(ctx, &row)
+		}
+
+		if err != nil {
+			var (
+				domainErr *model.DomainError
+				fieldErrs validator.ValidationErrors
+			)
+			if !errors.As(err, &domainErr) && !errors.As(err, &fieldErrs) {
+				return nil, err
+			}
+
+			rejected = append(rejected, &model.ImportRowResult{
+				Row:    rowNumber,
+				Key:    key,
+				Action: model.ImportActionError,
+				Errors: model.RowErrors(err),
+			})
+			continue
+		}
+
+		seen[strings.ToLower(key)] = rowNumber
+		items = append(items, model.ImportItem[T]{Row: rowNumber, Value: row})
+	}
+
+	results := rejected
+	if len(items) > 0 {
+		upserted, err := upsert(ctx, items, dryRun || len(rejected) > 0)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, upserted...)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Row < results[j].Row
+	})
+
+	return results, nil
+}
+
+// importColumns maps the string fields of a row type to the header column
+// with their json name. Columns of required fields must be present, other
+// columns of the file are ignored.
+func importColumns(t reflect.Type, header []string) (map[int]int, error) {
+	positions := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		positions[strings.ReplaceAll(name, " ", "_")] = i
+	}
+
+	columns := map[int]int{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+
+		position, ok := positions[name]
+		if !ok {
+			if strings.Contains(","+field.Tag.Get("validate")+",", ",required,") {
+				return nil, model.NewFieldError("file", "columns", fmt.Sprintf("missing column %s", name))
+			}
+			continue
+		}
+
+		columns[i] = position
+	}
+
+	return columns, nil
+}
+
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// readImportFile returns the rows of a CSV file or of the first sheet of an
+// XLSX workbook, the format being picked from the file extension.
+func readImportFile(fileName string, data []byte) ([][]string, error) {
+	switch strings.TrimPrefix(strings.ToLower(path.Ext(fileName)), ".") {
+	case model.ImportFormatCSV:
+		reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+		reader.FieldsPerRecord = -1
+
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, model.NewFieldError("file", "csv", fmt.Sprintf("invalid CSV: %v", err))
+		}
+		return records, nil
+	case model.ImportFormatXLSX:
+		workbook, err := excelize.OpenReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, model.NewFieldError("file", "xlsx", fmt.Sprintf("invalid XLSX: %v", err))
+		}
+		defer workbook.Close()
+
+		records, err := workbook.GetRows(workbook.GetSheetName(0))
+		if err != nil {
+			return nil, model.NewFieldError("file", "xlsx", fmt.Sprintf("invalid XLSX: %v", err))
+		}
+		return records, nil
+	default:
+		return nil, model.NewFieldError("file", "oneof", "file must be a .csv or .xlsx file")
+	}
+}
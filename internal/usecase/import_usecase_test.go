@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"bytes"
+	"errors"
+	"maps"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestReadImportFile(t *testing.T) {
+	workbook := excelize.NewFile()
+	_ = workbook.SetSheetRow(workbook.GetSheetName(0), "A1", &[]interface{}{"name", "code_name"})
+	_ = workbook.SetSheetRow(workbook.GetSheetName(0), "A2", &[]interface{}{"Station One", "ST1"})
+	var xlsx bytes.Buffer
+	if err := workbook.Write(&xlsx); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		fileName string
+		data     []byte
+		want     [][]string
+		wantRule string
+	}{
+		{
+			name:     "csv",
+			fileName: "locations.csv",
+			data:     []byte("name,code_name\nStation One,ST1\n"),
+			want:     [][]string{{"name", "code_name"}, {"Station One", "ST1"}},
+		},
+		{
+			name:     "csv with a byte order mark and ragged rows",
+			fileName: "LOCATIONS.CSV",
+			data:     []byte("\xef\xbb\xbfname,code_name\nStation One\n"),
+			want:     [][]string{{"name", "code_name"}, {"Station One"}},
+		},
+		{
+			name:     "xlsx",
+			fileName: "locations.xlsx",
+			data:     xlsx.Bytes(),
+			want:     [][]string{{"name", "code_name"}, {"Station One", "ST1"}},
+		},
+		{
+			name:     "invalid csv",
+			fileName: "locations.csv",
+			data:     []byte("name\n\"Station One\n"),
+			wantRule: "csv",
+		},
+		{
+			name:     "invalid xlsx",
+			fileName: "locations.xlsx",
+			data:     []byte("name,code_name\n"),
+			wantRule: "xlsx",
+		},
+		{
+			name:     "unsupported extension",
+			fileName: "locations.txt",
+			data:     []byte("name\n"),
+			wantRule: "oneof",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readImportFile(tt.fileName, tt.data)
+			if tt.wantRule != "" {
+				assertFieldRule(t, err, "file", tt.wantRule)
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("readImportFile() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readImportFile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportColumns(t *testing.T) {
+	userRow := reflect.TypeFor[model.ImportUserRow]()
+
+	tests := []struct {
+		name     string
+		header   []string
+		want     map[int]int
+		wantRule string
+	}{
+		{
+			name:   "columns in any order, names normalized",
+			header: []string{" Role", "Code Name", "USERNAME", "name", "password"},
+			// Fields: Name, CodeName, Username, Password, Role.
+			want: map[int]int{0: 3, 1: 1, 2: 2, 3: 4, 4: 0},
+		},
+		{
+			name:   "optional column missing, extra column ignored",
+			header: []string{"name", "code_name", "username", "role", "notes"},
+			want:   map[int]int{0: 0, 1: 1, 2: 2, 4: 3},
+		},
+		{
+			name:     "required column missing",
+			header:   []string{"name", "code_name", "password", "role"},
+			wantRule: "columns",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := importColumns(userRow, tt.header)
+			if tt.wantRule != "" {
+				assertFieldRule(t, err, "file", tt.wantRule)
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("importColumns() error = %v", err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("importColumns() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func assertFieldRule(t *testing.T, err error, field string, rule string) {
+	t.Helper()
+
+	var domainErr *model.DomainError
+	if !errors.As(err, &domainErr) {
+		t.Fatalf("error = %v, want a domain error", err)
+	}
+
+	found := slices.ContainsFunc(domainErr.Fields, func(f model.FieldError) bool {
+		return f.Field == field && f.Rule == rule
+	})
+	if !found {
+		t.Fatalf("error fields = %+v, want %s/%s", domainErr.Fields, field, rule)
+	}
+}
@@ -2,7 +2,6 @@ package usecase
 
 import (
 	"context"
-	"errors"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -19,22 +18,22 @@ func NewLocationUsecase(locationRepo model.ILocationRepository) model.ILocationU
 	}
 }
 
-func (l *LocationUsecase) FindAll(ctx context.Context, location model.Location) ([]*model.Location, error) {
-	log := logrus.WithFields(logrus.Fields{
+func (l *LocationUsecase) FindAll(ctx context.Context, location model.Location, page model.PageRequest) ([]*model.Location, *model.PageMeta, error) {
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
 		"filter": location,
 	})
 
-	locations, err := l.locationRepo.FindAll(ctx, location)
+	locations, meta, err := l.locationRepo.FindAll(ctx, location, page)
 	if err != nil {
 		log.Error("Failed to fetch locations: ", err)
-		return nil, err
+		return nil, nil, err
 	}
 
-	return locations, nil
+	return locations, meta, nil
 }
 
 func (l *LocationUsecase) FindByID(ctx context.Context, id int64) (*model.Location, error) {
-	log := logrus.WithFields(logrus.Fields{
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
 		"id": id,
 	})
 
@@ -46,19 +45,19 @@ func (l *LocationUsecase) FindByID(ctx context.Context, id int64) (*model.Locati
 
 	if location == nil {
 		log.Error("Location not found")
-		return nil, errors.New("location not found")
+		return nil, model.NewNotFoundError("location not found")
 	}
 
 	return location, nil
 }
 
 func (l *LocationUsecase) Create(ctx context.Context, in model.CreateLocationInput) (*model.Location, error) {
-	log := logrus.WithFields(logrus.Fields{
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
 		"in": in,
 	})
 
-	if !isAdmin(ctx) {
-		return nil, errors.New("forbidden: admin only")
+	if !hasPermission(ctx, model.PermissionMasterDataWrite) {
+		return nil, errForbidden(model.PermissionMasterDataWrite)
 	}
 
 	if err := v.StructCtx(ctx, in); err != nil {
@@ -81,20 +80,20 @@ func (l *LocationUsecase) Create(ctx context.Context, in model.CreateLocationInp
 
 	if createdLocation == nil {
 		log.Error("Location not created")
-		return nil, errors.New("location not created")
+		return nil, model.NewInternalError("location not created")
 	}
 
 	return createdLocation, nil
 }
 
 func (l *LocationUsecase) Update(ctx context.Context, id int64, in model.UpdateLocationInput) error {
-	log := logrus.WithFields(logrus.Fields{
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
 		"id": id,
 		"in": in,
 	})
 
-	if !isAdmin(ctx) {
-		return errors.New("forbidden: admin only")
+	if !hasPermission(ctx, model.PermissionMasterDataWrite) {
+		return errForbidden(model.PermissionMasterDataWrite)
 	}
 
 	if err := v.StructCtx(ctx, in); err != nil {
@@ -109,7 +108,7 @@ func (l *LocationUsecase) Update(ctx context.Context, id int64, in model.UpdateL
 
 	if existingLocation == nil || (existingLocation.DeletedAt != nil && !existingLocation.DeletedAt.IsZero()) {
 		log.Error("Location is deleted or does not exist")
-		return errors.New("location is deleted or does not exist")
+		return model.NewNotFoundError("location is deleted or does not exist")
 	}
 
 	location := model.Location{
@@ -127,32 +126,71 @@ func (l *LocationUsecase) Update(ctx context.Context, id int64, in model.UpdateL
 	return nil
 }
 
-func (l *LocationUsecase) Delete(ctx context.Context, id int64) error {
-	log := logrus.WithFields(logrus.Fields{
+// Delete refuses while open tickets still use the location, unless replaceWith
+// names another location to move them to.
+func (l *LocationUsecase) Delete(ctx context.Context, id int64, replaceWith *int64) error {
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
 		"id": id,
 	})
 
-	if !isAdmin(ctx) {
-		return errors.New("forbidden: admin only")
+	if !hasPermission(ctx, model.PermissionMasterDataWrite) {
+		return errForbidden(model.PermissionMasterDataWrite)
 	}
 
-	location, err := l.locationRepo.FindByID(ctx, id)
+	moved, err := l.locationRepo.Delete(ctx, id, replaceWith)
 	if err != nil {
-		log.Error("Failed to fetch location for deletion: ", err)
+		log.Error("Failed to delete location: ", err)
 		return err
 	}
 
-	if location == nil {
-		log.Error("Location not found")
-		return errors.New("location not found")
+	if replaceWith != nil {
+		log.WithFields(logrus.Fields{
+			"replace_with": *replaceWith,
+			"tickets":      moved,
+		}).Info("Reassigned open tickets before delete")
+	}
+
+	log.Info("Successfully deleted location with ID: ", id)
+	return nil
+}
+
+func (l *LocationUsecase) FindTrash(ctx context.Context, page model.PageRequest) ([]*model.Location, *model.PageMeta, error) {
+	if !hasPermission(ctx, model.PermissionMasterDataWrite) {
+		return nil, nil, errForbidden(model.PermissionMasterDataWrite)
 	}
 
-	err = l.locationRepo.Delete(ctx, id)
+	locations, meta, err := l.locationRepo.FindTrash(ctx, page)
 	if err != nil {
-		log.Error("Failed to delete location: ", err)
+		logrus.WithContext(ctx).Error("Failed to fetch deleted locations: ", err)
+		return nil, nil, err
+	}
+
+	return locations, meta, nil
+}
+
+func (l *LocationUsecase) Restore(ctx context.Context, id int64) error {
+	if !hasPermission(ctx, model.PermissionMasterDataWrite) {
+		return errForbidden(model.PermissionMasterDataWrite)
+	}
+
+	if err := l.locationRepo.Restore(ctx, id); err != nil {
+		logrus.WithContext(ctx).WithField("id", id).Error("Failed to restore location: ", err)
 		return err
 	}
 
-	log.Info("Successfully deleted location with ID: ", id)
+	return nil
+}
+
+func (l *LocationUsecase) Purge(ctx context.Context, id int64) error {
+	if !hasPermission(ctx, model.PermissionTrashPurge) {
+		return errForbidden(model.PermissionTrashPurge)
+	}
+
+	if err := l.locationRepo.Purge(ctx, id); err != nil {
+		logrus.WithContext(ctx).WithField("id", id).Error("Failed to purge location: ", err)
+		return err
+	}
+
+	logrus.WithContext(ctx).WithField("id", id).Info("Purged location")
 	return nil
 }
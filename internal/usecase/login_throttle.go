@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/config"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+)
+
+// loginThrottle tracks failed logins per username and per client IP. Each
+// failure doubles the wait before the next attempt, and reaching the limit
+// locks the key for the lockout period.
+type loginThrottle struct {
+	repo model.ILoginAttemptRepository
+}
+
+type throttleKey struct {
+	scope       string
+	key         string
+	maxFailures int
+}
+
+func (t *loginThrottle) keys(username, ip string) []throttleKey {
+	keys := []throttleKey{{
+		scope:       model.LoginAttemptScopeUsername,
+		key:         strings.ToLower(strings.TrimSpace(username)),
+		maxFailures: config.LoginMaxFailures(),
+	}}
+
+	if ip != "" {
+		keys = append(keys, throttleKey{
+			scope:       model.LoginAttemptScopeIP,
+			key:         ip,
+			maxFailures: config.LoginIPMaxFailures(),
+		})
+	}
+
+	return keys
+}
+
+// Check returns a *model.LoginThrottledError while any key is locked or
+// still inside its back-off delay.
+func (t *loginThrottle) Check(ctx context.Context, username, ip string) error {
+	now := time.Now()
+	var wait time.Duration
+
+	for _, k := range t.keys(username, ip) {
+		attempt, err := t.repo.Find(ctx, k.scope, k.key)
+		if err != nil {
+			return err
+		}
+		if attempt == nil || now.Sub(attempt.LastFailureAt) > config.LoginFailureWindow() {
+			continue
+		}
+
+		if attempt.LockedUntil != nil && attempt.LockedUntil.After(now) {
+			wait = max(wait, attempt.LockedUntil.Sub(now))
+		}
+
+		if next := attempt.LastFailureAt.Add(loginDelay(attempt.Failures)); next.After(now) {
+			wait = max(wait, next.Sub(now))
+		}
+	}
+
+	if wait > 0 {
+		return &model.LoginThrottledError{RetryAfter: wait}
+	}
+
+	return nil
+}
+
+func (t *loginThrottle) Failure(ctx context.Context, username, ip string) {
+	resetBefore := time.Now().Add(-config.LoginFailureWindow())
+
+	for _, k := range t.keys(username, ip) {
+		log := logrus.WithContext(ctx).WithFields(logrus.Fields{
+			"scope": k.scope,
+			"key":   k.key,
+		})
+
+		attempt, err := t.repo.RecordFailure(ctx, k.scope, k.key, resetBefore)
+		if err != nil {
+			log.Error("Failed to record login failure: ", err)
+			continue
+		}
+
+		if attempt.Failures >= k.maxFailures && (attempt.LockedUntil == nil || !attempt.LockedUntil.After(time.Now())) {
+			log.Warn("Too many failed logins, locking")
+			if err := t.repo.Lock(ctx, k.scope, k.key, time.Now().Add(config.LoginLockout())); err != nil {
+				log.Error("Failed to lock login: ", err)
+			}
+		}
+	}
+}
+
+func (t *loginThrottle) Success(ctx context.Context, username string) {
+	key := strings.ToLower(strings.TrimSpace(username))
+	if err := t.repo.Reset(ctx, model.LoginAttemptScopeUsername, key); err != nil {
+		logrus.WithContext(ctx).WithField("username", key).Error("Failed to reset login attempts: ", err)
+	}
+}
+
+func (t *loginThrottle) Unlock(ctx context.Context, username string) error {
+	return t.repo.Reset(ctx, model.LoginAttemptScopeUsername, strings.ToLower(strings.TrimSpace(username)))
+}
+
+// loginDelay is the wait after the given number of consecutive failures:
+// nothing after the first, then doubling up to the configured maximum.
+func loginDelay(failures int) time.Duration {
+	if failures <= 1 {
+		return 0
+	}
+
+	delay := config.LoginDelayBase()
+	for i := 2; i < failures && delay < config.LoginDelayMax(); i++ {
+		delay *= 2
+	}
+
+	return min(delay, config.LoginDelayMax())
+}
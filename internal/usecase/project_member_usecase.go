@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+)
+
+type ProjectMemberUsecase struct {
+	memberRepo  model.IProjectMemberRepository
+	projectRepo model.IProjectRepository
+	userRepo    model.IUserRepository
+}
+
+func NewProjectMemberUsecase(
+	memberRepo model.IProjectMemberRepository,
+	projectRepo model.IProjectRepository,
+	userRepo model.IUserRepository,
+) model.IProjectMemberUsecase {
+	return &ProjectMemberUsecase{
+		memberRepo:  memberRepo,
+		projectRepo: projectRepo,
+		userRepo:    userRepo,
+	}
+}
+
+func (p *ProjectMemberUsecase) FindMembers(ctx context.Context, projectID int64) ([]*model.User, error) {
+	users, err := p.memberRepo.FindMembers(ctx, projectID)
+	if err != nil {
+		logrus.WithContext(ctx).WithField("project_id", projectID).Error("Failed to fetch project members: ", err)
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (p *ProjectMemberUsecase) Add(ctx context.Context, projectID int64, in model.ProjectMemberInput) error {
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
+		"project_id": projectID,
+		"user_id":    in.UserID,
+	})
+
+	if !hasPermission(ctx, model.PermissionUserWrite) {
+		return errForbidden(model.PermissionUserWrite)
+	}
+
+	if err := v.StructCtx(ctx, in); err != nil {
+		return err
+	}
+
+	if project, err := p.projectRepo.FindByID(ctx, projectID); err != nil || project == nil {
+		return model.NewNotFoundError("project not found")
+	}
+
+	if user, err := p.userRepo.FindByID(ctx, in.UserID); err != nil || user == nil {
+		return model.NewNotFoundError("user not found")
+	}
+
+	if err := p.memberRepo.Add(ctx, model.ProjectMember{ProjectID: projectID, UserID: in.UserID}); err != nil {
+		log.Error("Failed to add project member: ", err)
+		return err
+	}
+
+	log.Info("Project member added")
+	return nil
+}
+
+func (p *ProjectMemberUsecase) Remove(ctx context.Context, projectID int64, userID int64) error {
+	if !hasPermission(ctx, model.PermissionUserWrite) {
+		return errForbidden(model.PermissionUserWrite)
+	}
+
+	if err := p.memberRepo.Remove(ctx, projectID, userID); err != nil {
+		logrus.WithContext(ctx).WithFields(logrus.Fields{
+			"project_id": projectID,
+			"user_id":    userID,
+		}).Error("Failed to remove project member: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// ResolveScope returns the projects the caller may see: all of them with
+// the project:all permission, otherwise the ones they are a member of.
+func (p *ProjectMemberUsecase) ResolveScope(ctx context.Context, claims *model.CustomClaims) (*model.ProjectScope, error) {
+	if claims.HasPermission(model.PermissionProjectAll) {
+		return &model.ProjectScope{All: true}, nil
+	}
+
+	ids, err := p.memberRepo.FindProjectIDsByUser(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.Sort(ids)
+	return &model.ProjectScope{ProjectIDs: ids}, nil
+}
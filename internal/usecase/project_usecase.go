@@ -2,7 +2,6 @@ package usecase
 
 import (
 	"context"
-	"errors"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -19,22 +18,22 @@ func NewProjectUsecase(projectRepo model.IProjectRepository) model.IProjectUseca
 	}
 }
 
-func (p *ProjectUsecase) FindAll(ctx context.Context, project model.Project) ([]*model.Project, error) {
-	log := logrus.WithFields(logrus.Fields{
+func (p *ProjectUsecase) FindAll(ctx context.Context, project model.Project, page model.PageRequest) ([]*model.Project, *model.PageMeta, error) {
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
 		"filter": project,
 	})
 
-	projects, err := p.projectRepo.FindAll(ctx, project)
+	projects, meta, err := p.projectRepo.FindAll(ctx, project, page)
 	if err != nil {
 		log.Error("Failed to fetch projects: ", err)
-		return nil, err
+		return nil, nil, err
 	}
 
-	return projects, nil
+	return projects, meta, nil
 }
 
 func (p *ProjectUsecase) FindByID(ctx context.Context, id int64) (*model.Project, error) {
-	log := logrus.WithFields(logrus.Fields{
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
 		"id": id,
 	})
 
@@ -46,19 +45,19 @@ func (p *ProjectUsecase) FindByID(ctx context.Context, id int64) (*model.Project
 
 	if project == nil {
 		log.Error("Project not found")
-		return nil, errors.New("project not found")
+		return nil, model.NewNotFoundError("project not found")
 	}
 
 	return project, nil
 }
 
 func (p *ProjectUsecase) Create(ctx context.Context, in model.CreateProjectInput) (*model.Project, error) {
-	log := logrus.WithFields(logrus.Fields{
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
 		"in": in,
 	})
 
-	if !isAdmin(ctx) {
-		return nil, errors.New("forbidden: admin only")
+	if !hasPermission(ctx, model.PermissionMasterDataWrite) {
+		return nil, errForbidden(model.PermissionMasterDataWrite)
 	}
 
 	err := v.StructCtx(ctx, in)
@@ -81,19 +80,19 @@ func (p *ProjectUsecase) Create(ctx context.Context, in model.CreateProjectInput
 
 	if createdProject == nil {
 		log.Error("Project not created")
-		return nil, errors.New("project not created")
+		return nil, model.NewInternalError("project not created")
 	}
 
 	return createdProject, nil
 }
 
 func (p *ProjectUsecase) Update(ctx context.Context, id int64, in model.UpdateProjectInput) error {
-	log := logrus.WithFields(logrus.Fields{
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
 		"in": in,
 	})
 
-	if !isAdmin(ctx) {
-		return errors.New("forbidden: admin only")
+	if !hasPermission(ctx, model.PermissionMasterDataWrite) {
+		return errForbidden(model.PermissionMasterDataWrite)
 	}
 
 	err := v.StructCtx(ctx, in)
@@ -110,7 +109,7 @@ func (p *ProjectUsecase) Update(ctx context.Context, id int64, in model.UpdatePr
 
 	if existingProject == nil || (existingProject.DeletedAt != nil && !existingProject.DeletedAt.IsZero()) {
 		log.Error("Project is deleted or does not exist")
-		return errors.New("project is deleted or does not exist")
+		return model.NewNotFoundError("project is deleted or does not exist")
 	}
 
 	project := model.Project{
@@ -128,33 +127,72 @@ func (p *ProjectUsecase) Update(ctx context.Context, id int64, in model.UpdatePr
 	return nil
 }
 
-func (p *ProjectUsecase) Delete(ctx context.Context, id int64) error {
-	log := logrus.WithFields(logrus.Fields{
+// Delete refuses while open tickets still use the project, unless replaceWith
+// names another project to move them to.
+func (p *ProjectUsecase) Delete(ctx context.Context, id int64, replaceWith *int64) error {
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
 		"id": id,
 	})
 
-	if !isAdmin(ctx) {
-		return errors.New("forbidden: admin only")
+	if !hasPermission(ctx, model.PermissionMasterDataWrite) {
+		return errForbidden(model.PermissionMasterDataWrite)
 	}
 
-	project, err := p.projectRepo.FindByID(ctx, id)
+	moved, err := p.projectRepo.Delete(ctx, id, replaceWith)
 	if err != nil {
-		log.Error("Failed to fetch project for deletion: ", err)
+		log.Error("Failed to delete project: ", err)
 		return err
 	}
 
-	if project == nil {
-		log.Error("Project not found")
-		return errors.New("project not found")
+	if replaceWith != nil {
+		log.WithFields(logrus.Fields{
+			"replace_with": *replaceWith,
+			"tickets":      moved,
+		}).Info("Reassigned open tickets before delete")
+	}
+
+	log.Info("Successfully deleted project with ID: ", id)
+
+	return nil
+}
+
+func (p *ProjectUsecase) FindTrash(ctx context.Context, page model.PageRequest) ([]*model.Project, *model.PageMeta, error) {
+	if !hasPermission(ctx, model.PermissionMasterDataWrite) {
+		return nil, nil, errForbidden(model.PermissionMasterDataWrite)
 	}
 
-	err = p.projectRepo.Delete(ctx, id)
+	projects, meta, err := p.projectRepo.FindTrash(ctx, page)
 	if err != nil {
-		log.Error("Failed to delete project: ", err)
+		logrus.WithContext(ctx).Error("Failed to fetch deleted projects: ", err)
+		return nil, nil, err
+	}
+
+	return projects, meta, nil
+}
+
+func (p *ProjectUsecase) Restore(ctx context.Context, id int64) error {
+	if !hasPermission(ctx, model.PermissionMasterDataWrite) {
+		return errForbidden(model.PermissionMasterDataWrite)
+	}
+
+	if err := p.projectRepo.Restore(ctx, id); err != nil {
+		logrus.WithContext(ctx).WithField("id", id).Error("Failed to restore project: ", err)
 		return err
 	}
 
-	log.Info("Successfully deleted project with ID: ", id)
+	return nil
+}
+
+func (p *ProjectUsecase) Purge(ctx context.Context, id int64) error {
+	if !hasPermission(ctx, model.PermissionTrashPurge) {
+		return errForbidden(model.PermissionTrashPurge)
+	}
+
+	if err := p.projectRepo.Purge(ctx, id); err != nil {
+		logrus.WithContext(ctx).WithField("id", id).Error("Failed to purge project: ", err)
+		return err
+	}
 
+	logrus.WithContext(ctx).WithField("id", id).Info("Purged project")
 	return nil
 }
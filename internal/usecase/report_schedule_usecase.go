@@ -0,0 +1,511 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/config"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+)
+
+const defaultReportTemplate = `*Rekap Troubleshooting {{.ProjectName}}*
+{{.PeriodStart.Format "02 Jan 2006 15:04"}} - {{.PeriodEnd.Format "02 Jan 2006 15:04"}}
+{{range .Stations}}
+- {{.LocationName}}: baru {{.New}}, selesai {{.Closed}}, open {{.Open}}{{end}}
+
+Total: baru {{.TotalNew}}, selesai {{.TotalClosed}}, open {{.TotalOpen}}`
+
+type ReportScheduleUsecase struct {
+	scheduleRepo model.IReportScheduleRepository
+	projectRepo  model.IProjectRepository
+	sender       model.IMessageSender
+
+	mu      sync.Mutex
+	cron    *cron.Cron
+	entries []cron.EntryID
+	// loaded identifies the schedules the entries were built from, so the
+	// periodic reload only rebuilds them when a schedule changed.
+	loaded string
+}
+
+func NewReportScheduleUsecase(
+	scheduleRepo model.IReportScheduleRepository,
+	projectRepo model.IProjectRepository,
+	sender model.IMessageSender,
+) model.IReportScheduleUsecase {
+	return &ReportScheduleUsecase{
+		scheduleRepo: scheduleRepo,
+		projectRepo:  projectRepo,
+		sender:       sender,
+	}
+}
+
+func (r *ReportScheduleUsecase) FindAll(ctx context.Context) ([]*model.ReportSchedule, error) {
+	if !hasPermission(ctx, model.PermissionReportManage) {
+		return nil, errForbidden(model.PermissionReportManage)
+	}
+
+	return r.scheduleRepo.FindAll(ctx)
+}
+
+func (r *ReportScheduleUsecase) FindByID(ctx context.Context, id int64) (*model.ReportSchedule, error) {
+	if !hasPermission(ctx, model.PermissionReportManage) {
+		return nil, errForbidden(model.PermissionReportManage)
+	}
+
+	return r.scheduleRepo.FindByID(ctx, id)
+}
+
+func (r *ReportScheduleUsecase) Create(ctx context.Context, in model.ReportScheduleInput) (*model.ReportSchedule, error) {
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
+		"in": in,
+	})
+
+	if !hasPermission(ctx, model.PermissionReportManage) {
+		return nil, errForbidden(model.PermissionReportManage)
+	}
+
+	schedule, err := r.scheduleFromInput(ctx, in)
+	if err != nil {
+		log.Error("Validation error: ", err)
+		return nil, err
+	}
+
+	created, err := r.scheduleRepo.Create(ctx, schedule)
+	if err != nil {
+		log.Error("Failed to create report schedule: ", err)
+		return nil, err
+	}
+
+	r.reload()
+	return created, nil
+}
+
+func (r *ReportScheduleUsecase) Update(ctx context.Context, id int64, in model.ReportScheduleInput) error {
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
+		"id": id,
+		"in": in,
+	})
+
+	if !hasPermission(ctx, model.PermissionReportManage) {
+		return errForbidden(model.PermissionReportManage)
+	}
+
+	if _, err := r.scheduleRepo.FindByID(ctx, id); err != nil {
+		return err
+	}
+
+	schedule, err := r.scheduleFromInput(ctx, in)
+	if err != nil {
+		log.Error("Validation error: ", err)
+		return err
+	}
+
+	schedule.ID = id
+	if err := r.scheduleRepo.Update(ctx, schedule); err != nil {
+		log.Error("Failed to update report schedule: ", err)
+		return err
+	}
+
+	r.reload()
+	return nil
+}
+
+func (r *ReportScheduleUsecase) Delete(ctx context.Context, id int64) error {
+	if !hasPermission(ctx, model.PermissionReportManage) {
+		return errForbidden(model.PermissionReportManage)
+	}
+
+	if _, err := r.scheduleRepo.FindByID(ctx, id); err != nil {
+		return err
+	}
+
+	if err := r.scheduleRepo.Delete(ctx, id); err != nil {
+		logrus.WithContext(ctx).WithField("id", id).Error("Failed to delete report schedule: ", err)
+		return err
+	}
+
+	r.reload()
+	return nil
+}
+
+func (r *ReportScheduleUsecase) FindDeliveries(ctx context.Context, scheduleID int64) ([]*model.ReportDelivery, error) {
+	if !hasPermission(ctx, model.PermissionReportManage) {
+		return nil, errForbidden(model.PermissionReportManage)
+	}
+
+	if _, err := r.scheduleRepo.FindByID(ctx, scheduleID); err != nil {
+		return nil, err
+	}
+
+	return r.scheduleRepo.FindDeliveries(ctx, scheduleID, 100)
+}
+
+// Run renders and sends the report for the period ending at periodEnd. It
+// is what the scheduler calls, and lets an admin send a missed report.
+func (r *ReportScheduleUsecase) Run(ctx context.Context, id int64, periodEnd time.Time) (*model.ReportDelivery, error) {
+	if !hasPermission(ctx, model.PermissionReportManage) {
+		return nil, errForbidden(model.PermissionReportManage)
+	}
+
+	return r.run(ctx, id, periodEnd)
+}
+
+func (r *ReportScheduleUsecase) Resend(ctx context.Context, deliveryID int64) (*model.ReportDelivery, error) {
+	if !hasPermission(ctx, model.PermissionReportManage) {
+		return nil, errForbidden(model.PermissionReportManage)
+	}
+
+	delivery, err := r.scheduleRepo.FindDeliveryByID(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The schedule lookup is limited to the caller's projects.
+	if _, err := r.scheduleRepo.FindByID(ctx, delivery.ReportScheduleID); err != nil {
+		return nil, model.NewNotFoundError("report delivery not found")
+	}
+
+	delivery.Attempts++
+	r.send(ctx, delivery)
+
+	if err := r.scheduleRepo.UpdateDelivery(ctx, *delivery); err != nil {
+		logrus.WithContext(ctx).WithField("id", deliveryID).Error("Failed to update report delivery: ", err)
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+func (r *ReportScheduleUsecase) Start(ctx context.Context) error {
+	r.mu.Lock()
+	r.cron = cron.New()
+	r.entries = nil
+	r.loaded = ""
+	r.mu.Unlock()
+
+	if err := r.reload(); err != nil {
+		return err
+	}
+
+	// Schedules changed through another instance are picked up here.
+	interval := config.ReportScheduleReloadInterval()
+	if _, err := r.cron.AddFunc(fmt.Sprintf("@every %s", interval), func() { _ = r.reload() }); err != nil {
+		return err
+	}
+
+	r.cron.Start()
+	return nil
+}
+
+// Stop waits for running reports to finish.
+func (r *ReportScheduleUsecase) Stop() {
+	r.mu.Lock()
+	c := r.cron
+	r.mu.Unlock()
+
+	if c != nil {
+		<-c.Stop().Done()
+	}
+}
+
+// reload rebuilds the cron entries from the database. It loads outside of
+// any request, the schedules of every project are run whoever changed one.
+// Nothing is rebuilt when no schedule changed since the last reload.
+func (r *ReportScheduleUsecase) reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cron == nil {
+		return nil
+	}
+
+	ctx := context.Background()
+	log := logrus.WithContext(ctx).WithField("job", "report_schedule")
+
+	schedules, err := r.scheduleRepo.FindAll(ctx)
+	if err != nil {
+		log.Error("Failed to load report schedules: ", err)
+		return err
+	}
+
+	var loaded strings.Builder
+	for _, schedule := range schedules {
+		fmt.Fprintf(&loaded, "%d@%d;", schedule.ID, schedule.UpdatedAt.UnixNano())
+	}
+	if loaded.String() == r.loaded {
+		return nil
+	}
+	r.loaded = loaded.String()
+
+	for _, entry := range r.entries {
+		r.cron.Remove(entry)
+	}
+	r.entries = nil
+
+	for _, schedule := range schedules {
+		if !schedule.Enabled {
+			continue
+		}
+
+		id := schedule.ID
+		entry, err := r.cron.AddFunc(cronSpec(schedule.CronExpression, schedule.Timezone), func() {
+			r.runScheduled(id, time.Now())
+		})
+		if err != nil {
+			log.WithField("report_schedule_id", id).Error("Invalid report schedule: ", err)
+			continue
+		}
+
+		r.entries = append(r.entries, entry)
+	}
+
+	return nil
+}
+
+// runScheduled sends the report of one cron slot. Every instance runs the
+// scheduler, so the slot is claimed in the database first and only the
+// instance that claimed it sends the report. The slot, rounded down to the
+// minute, is also the end of the report period, so all instances agree on it.
+// An instance that has not reloaded a changed schedule yet still fires on its
+// old entry, so the schedule is read again and the slot skipped unless the
+// schedule, as it is now, is enabled and due at it.
+func (r *ReportScheduleUsecase) runScheduled(id int64, firedAt time.Time) {
+	ctx := context.Background()
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
+		"job":                "report_schedule",
+		"report_schedule_id": id,
+	})
+
+	slot := firedAt.Truncate(time.Minute).UTC()
+
+	schedule, err := r.scheduleRepo.FindByID(ctx, id)
+	if errors.Is(err, model.ErrNotFound) {
+		log.Debug("Skipping scheduled report, the schedule was deleted")
+		return
+	}
+	if err != nil {
+		log.Error("Failed to load report schedule: ", err)
+		return
+	}
+	if !schedule.Enabled || !dueAt(schedule, slot) {
+		log.Debug("Skipping scheduled report, the schedule changed")
+		return
+	}
+
+	claimed, err := r.scheduleRepo.ClaimRun(ctx, id, slot)
+	if err != nil {
+		log.Error("Failed to claim scheduled report: ", err)
+		return
+	}
+	if !claimed {
+		log.Debug("Scheduled report already sent by another instance")
+		return
+	}
+
+	if _, err := r.run(ctx, id, slot); err != nil {
+		log.Error("Scheduled report failed: ", err)
+	}
+}
+
+// dueAt reports whether the schedule's cron expression and timezone fire at
+// slot.
+func dueAt(schedule *model.ReportSchedule, slot time.Time) bool {
+	spec, err := cron.ParseStandard(cronSpec(schedule.CronExpression, schedule.Timezone))
+	if err != nil {
+		return false
+	}
+
+	return spec.Next(slot.Add(-time.Second)).Equal(slot)
+}
+
+func (r *ReportScheduleUsecase) run(ctx context.Context, id int64, periodEnd time.Time) (*model.ReportDelivery, error) {
+	schedule, err := r.scheduleRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := r.projectRepo.FindByID(ctx, schedule.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	periodStart := periodEnd.AddDate(0, 0, -1)
+	switch schedule.Period {
+	case "week":
+		periodStart = periodEnd.AddDate(0, 0, -7)
+	case "month":
+		periodStart = periodEnd.AddDate(0, -1, 0)
+	}
+
+	stations, err := r.scheduleRepo.StationSummary(ctx, schedule.ProjectID, periodStart, periodEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	data := model.ReportData{
+		ProjectName: project.Name,
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+		Stations:    stations,
+	}
+
+	for _, station := range stations {
+		data.TotalNew += station.New
+		data.TotalClosed += station.Closed
+		data.TotalOpen += station.Open
+	}
+
+	message, err := renderReport(schedule.Template, data)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := &model.ReportDelivery{
+		ReportScheduleID: schedule.ID,
+		PeriodStart:      periodStart,
+		PeriodEnd:        periodEnd,
+		Target:           schedule.Target,
+		Message:          message,
+		Attempts:         1,
+	}
+
+	r.send(ctx, delivery)
+
+	// The delivery is stored even when sending failed so it can be resent.
+	return r.scheduleRepo.CreateDelivery(ctx, *delivery)
+}
+
+func (r *ReportScheduleUsecase) send(ctx context.Context, delivery *model.ReportDelivery) {
+	if err := r.sender.Send(ctx, delivery.Target, delivery.Message); err != nil {
+		logrus.WithContext(ctx).WithField("target", delivery.Target).Error("Failed to send report: ", err)
+
+		message := err.Error()
+		delivery.Status = model.ReportDeliveryFailed
+		delivery.Error = &message
+		return
+	}
+
+	now := time.Now()
+	delivery.Status = model.ReportDeliverySent
+	delivery.Error = nil
+	delivery.SentAt = &now
+}
+
+func (r *ReportScheduleUsecase) scheduleFromInput(ctx context.Context, in model.ReportScheduleInput) (model.ReportSchedule, error) {
+	if err := v.StructCtx(ctx, in); err != nil {
+		return model.ReportSchedule{}, err
+	}
+
+	schedule := model.ReportSchedule{
+		ProjectID:      in.ProjectID,
+		Name:           in.Name,
+		CronExpression: strings.TrimSpace(in.CronExpression),
+		Timezone:       in.Timezone,
+		Period:         in.Period,
+		Target:         in.Target,
+		Template:       in.Template,
+		Enabled:        in.Enabled == nil || *in.Enabled,
+	}
+
+	if schedule.Timezone == "" {
+		schedule.Timezone = "Asia/Jakarta"
+	}
+
+	if schedule.Period == "" {
+		schedule.Period = "day"
+	}
+
+	if _, err := time.LoadLocation(schedule.Timezone); err != nil {
+		return schedule, model.NewFieldError("timezone", "timezone", "invalid timezone")
+	}
+
+	if _, err := cron.ParseStandard(cronSpec(schedule.CronExpression, schedule.Timezone)); err != nil {
+		return schedule, model.NewFieldError("cron_expression", "cron", "invalid cron expression: "+err.Error())
+	}
+
+	if _, err := template.New("report").Parse(schedule.Template); err != nil {
+		return schedule, model.NewFieldError("template", "template", "invalid template: "+err.Error())
+	}
+
+	if _, err := r.projectRepo.FindByID(ctx, schedule.ProjectID); err != nil {
+		return schedule, err
+	}
+
+	if !model.ProjectScopeFromContext(ctx).Allows(&schedule.ProjectID) {
+		return schedule, errProjectNotAllowed
+	}
+
+	return schedule, nil
+}
+
+func cronSpec(expression string, timezone string) string {
+	return "CRON_TZ=" + timezone + " " + expression
+}
+
+func renderReport(text string, data model.ReportData) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		text = defaultReportTemplate
+	}
+
+	tmpl, err := template.New("report").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", err
+	}
+
+	return out.String(), nil
+}
+
+func (r *ReportScheduleUsecase) FindTrash(ctx context.Context, page model.PageRequest) ([]*model.ReportSchedule, *model.PageMeta, error) {
+	if !hasPermission(ctx, model.PermissionReportManage) {
+		return nil, nil, errForbidden(model.PermissionReportManage)
+	}
+
+	schedules, meta, err := r.scheduleRepo.FindTrash(ctx, page)
+	if err != nil {
+		logrus.WithContext(ctx).Error("Failed to fetch deleted report schedules: ", err)
+		return nil, nil, err
+	}
+
+	return schedules, meta, nil
+}
+
+func (r *ReportScheduleUsecase) Restore(ctx context.Context, id int64) error {
+	if !hasPermission(ctx, model.PermissionReportManage) {
+		return errForbidden(model.PermissionReportManage)
+	}
+
+	if err := r.scheduleRepo.Restore(ctx, id); err != nil {
+		logrus.WithContext(ctx).WithField("id", id).Error("Failed to restore report schedule: ", err)
+		return err
+	}
+
+	r.reload()
+	return nil
+}
+
+func (r *ReportScheduleUsecase) Purge(ctx context.Context, id int64) error {
+	if !hasPermission(ctx, model.PermissionTrashPurge) {
+		return errForbidden(model.PermissionTrashPurge)
+	}
+
+	if err := r.scheduleRepo.Purge(ctx, id); err != nil {
+		logrus.WithContext(ctx).WithField("id", id).Error("Failed to purge report schedule: ", err)
+		return err
+	}
+
+	logrus.WithContext(ctx).WithField("id", id).Info("Purged report schedule")
+	return nil
+}
@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+)
+
+type RoleUsecase struct {
+	roleRepo model.IRoleRepository
+}
+
+func NewRoleUsecase(roleRepo model.IRoleRepository) model.IRoleUsecase {
+	return &RoleUsecase{
+		roleRepo: roleRepo,
+	}
+}
+
+func (r *RoleUsecase) FindAll(ctx context.Context) ([]*model.Role, error) {
+	roles, err := r.roleRepo.FindAll(ctx)
+	if err != nil {
+		logrus.WithContext(ctx).Error("Failed to fetch roles: ", err)
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+func (r *RoleUsecase) FindByID(ctx context.Context, id int64) (*model.Role, error) {
+	role, err := r.roleRepo.FindByID(ctx, id)
+	if err != nil {
+		logrus.WithContext(ctx).WithField("id", id).Error("Failed to fetch role: ", err)
+		return nil, err
+	}
+
+	return role, nil
+}
+
+func (r *RoleUsecase) Create(ctx context.Context, in model.RoleInput) (*model.Role, error) {
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
+		"in": in,
+	})
+
+	if !hasPermission(ctx, model.PermissionRoleManage) {
+		return nil, errForbidden(model.PermissionRoleManage)
+	}
+
+	if err := v.StructCtx(ctx, in); err != nil {
+		log.Error("Validation error: ", err)
+		return nil, err
+	}
+
+	role, err := r.roleRepo.Create(ctx, model.Role{
+		Name:        in.Name,
+		Description: in.Description,
+		Permissions: in.Permissions,
+	})
+	if err != nil {
+		log.Error("Failed to create role: ", err)
+		return nil, err
+	}
+
+	return role, nil
+}
+
+func (r *RoleUsecase) Update(ctx context.Context, id int64, in model.RoleInput) error {
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
+		"id": id,
+		"in": in,
+	})
+
+	if !hasPermission(ctx, model.PermissionRoleManage) {
+		return errForbidden(model.PermissionRoleManage)
+	}
+
+	if err := v.StructCtx(ctx, in); err != nil {
+		log.Error("Validation error: ", err)
+		return err
+	}
+
+	existing, err := r.roleRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Guard against locking everyone out of role management.
+	if existing.Name == "admin" && (in.Name != "admin" || !slices.Contains(in.Permissions, model.PermissionRoleManage)) {
+		return model.NewForbiddenError("the admin role must keep its name and the role:manage permission")
+	}
+
+	err = r.roleRepo.Update(ctx, model.Role{
+		ID:          id,
+		Name:        in.Name,
+		Description: in.Description,
+		Permissions: in.Permissions,
+	})
+	if err != nil {
+		log.Error("Failed to update role: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *RoleUsecase) Delete(ctx context.Context, id int64) error {
+	if !hasPermission(ctx, model.PermissionRoleManage) {
+		return errForbidden(model.PermissionRoleManage)
+	}
+
+	role, err := r.roleRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if role.Name == "admin" {
+		return model.NewForbiddenError("the admin role cannot be deleted")
+	}
+
+	// users.role references the role, so this fails while users still have it.
+	if err := r.roleRepo.Delete(ctx, id); err != nil {
+		logrus.WithContext(ctx).WithField("id", id).Error("Failed to delete role: ", err)
+		return model.NewConflictError("role is still assigned to users")
+	}
+
+	return nil
+}
+
+func (r *RoleUsecase) FindPermissions(ctx context.Context) ([]*model.Permission, error) {
+	return r.roleRepo.FindPermissions(ctx)
+}
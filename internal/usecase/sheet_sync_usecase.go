@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/config"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/metrics"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+)
+
+const (
+	// sheetSyncBatch is how many queued tickets one pass appends at most.
+	sheetSyncBatch = 50
+
+	// sheetSyncLease is how long a claimed ticket is left to the instance
+	// that claimed it before another instance takes it over.
+	sheetSyncLease = 5 * time.Minute
+
+	sheetAppendTimeout = 30 * time.Second
+)
+
+// SheetSyncUsecase appends the queued WhatsApp tickets to the spreadsheet in
+// the background, so a slow or failing spreadsheet never holds up the
+// webhook. Every instance runs it and the claim keeps two of them from
+// appending the same ticket. An instance that dies between the append and
+// marking the ticket synced leaves it to be appended again once its claim
+// expires.
+type SheetSyncUsecase struct {
+	syncRepo  model.ISheetSyncRepository
+	sheetRepo model.ISpreadsheetRepository
+	interval  time.Duration
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewSheetSyncUsecase(
+	syncRepo model.ISheetSyncRepository,
+	sheetRepo model.ISpreadsheetRepository,
+) *SheetSyncUsecase {
+	return &SheetSyncUsecase{
+		syncRepo:  syncRepo,
+		sheetRepo: sheetRepo,
+		interval:  config.SheetSyncInterval(),
+	}
+}
+
+// Start runs a sync pass right away and then every interval until Stop.
+func (s *SheetSyncUsecase) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			s.sync(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop waits for the ticket being appended, the rest stay queued for the
+// next start.
+func (s *SheetSyncUsecase) Stop() {
+	if s.cancel == nil {
+		return
+	}
+
+	s.cancel()
+	<-s.done
+}
+
+// sync appends the oldest queued tickets until stop is cancelled. The first
+// failure ends the pass, the spreadsheet is most likely down and the
+// remaining tickets wait for the next one.
+func (s *SheetSyncUsecase) sync(stop context.Context) {
+	ctx := context.Background()
+	log := logrus.WithContext(ctx).WithField("job", "sheet_sync")
+
+	tickets, err := s.syncRepo.FindPending(ctx, sheetSyncBatch)
+	if err != nil {
+		log.Error("Failed to load queued tickets: ", err)
+		return
+	}
+
+	for _, ticket := range tickets {
+		if stop.Err() != nil {
+			break
+		}
+
+		if err := s.append(ctx, ticket); err != nil {
+			log.WithField("troubleshoot_log_id", ticket.ID).Warn("Failed to sync ticket to the spreadsheet: ", err)
+			break
+		}
+	}
+
+	pending, err := s.syncRepo.CountPending(ctx)
+	if err != nil {
+		log.Warn("Failed to count queued tickets: ", err)
+		return
+	}
+	metrics.SheetSyncPending.Set(float64(pending))
+}
+
+// append claims the ticket and appends it. A ticket claimed by another
+// instance is skipped, a failed append gives the claim back for a retry.
+func (s *SheetSyncUsecase) append(ctx context.Context, ticket *model.TroubleshootLog) error {
+	claimed, err := s.syncRepo.Claim(ctx, ticket.ID, time.Now().Add(-sheetSyncLease))
+	if err != nil || !claimed {
+		return err
+	}
+
+	appendCtx, cancel := context.WithTimeout(ctx, sheetAppendTimeout)
+	row, err := s.sheetRepo.AppendTroubleshoot(appendCtx, ticket)
+	cancel()
+
+	metrics.ObserveSheetSync(ticket.CreatedAt, err)
+
+	if err != nil {
+		if releaseErr := s.syncRepo.Release(ctx, ticket.ID); releaseErr != nil {
+			logrus.WithContext(ctx).WithFields(logrus.Fields{
+				"job":                 "sheet_sync",
+				"troubleshoot_log_id": ticket.ID,
+			}).Warn("Failed to release sheet sync claim: ", releaseErr)
+		}
+		return err
+	}
+
+	if err := s.syncRepo.MarkSynced(ctx, ticket.ID, row); err != nil {
+		return fmt.Errorf("appended as row %d but not marked synced: %w", row, err)
+	}
+
+	return nil
+}
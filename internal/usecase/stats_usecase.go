@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/helper"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+)
+
+const statsMaxPeriods = 400
+
+type StatsUsecase struct {
+	statsRepo model.IStatsRepository
+	cache     *helper.TTLCache
+}
+
+func NewStatsUsecase(statsRepo model.IStatsRepository, cacheTTL time.Duration) model.IStatsUsecase {
+	return &StatsUsecase{
+		statsRepo: statsRepo,
+		cache:     helper.NewTTLCache(cacheTTL),
+	}
+}
+
+func (s *StatsUsecase) Summary(ctx context.Context, filter model.StatsFilter) (*model.StatsSummary, error) {
+	filter, err := normalizeStatsFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return cached(ctx, s.cache, statsCacheKey(ctx, "summary", filter), func() (*model.StatsSummary, error) {
+		return s.statsRepo.Summary(ctx, filter)
+	})
+}
+
+func (s *StatsUsecase) Breakdown(ctx context.Context, filter model.StatsFilter) ([]*model.StatsGroup, error) {
+	if filter.By == "" {
+		filter.By = model.StatsByStatus
+	}
+
+	filter, err := normalizeStatsFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return cached(ctx, s.cache, statsCacheKey(ctx, "breakdown", filter), func() ([]*model.StatsGroup, error) {
+		return s.statsRepo.Breakdown(ctx, filter)
+	})
+}
+
+func (s *StatsUsecase) TimeSeries(ctx context.Context, filter model.StatsFilter) ([]*model.StatsTimeSeriesPoint, error) {
+	if filter.Interval == "" {
+		filter.Interval = "day"
+	}
+
+	filter, err := normalizeStatsFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	periods := filter.To.Sub(filter.From).Hours() / 24
+	switch filter.Interval {
+	case "week":
+		periods /= 7
+	case "month":
+		periods /= 30
+	}
+
+	if periods > statsMaxPeriods {
+		return nil, model.NewValidationError("time range is too large for a %s interval", filter.Interval)
+	}
+
+	return cached(ctx, s.cache, statsCacheKey(ctx, "timeseries", filter), func() ([]*model.StatsTimeSeriesPoint, error) {
+		return s.statsRepo.TimeSeries(ctx, filter)
+	})
+}
+
+// normalizeStatsFilter defaults to the last 30 days including today.
+func normalizeStatsFilter(ctx context.Context, filter model.StatsFilter) (model.StatsFilter, error) {
+	if filter.To.IsZero() {
+		now := time.Now()
+		filter.To = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1)
+	}
+
+	if filter.From.IsZero() {
+		filter.From = filter.To.AddDate(0, 0, -30)
+	}
+
+	if !filter.From.Before(filter.To) {
+		return filter, model.NewFieldError("date_from", "ltfield", "from must be before to")
+	}
+
+	if err := v.StructCtx(ctx, filter); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+// statsCacheKey includes the caller's project scope so results computed for
+// one user's projects are never served to another.
+func statsCacheKey(ctx context.Context, kind string, filter model.StatsFilter) string {
+	key := fmt.Sprintf("%s|%d|%d|%s|%s", kind, filter.From.Unix(), filter.To.Unix(), filter.By, filter.Interval)
+
+	if filter.ProjectID != nil {
+		key += fmt.Sprintf("|p%d", *filter.ProjectID)
+	}
+
+	if filter.LocationID != nil {
+		key += fmt.Sprintf("|l%d", *filter.LocationID)
+	}
+
+	if scope := model.ProjectScopeFromContext(ctx); scope != nil && !scope.All {
+		key += fmt.Sprintf("|s%v", scope.ProjectIDs)
+	}
+
+	return key
+}
+
+func cached[T any](ctx context.Context, cache *helper.TTLCache, key string, load func() (T, error)) (T, error) {
+	if value, ok := cache.Get(key); ok {
+		return value.(T), nil
+	}
+
+	value, err := load()
+	if err != nil {
+		logrus.WithContext(ctx).WithField("key", key).Error("Failed to load stats: ", err)
+		return value, err
+	}
+
+	cache.Set(key, value)
+	return value, nil
+}
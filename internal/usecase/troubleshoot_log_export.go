@@ -0,0 +1,346 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tubagusmf/log-troubleshoot-be/internal/config"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/xuri/excelize/v2"
+)
+
+var exportHeader = []string{
+	"Ticket Number", "Trouble Date", "Trouble Time", "Done Date", "Done Time", "Duration",
+	"Technician", "Project", "Location", "Device", "Device Number", "Work Type",
+	"Part", "Issue", "Solution", "Status",
+}
+
+// exportRecord returns the spreadsheet cells of a row. Most of the text comes
+// from WhatsApp messages, so every cell goes through escapeFormula.
+func exportRecord(row *model.TroubleshootLogExportRow) []string {
+	record := []string{
+		row.TicketNumber,
+		row.TroubleDate.Format(time.DateOnly),
+		row.TroubleTime.Format(time.TimeOnly),
+		formatTimePtr(row.DoneDate, time.DateOnly),
+		formatTimePtr(row.DoneTime, time.TimeOnly),
+		stringValue(row.Duration),
+		stringValue(row.UserName),
+		stringValue(row.ProjectName),
+		stringValue(row.LocationName),
+		stringValue(row.DeviceName),
+		row.DeviceNumber,
+		stringValue(row.WorkTypeName),
+		row.Part,
+		row.Issue,
+		row.Solution,
+		row.Status,
+	}
+
+	for i, value := range record {
+		record[i] = escapeFormula(value)
+	}
+
+	return record
+}
+
+// escapeFormula keeps a spreadsheet from evaluating a cell as a formula by
+// prefixing a quote when it starts with one of the characters Excel and
+// LibreOffice treat as the start of a formula.
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func (u *troubleshootLogUsecase) Export(ctx context.Context, filter model.TroubleshootLogFilter, format string, w io.Writer) error {
+	if err := v.StructCtx(ctx, filter); err != nil {
+		return err
+	}
+
+	switch format {
+	case model.ExportFormatCSV:
+		return u.exportCSV(ctx, filter, w)
+	case model.ExportFormatXLSX:
+		return u.exportXLSX(ctx, filter, w)
+	case model.ExportFormatPDF:
+		return u.exportPDF(ctx, filter, w)
+	default:
+		return model.NewFieldError("format", "oneof", fmt.Sprintf("unsupported export format %q", format))
+	}
+}
+
+func (u *troubleshootLogUsecase) exportCSV(ctx context.Context, filter model.TroubleshootLogFilter, w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(exportHeader); err != nil {
+		return err
+	}
+
+	count := 0
+	err := u.repo.Export(ctx, filter, func(row *model.TroubleshootLogExportRow) error {
+		if err := writer.Write(exportRecord(row)); err != nil {
+			return err
+		}
+
+		// Flush regularly so the client receives data while we read.
+		if count++; count%500 == 0 {
+			writer.Flush()
+			return writer.Error()
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// exportXLSX uses the excelize stream writer, which spills rows to a temp
+// file instead of keeping the whole sheet in memory.
+func (u *troubleshootLogUsecase) exportXLSX(ctx context.Context, filter model.TroubleshootLogFilter, w io.Writer) error {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	const sheet = "Tickets"
+	if err := file.SetSheetName("Sheet1", sheet); err != nil {
+		return err
+	}
+
+	stream, err := file.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+
+	if err := stream.SetRow("A1", toCells(exportHeader)); err != nil {
+		return err
+	}
+
+	rowNumber := 1
+	err = u.repo.Export(ctx, filter, func(row *model.TroubleshootLogExportRow) error {
+		rowNumber++
+
+		cell, err := excelize.CoordinatesToCellName(1, rowNumber)
+		if err != nil {
+			return err
+		}
+
+		return stream.SetRow(cell, toCells(exportRecord(row)))
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := stream.Flush(); err != nil {
+		return err
+	}
+
+	_, err = file.WriteTo(w)
+	return err
+}
+
+type exportSummaryRow struct {
+	Key      string
+	Total    int
+	Open     int
+	Resolved int
+}
+
+var errExportPDFTooLarge = errors.New("too many rows for a pdf export")
+
+// exportPDF reads the tickets twice: first to build the summary tables that
+// open the report, then to render the ticket list. Unlike CSV and XLSX the
+// document is built in memory, so it is refused above ExportPDFMaxRows.
+func (u *troubleshootLogUsecase) exportPDF(ctx context.Context, filter model.TroubleshootLogFilter, w io.Writer) error {
+	byStatus := map[string]*exportSummaryRow{}
+	byLocation := map[string]*exportSummaryRow{}
+	total := 0
+	maxRows := config.ExportPDFMaxRows()
+
+	err := u.repo.Export(ctx, filter, func(row *model.TroubleshootLogExportRow) error {
+		if total++; total > maxRows {
+			return errExportPDFTooLarge
+		}
+		addToSummary(byStatus, row.Status, row.DoneDate != nil)
+		addToSummary(byLocation, stringValue(row.LocationName), row.DoneDate != nil)
+		return nil
+	})
+	if errors.Is(err, errExportPDFTooLarge) {
+		return model.NewFieldError("format", "max_rows", fmt.Sprintf("pdf export is limited to %d tickets, narrow the filter or use csv or xlsx", maxRows))
+	}
+	if err != nil {
+		return err
+	}
+
+	pdf := fpdf.New("L", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetAutoPageBreak(true, 12)
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 10, "Troubleshooting Report", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.CellFormat(0, 6, tr(exportPeriod(filter)), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, fmt.Sprintf("Generated %s, %d tickets", time.Now().Format("2006-01-02 15:04"), total), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	writePDFSummary(pdf, tr, "By status", "Status", byStatus)
+	writePDFSummary(pdf, tr, "By location", "Location", byLocation)
+
+	pdf.AddPage()
+
+	widths := []float64{30, 20, 32, 32, 28, 28, 70, 18, 19}
+	header := []string{"Ticket", "Date", "Project", "Location", "Device", "Part", "Issue", "Status", "Technician"}
+	writePDFRow(pdf, tr, widths, header, true)
+
+	err = u.repo.Export(ctx, filter, func(row *model.TroubleshootLogExportRow) error {
+		device := stringValue(row.DeviceName)
+		if row.DeviceNumber != "" {
+			device += " " + row.DeviceNumber
+		}
+
+		writePDFRow(pdf, tr, widths, []string{
+			row.TicketNumber,
+			row.TroubleDate.Format(time.DateOnly),
+			stringValue(row.ProjectName),
+			stringValue(row.LocationName),
+			device,
+			row.Part,
+			row.Issue,
+			row.Status,
+			stringValue(row.UserName),
+		}, false)
+
+		return pdf.Error()
+	})
+	if err != nil {
+		return err
+	}
+
+	return pdf.Output(w)
+}
+
+func addToSummary(summary map[string]*exportSummaryRow, key string, resolved bool) {
+	if key == "" {
+		key = "-"
+	}
+
+	row, ok := summary[key]
+	if !ok {
+		row = &exportSummaryRow{Key: key}
+		summary[key] = row
+	}
+
+	row.Total++
+	if resolved {
+		row.Resolved++
+	} else {
+		row.Open++
+	}
+}
+
+func writePDFSummary(pdf *fpdf.Fpdf, tr func(string) string, title string, keyHeader string, summary map[string]*exportSummaryRow) {
+	rows := make([]*exportSummaryRow, 0, len(summary))
+	for _, row := range summary {
+		rows = append(rows, row)
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Total != rows[j].Total {
+			return rows[i].Total > rows[j].Total
+		}
+		return rows[i].Key < rows[j].Key
+	})
+
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(0, 8, title, "", 1, "L", false, 0, "")
+
+	widths := []float64{70, 25, 25, 25}
+	writePDFRow(pdf, tr, widths, []string{keyHeader, "Total", "Open", "Resolved"}, true)
+
+	for _, row := range rows {
+		writePDFRow(pdf, tr, widths, []string{
+			row.Key,
+			fmt.Sprint(row.Total),
+			fmt.Sprint(row.Open),
+			fmt.Sprint(row.Resolved),
+		}, false)
+	}
+
+	pdf.Ln(4)
+}
+
+func writePDFRow(pdf *fpdf.Fpdf, tr func(string) string, widths []float64, values []string, header bool) {
+	if header {
+		pdf.SetFont("Helvetica", "B", 8)
+		pdf.SetFillColor(230, 230, 230)
+	} else {
+		pdf.SetFont("Helvetica", "", 8)
+	}
+
+	for i, value := range values {
+		pdf.CellFormat(widths[i], 6, fitPDFText(pdf, tr(value), widths[i]-2), "1", 0, "L", header, 0, "")
+	}
+
+	pdf.Ln(-1)
+}
+
+// fitPDFText cuts text to a single line of the given width.
+func fitPDFText(pdf *fpdf.Fpdf, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+
+	for len(text) > 0 && pdf.GetStringWidth(text+"...") > width {
+		text = text[:len(text)-1]
+	}
+
+	return text + "..."
+}
+
+func exportPeriod(filter model.TroubleshootLogFilter) string {
+	switch {
+	case filter.TroubleDateFrom != nil && filter.TroubleDateTo != nil:
+		return fmt.Sprintf("Period %s to %s", filter.TroubleDateFrom.Format(time.DateOnly), filter.TroubleDateTo.Add(-time.Nanosecond).Format(time.DateOnly))
+	case filter.TroubleDateFrom != nil:
+		return "From " + filter.TroubleDateFrom.Format(time.DateOnly)
+	case filter.TroubleDateTo != nil:
+		return "Until " + filter.TroubleDateTo.Add(-time.Nanosecond).Format(time.DateOnly)
+	default:
+		return "All tickets"
+	}
+}
+
+func toCells(values []string) []interface{} {
+	cells := make([]interface{}, len(values))
+	for i, value := range values {
+		cells[i] = value
+	}
+	return cells
+}
+
+func formatTimePtr(t *time.Time, layout string) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(layout)
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -3,43 +3,450 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/config"
 	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
 )
 
+var errProjectNotAllowed = model.NewForbiddenError("you are not a member of this project")
+
 type troubleshootLogUsecase struct {
-	repo model.ITroubleshootLogRepository
+	repo           model.ITroubleshootLogRepository
+	userRepo       model.IUserRepository
+	projectRepo    model.IProjectRepository
+	locationRepo   model.ILocationRepository
+	deviceRepo     model.IDeviceRepository
+	workTypeRepo   model.IWorkTypeRepository
+	attachmentRepo model.IAttachmentRepository
+	storage        model.IFileStorage
 }
 
-func NewTroubleshootLogUsecase(repo model.ITroubleshootLogRepository) model.ITroubleshootLogUsecase {
-	return &troubleshootLogUsecase{repo: repo}
+func NewTroubleshootLogUsecase(
+	repo model.ITroubleshootLogRepository,
+	userRepo model.IUserRepository,
+	projectRepo model.IProjectRepository,
+	locationRepo model.ILocationRepository,
+	deviceRepo model.IDeviceRepository,
+	workTypeRepo model.IWorkTypeRepository,
+	attachmentRepo model.IAttachmentRepository,
+	storage model.IFileStorage,
+) model.ITroubleshootLogUsecase {
+	return &troubleshootLogUsecase{
+		repo:           repo,
+		userRepo:       userRepo,
+		projectRepo:    projectRepo,
+		locationRepo:   locationRepo,
+		deviceRepo:     deviceRepo,
+		workTypeRepo:   workTypeRepo,
+		attachmentRepo: attachmentRepo,
+		storage:        storage,
+	}
 }
 
-func (u *troubleshootLogUsecase) FindAll(ctx context.Context, log model.TroubleshootLog) ([]*model.TroubleshootLog, error) {
-	return u.repo.FindAll(ctx, log)
+func (u *troubleshootLogUsecase) FindAll(ctx context.Context, filter model.TroubleshootLogFilter, page model.PageRequest) ([]*model.TroubleshootLog, *model.PageMeta, error) {
+	if err := v.StructCtx(ctx, filter); err != nil {
+		return nil, nil, err
+	}
+
+	return u.repo.FindAll(ctx, filter, page)
 }
 
 func (u *troubleshootLogUsecase) FindByID(ctx context.Context, id int64) (*model.TroubleshootLog, error) {
 	return u.repo.FindByID(ctx, id)
 }
 
-func (u *troubleshootLogUsecase) Create(ctx context.Context, log model.TroubleshootLog) (*model.TroubleshootLog, error) {
-	if log.Issue == "" {
-		return nil, errors.New("issue is required")
+func (u *troubleshootLogUsecase) Create(ctx context.Context, in model.CreateTroubleshootLogInput) (*model.TroubleshootLog, error) {
+	if err := v.StructCtx(ctx, in); err != nil {
+		return nil, err
+	}
+
+	log := model.TroubleshootLog{
+		TroubleDate:  in.TroubleDate,
+		TroubleTime:  in.TroubleDate,
+		DoneDate:     in.DoneDate,
+		DoneTime:     in.DoneTime,
+		UserID:       in.UserID,
+		ProjectID:    in.ProjectID,
+		LocationID:   in.LocationID,
+		DeviceID:     in.DeviceID,
+		WorkTypeID:   in.WorkTypeID,
+		DeviceNumber: in.DeviceNumber,
+		Part:         in.Part,
+		Issue:        in.Issue,
+		Solution:     in.Solution,
+		Status:       strings.ToUpper(in.Status),
+	}
+
+	if in.TroubleTime != nil {
+		log.TroubleTime = *in.TroubleTime
 	}
 
 	if log.Status == "" {
-		log.Status = "OPEN"
+		log.Status = model.TicketStatusOpen
 	}
 
-	return u.repo.Create(ctx, log)
+	if isClosingTicket(log.Status, log.DoneDate, log.DoneTime) && !hasPermission(ctx, model.PermissionTicketClose) {
+		return nil, errForbidden(model.PermissionTicketClose)
+	}
+
+	if !model.ProjectScopeFromContext(ctx).Allows(log.ProjectID) {
+		return nil, errProjectNotAllowed
+	}
+
+	if err := u.checkReferences(ctx, log); err != nil {
+		return nil, err
+	}
+
+	if err := setDuration(&log); err != nil {
+		return nil, err
+	}
+
+	created, err := u.repo.Create(ctx, log)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.DetectRecurrence(ctx, created); err != nil {
+		logrus.WithContext(ctx).WithField("id", created.ID).Warn("Failed to detect recurrence: ", err)
+	}
+
+	return created, nil
 }
 
-func (u *troubleshootLogUsecase) Update(ctx context.Context, id int64, log model.TroubleshootLog) error {
-	log.ID = id
+func (u *troubleshootLogUsecase) Update(ctx context.Context, id int64, in model.UpdateTroubleshootLogInput) error {
+	if err := v.StructCtx(ctx, in); err != nil {
+		return err
+	}
+
+	status := ""
+	if in.Status != nil {
+		status = strings.ToUpper(*in.Status)
+		in.Status = &status
+	}
+
+	if isClosingTicket(status, in.DoneDate, in.DoneTime) && !hasPermission(ctx, model.PermissionTicketClose) {
+		return errForbidden(model.PermissionTicketClose)
+	}
+
+	// FindByID is project scoped, so tickets outside the caller's projects
+	// look like they do not exist.
+	existing, err := u.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if in.ProjectID != nil && !model.ProjectScopeFromContext(ctx).Allows(in.ProjectID) {
+		return errProjectNotAllowed
+	}
+
+	// Only the references that change are looked up.
+	changed := model.TroubleshootLog{
+		UserID:     in.UserID,
+		ProjectID:  in.ProjectID,
+		LocationID: in.LocationID,
+		DeviceID:   in.DeviceID,
+		WorkTypeID: in.WorkTypeID,
+	}
+	if err := u.checkReferences(ctx, changed); err != nil {
+		return err
+	}
+
+	log := *existing
+	applyTroubleshootLogUpdate(&log, in)
+
+	if err := setDuration(&log); err != nil {
+		return err
+	}
+
+	log.UpdatedAt = time.Now()
 	return u.repo.Update(ctx, log)
 }
 
+func applyTroubleshootLogUpdate(log *model.TroubleshootLog, in model.UpdateTroubleshootLogInput) {
+	if in.TroubleDate != nil {
+		log.TroubleDate = *in.TroubleDate
+	}
+	if in.TroubleTime != nil {
+		log.TroubleTime = *in.TroubleTime
+	}
+	if in.DoneDate != nil {
+		log.DoneDate = in.DoneDate
+	}
+	if in.DoneTime != nil {
+		log.DoneTime = in.DoneTime
+	}
+	if in.UserID != nil {
+		log.UserID = in.UserID
+	}
+	if in.ProjectID != nil {
+		log.ProjectID = in.ProjectID
+	}
+	if in.LocationID != nil {
+		log.LocationID = in.LocationID
+	}
+	if in.DeviceID != nil {
+		log.DeviceID = in.DeviceID
+	}
+	if in.WorkTypeID != nil {
+		log.WorkTypeID = in.WorkTypeID
+	}
+	if in.DeviceNumber != nil {
+		log.DeviceNumber = *in.DeviceNumber
+	}
+	if in.Part != nil {
+		log.Part = *in.Part
+	}
+	if in.Issue != nil {
+		log.Issue = *in.Issue
+	}
+	if in.Solution != nil {
+		log.Solution = *in.Solution
+	}
+	if in.Status != nil {
+		log.Status = *in.Status
+	}
+}
+
+// checkReferences makes sure every master-data ID set on the ticket points
+// at a record that exists and is not deleted.
+func (u *troubleshootLogUsecase) checkReferences(ctx context.Context, log model.TroubleshootLog) error {
+	references := []struct {
+		field string
+		id    *int64
+		find  func(id int64) (*time.Time, error)
+	}{
+		{"user_id", log.UserID, func(id int64) (*time.Time, error) {
+			user, err := u.userRepo.FindByID(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			return user.DeletedAt, nil
+		}},
+		{"project_id", log.ProjectID, func(id int64) (*time.Time, error) {
+			project, err := u.projectRepo.FindByID(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			return project.DeletedAt, nil
+		}},
+		{"location_id", log.LocationID, func(id int64) (*time.Time, error) {
+			location, err := u.locationRepo.FindByID(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			return location.DeletedAt, nil
+		}},
+		{"device_id", log.DeviceID, func(id int64) (*time.Time, error) {
+			device, err := u.deviceRepo.FindByID(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			return device.DeletedAt, nil
+		}},
+		{"work_type_id", log.WorkTypeID, func(id int64) (*time.Time, error) {
+			workType, err := u.workTypeRepo.FindByID(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			return workType.DeletedAt, nil
+		}},
+	}
+
+	var fields []model.FieldError
+	for _, ref := range references {
+		if ref.id == nil {
+			continue
+		}
+
+		deletedAt, err := ref.find(*ref.id)
+		if err != nil && !errors.Is(err, model.ErrNotFound) {
+			return err
+		}
+
+		if err != nil || deletedAt != nil {
+			fields = append(fields, model.FieldError{
+				Field:   ref.field,
+				Rule:    "exists",
+				Message: fmt.Sprintf("%s %d does not exist", ref.field, *ref.id),
+			})
+		}
+	}
+
+	if len(fields) > 0 {
+		return &model.DomainError{
+			Kind:    model.ErrValidation,
+			Message: "referenced records do not exist",
+			Fields:  fields,
+		}
+	}
+
+	return nil
+}
+
+// setDuration derives the duration from the trouble and done timestamps.
+// The dates and times are stored in separate columns, so they are combined
+// first; a missing done time counts as the start of the done date.
+func setDuration(log *model.TroubleshootLog) error {
+	if log.DoneDate == nil {
+		log.Duration = nil
+		return nil
+	}
+
+	start := combineDateTime(log.TroubleDate, log.TroubleTime)
+
+	doneTime := *log.DoneDate
+	if log.DoneTime != nil {
+		doneTime = *log.DoneTime
+	}
+	end := combineDateTime(*log.DoneDate, doneTime)
+
+	if end.Before(start) {
+		return model.NewFieldError("done_date", "gtefield", "done date must not be before the trouble date")
+	}
+
+	elapsed := end.Sub(start)
+	duration := fmt.Sprintf("%d:%02d:%02d", int(elapsed.Hours()), int(elapsed.Minutes())%60, int(elapsed.Seconds())%60)
+	log.Duration = &duration
+
+	return nil
+}
+
+func combineDateTime(date, clock time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, date.Location())
+}
+
 func (u *troubleshootLogUsecase) Delete(ctx context.Context, id int64) error {
+	if !hasPermission(ctx, model.PermissionTicketDelete) {
+		return errForbidden(model.PermissionTicketDelete)
+	}
+
+	if _, err := u.repo.FindByID(ctx, id); err != nil {
+		return err
+	}
+
 	return u.repo.Delete(ctx, id)
 }
+
+func (u *troubleshootLogUsecase) FindTrash(ctx context.Context, page model.PageRequest) ([]*model.TroubleshootLog, *model.PageMeta, error) {
+	if !hasPermission(ctx, model.PermissionTicketDelete) {
+		return nil, nil, errForbidden(model.PermissionTicketDelete)
+	}
+
+	return u.repo.FindTrash(ctx, page)
+}
+
+func (u *troubleshootLogUsecase) Restore(ctx context.Context, id int64) error {
+	if !hasPermission(ctx, model.PermissionTicketDelete) {
+		return errForbidden(model.PermissionTicketDelete)
+	}
+
+	return u.repo.Restore(ctx, id)
+}
+
+// Purge removes a ticket from the trash together with its attachments. The
+// blobs are only removed once the rows are gone.
+func (u *troubleshootLogUsecase) Purge(ctx context.Context, id int64) error {
+	if !hasPermission(ctx, model.PermissionTrashPurge) {
+		return errForbidden(model.PermissionTrashPurge)
+	}
+
+	attachments, err := u.attachmentRepo.FindAllByTroubleshootLogID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := u.repo.Purge(ctx, id); err != nil {
+		logrus.WithContext(ctx).WithField("id", id).Error("Failed to purge troubleshoot log: ", err)
+		return err
+	}
+
+	for _, attachment := range attachments {
+		removeBlobs(ctx, u.storage, attachment.StorageKey, attachment.ThumbnailKey)
+	}
+
+	logrus.WithContext(ctx).WithField("id", id).Info("Purged troubleshoot log")
+	return nil
+}
+
+// isClosingTicket reports whether a ticket is created or updated as
+// resolved, which needs the ticket:close permission on top of ticket:write.
+func isClosingTicket(status string, doneDate, doneTime *time.Time) bool {
+	if doneDate != nil || doneTime != nil {
+		return true
+	}
+
+	switch strings.ToUpper(status) {
+	case "CLOSED", "DONE", "RESOLVED":
+		return true
+	}
+
+	return false
+}
+
+func (u *troubleshootLogUsecase) Search(ctx context.Context, in model.SearchTroubleshootLogInput) ([]*model.TroubleshootLogSearchResult, error) {
+	in.Query = strings.TrimSpace(in.Query)
+
+	if in.Page == 0 {
+		in.Page = 1
+	}
+
+	if in.Limit == 0 {
+		in.Limit = 20
+	}
+
+	if err := v.StructCtx(ctx, in); err != nil {
+		return nil, err
+	}
+
+	return u.repo.Search(ctx, in)
+}
+
+// DetectRecurrence flags the ticket as recurring when the same device and
+// part failed with a similar issue within the recurrence window, and links
+// it to the most recent of those tickets.
+func (u *troubleshootLogUsecase) DetectRecurrence(ctx context.Context, log *model.TroubleshootLog) error {
+	since := log.TroubleDate.Add(-config.RecurrenceWindow())
+
+	previous, err := u.repo.FindRecurrenceCandidates(ctx, *log, since, config.RecurrenceMinSimilarity())
+	if err != nil {
+		return err
+	}
+
+	if len(previous) == 0 {
+		return nil
+	}
+
+	if err := u.repo.MarkRecurrence(ctx, log.ID, previous[0].ID, len(previous)); err != nil {
+		return err
+	}
+
+	log.IsRecurring = true
+	log.RecurrenceOfID = &previous[0].ID
+	log.RecurrenceCount = len(previous)
+
+	return nil
+}
+
+func (u *troubleshootLogUsecase) FindRepeatOffenders(ctx context.Context, filter model.RepeatOffenderFilter) ([]*model.RepeatOffender, error) {
+	if filter.To.IsZero() {
+		filter.To = time.Now().AddDate(0, 0, 1)
+	}
+
+	if filter.From.IsZero() {
+		filter.From = filter.To.AddDate(0, -3, 0)
+	}
+
+	if filter.PerLocation == 0 {
+		filter.PerLocation = 5
+	}
+
+	if err := v.StructCtx(ctx, filter); err != nil {
+		return nil, err
+	}
+
+	return u.repo.FindRepeatOffenders(ctx, filter)
+}
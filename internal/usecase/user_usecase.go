@@ -3,75 +3,280 @@ package usecase
 import (
 	"context"
 	"errors"
+	"reflect"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/tubagusmf/log-troubleshoot-be/internal/config"
 	"github.com/tubagusmf/log-troubleshoot-be/internal/helper"
 	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/sirupsen/logrus"
-	"golang.org/x/crypto/bcrypt"
 )
 
-var v = validator.New()
+var v = newValidator()
+
+// newValidator reports fields by their JSON name, so validation details
+// match the request body the client sent.
+func newValidator() *validator.Validate {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return validate
+}
 
 type UserUsecase struct {
-	userRepo model.IUserRepository
+	userRepo          model.IUserRepository
+	roleRepo          model.IRoleRepository
+	tokenRepo         model.ITokenRepository
+	passwordResetRepo model.IPasswordResetRepository
+	apiKeyRepo        model.IAPIKeyRepository
+	loginThrottle     *loginThrottle
 }
 
 func NewUserUsecase(
 	userRepo model.IUserRepository,
+	roleRepo model.IRoleRepository,
+	tokenRepo model.ITokenRepository,
+	passwordResetRepo model.IPasswordResetRepository,
+	loginAttemptRepo model.ILoginAttemptRepository,
+	apiKeyRepo model.IAPIKeyRepository,
 ) model.IUserUsecase {
 	return &UserUsecase{
-		userRepo: userRepo,
+		userRepo:          userRepo,
+		roleRepo:          roleRepo,
+		tokenRepo:         tokenRepo,
+		passwordResetRepo: passwordResetRepo,
+		apiKeyRepo:        apiKeyRepo,
+		loginThrottle:     &loginThrottle{repo: loginAttemptRepo},
 	}
 }
 
-func (u *UserUsecase) Login(ctx context.Context, in model.LoginInput) (token string, err error) {
-	log := logrus.WithFields(logrus.Fields{
+var errInvalidRefreshToken = model.NewUnauthorizedError("refresh token is invalid or expired")
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     string
+)
+
+func dummyPasswordHash() string {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = helper.HashRequestPassword("not-a-real-password")
+	})
+	return dummyHash
+}
+
+func (u *UserUsecase) Login(ctx context.Context, in model.LoginInput) (*model.TokenPair, error) {
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
 		"username": in.Username,
 	})
 
 	if err := v.Struct(in); err != nil {
 		log.Error("Validation error: ", err)
-		return "", err
+		return nil, err
+	}
+
+	if err := u.loginThrottle.Check(ctx, in.Username, in.IP); err != nil {
+		log.Warn("Login throttled: ", err)
+		return nil, err
 	}
 
 	user, err := u.userRepo.FindByUsername(ctx, in.Username)
 	if err != nil || user == nil {
-		return "", errors.New("username or password is wrong")
+		// Spend the same time as a wrong password so response times do not
+		// reveal which usernames exist.
+		helper.CheckPasswordHash(in.Password, dummyPasswordHash())
+		u.loginThrottle.Failure(ctx, in.Username, in.IP)
+		return nil, model.ErrInvalidCredentials
 	}
 
 	if !helper.CheckPasswordHash(in.Password, user.Password) {
-		err = errors.New("missmatch password")
-		return
+		u.loginThrottle.Failure(ctx, in.Username, in.IP)
+		return nil, model.ErrInvalidCredentials
 	}
 
-	token, err = helper.GenerateToken(*user)
+	u.loginThrottle.Success(ctx, in.Username)
 
+	familyID, err := helper.RandomToken(16)
 	if err != nil {
 		log.Error(err)
+		return nil, err
 	}
 
-	return
+	return u.issueTokens(ctx, user, familyID, func(next model.RefreshToken) error {
+		_, err := u.tokenRepo.CreateRefreshToken(ctx, next)
+		return err
+	})
 }
 
-func (u *UserUsecase) FindAll(ctx context.Context, user model.User) ([]*model.User, error) {
-	log := logrus.WithFields(logrus.Fields{
+// Refresh exchanges a refresh token for a new token pair. The presented token
+// is rotated; presenting it again revokes every token descended from the
+// same login, since that means it was copied.
+func (u *UserUsecase) Refresh(ctx context.Context, in model.RefreshTokenInput) (*model.TokenPair, error) {
+	if err := v.StructCtx(ctx, in); err != nil {
+		return nil, err
+	}
+
+	current, err := u.tokenRepo.FindRefreshTokenByHash(ctx, helper.HashToken(in.RefreshToken))
+	if err != nil {
+		logrus.WithContext(ctx).Error("Failed to fetch refresh token: ", err)
+		return nil, err
+	}
+
+	if current == nil {
+		return nil, errInvalidRefreshToken
+	}
+
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
+		"user_id":   current.UserID,
+		"family_id": current.FamilyID,
+	})
+
+	if current.RevokedAt != nil {
+		log.Warn("Refresh token reuse detected, revoking token family")
+		if err := u.tokenRepo.RevokeRefreshTokenFamily(ctx, current.FamilyID); err != nil {
+			log.Error("Failed to revoke token family: ", err)
+		}
+		return nil, errInvalidRefreshToken
+	}
+
+	if time.Now().After(current.ExpiresAt) {
+		return nil, errInvalidRefreshToken
+	}
+
+	user, err := u.userRepo.FindByID(ctx, current.UserID)
+	if err != nil || user == nil || user.DeletedAt != nil {
+		return nil, errInvalidRefreshToken
+	}
+
+	return u.issueTokens(ctx, user, current.FamilyID, func(next model.RefreshToken) error {
+		_, rotated, err := u.tokenRepo.RotateRefreshToken(ctx, current.ID, next)
+		if err != nil {
+			return err
+		}
+
+		if !rotated {
+			log.Warn("Refresh token reused concurrently, revoking token family")
+			if err := u.tokenRepo.RevokeRefreshTokenFamily(ctx, current.FamilyID); err != nil {
+				log.Error("Failed to revoke token family: ", err)
+			}
+			return errInvalidRefreshToken
+		}
+
+		return nil
+	})
+}
+
+// issueTokens signs an access token for the user and hands a freshly
+// generated refresh token to store, which persists it.
+func (u *UserUsecase) issueTokens(ctx context.Context, user *model.User, familyID string, store func(model.RefreshToken) error) (*model.TokenPair, error) {
+	role, err := u.roleRepo.FindByName(ctx, user.Role)
+	if err != nil {
+		logrus.WithContext(ctx).WithField("role", user.Role).Error("Failed to fetch role: ", err)
+		return nil, err
+	}
+
+	accessToken, err := helper.GenerateToken(*user, role.Permissions)
+	if err != nil {
+		logrus.WithContext(ctx).Error(err)
+		return nil, err
+	}
+
+	refreshToken, err := helper.RandomToken(32)
+	if err != nil {
+		logrus.WithContext(ctx).Error(err)
+		return nil, err
+	}
+
+	err = store(model.RefreshToken{
+		UserID:    user.Id,
+		FamilyID:  familyID,
+		TokenHash: helper.HashToken(refreshToken),
+		ExpiresAt: time.Now().Add(config.JWTRefreshExp()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(config.JWTExp().Seconds()),
+	}, nil
+}
+
+// Logout denylists the caller's access token until it expires and, when
+// given, revokes the refresh token family it belongs to.
+func (u *UserUsecase) Logout(ctx context.Context, in model.LogoutInput) error {
+	claims, ok := ctx.Value(model.BearerAuthKey).(*model.CustomClaims)
+	if !ok || claims == nil {
+		return model.NewUnauthorizedError("unauthorized")
+	}
+
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
+		"user_id": claims.UserID,
+	})
+
+	if claims.ID != "" && claims.ExpiresAt != nil {
+		if err := u.tokenRepo.RevokeAccessToken(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+			log.Error("Failed to revoke access token: ", err)
+			return err
+		}
+	}
+
+	if in.RefreshToken != "" {
+		token, err := u.tokenRepo.FindRefreshTokenByHash(ctx, helper.HashToken(in.RefreshToken))
+		if err != nil {
+			log.Error("Failed to fetch refresh token: ", err)
+			return err
+		}
+
+		if token != nil && token.UserID == claims.UserID {
+			if err := u.tokenRepo.RevokeRefreshTokenFamily(ctx, token.FamilyID); err != nil {
+				log.Error("Failed to revoke refresh tokens: ", err)
+				return err
+			}
+		}
+	}
+
+	if err := u.tokenRepo.DeleteExpired(ctx); err != nil {
+		log.Warn("Failed to clean up expired tokens: ", err)
+	}
+
+	return nil
+}
+
+func (u *UserUsecase) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	if jti == "" {
+		return false, nil
+	}
+
+	return u.tokenRepo.IsAccessTokenRevoked(ctx, jti)
+}
+
+func (u *UserUsecase) FindAll(ctx context.Context, user model.User, page model.PageRequest) ([]*model.User, *model.PageMeta, error) {
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
 		"filter": user,
 	})
 
-	users, err := u.userRepo.FindAll(ctx, user)
+	users, meta, err := u.userRepo.FindAll(ctx, user, page)
 	if err != nil {
 		log.Error("Failed to fetch users: ", err)
-		return nil, err
+		return nil, nil, err
 	}
 
-	return users, nil
+	return users, meta, nil
 }
 
 func (u *UserUsecase) FindByID(ctx context.Context, id int64) (*model.User, error) {
-	log := logrus.WithFields(logrus.Fields{
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
 		"id": id,
 	})
 
@@ -83,43 +288,90 @@ func (u *UserUsecase) FindByID(ctx context.Context, id int64) (*model.User, erro
 
 	if user == nil {
 		log.Error("User not found")
-		return nil, errors.New("user not found")
+		return nil, model.NewNotFoundError("user not found")
 	}
 
 	return user, nil
 }
 
-func isAdmin(ctx context.Context) bool {
-	val := ctx.Value(model.BearerAuthKey)
-	logrus.Infof("AUTH CONTEXT VALUE: %#v", val)
+func hasPermission(ctx context.Context, permission string) bool {
+	claims, ok := ctx.Value(model.BearerAuthKey).(*model.CustomClaims)
+	return ok && claims != nil && claims.HasPermission(permission)
+}
+
+func errForbidden(permission string) error {
+	return model.NewForbiddenError("%s permission required", permission)
+}
 
-	claims, ok := val.(*model.CustomClaims)
+// checkRoleAssignment lets a caller with role:manage give a user any role.
+// Anyone else may only hand out roles that grant nothing beyond their own
+// permissions, so user:write alone cannot make an admin.
+func checkRoleAssignment(ctx context.Context, role *model.Role) error {
+	claims, ok := ctx.Value(model.BearerAuthKey).(*model.CustomClaims)
 	if !ok || claims == nil {
-		logrus.Warn("CLAIMS NOT FOUND OR WRONG TYPE")
-		return false
+		return model.NewUnauthorizedError("unauthorized")
 	}
 
-	logrus.Infof("USER ROLE FROM TOKEN: %s", claims.Role)
-	return claims.Role == "admin"
+	if claims.HasPermission(model.PermissionRoleManage) {
+		return nil
+	}
+
+	for _, permission := range role.Permissions {
+		if !claims.HasPermission(permission) {
+			return model.NewForbiddenError("%s permission required to assign role %s", model.PermissionRoleManage, role.Name)
+		}
+	}
+
+	return nil
 }
 
-func (u *UserUsecase) Create(ctx context.Context, in model.CreateUserInput) (token string, err error) {
-	logger := logrus.WithFields(logrus.Fields{
+// checkManageUser applies checkRoleAssignment to the user's current role
+// before acting on their account, so holding user:write alone is not
+// enough to reset, unlock or delete an admin.
+func (u *UserUsecase) checkManageUser(ctx context.Context, user *model.User) error {
+	role, err := u.roleRepo.FindByName(ctx, user.Role)
+	if err != nil {
+		return err
+	}
+
+	err = checkRoleAssignment(ctx, role)
+	if errors.Is(err, model.ErrForbidden) {
+		return model.NewForbiddenError("%s permission required to manage a user with role %s", model.PermissionRoleManage, role.Name)
+	}
+
+	return err
+}
+
+func (u *UserUsecase) Create(ctx context.Context, in model.CreateUserInput) (*model.User, error) {
+	logger := logrus.WithContext(ctx).WithFields(logrus.Fields{
 		"in": in,
 	})
 
-	if !isAdmin(ctx) {
-		return "", errors.New("forbidden: admin only")
+	if !hasPermission(ctx, model.PermissionUserWrite) {
+		return nil, errForbidden(model.PermissionUserWrite)
 	}
 
 	if err := v.Struct(in); err != nil {
-		return "", err
+		return nil, err
+	}
+
+	if err := helper.ValidatePassword(in.Password); err != nil {
+		return nil, model.NewFieldError("password", "password", err.Error())
+	}
+
+	role, err := u.roleRepo.FindByName(ctx, in.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkRoleAssignment(ctx, role); err != nil {
+		return nil, err
 	}
 
 	passwordHashed, err := helper.HashRequestPassword(in.Password)
 	if err != nil {
 		logger.Error(err)
-		return
+		return nil, err
 	}
 
 	newUser, err := u.userRepo.Create(ctx, model.User{
@@ -129,31 +381,24 @@ func (u *UserUsecase) Create(ctx context.Context, in model.CreateUserInput) (tok
 		Password: passwordHashed,
 		Role:     in.Role,
 	})
-
 	if err != nil {
 		logger.Error(err)
-		return
-	}
-
-	accessToken, err := helper.GenerateToken(*newUser)
-	if err != nil {
-		logger.Error(err)
-		return
+		return nil, err
 	}
 
-	return accessToken, nil
+	return newUser, nil
 }
 
 func (u *UserUsecase) Update(ctx context.Context, id int64, in model.UpdateUserInput) error {
-	log := logrus.WithFields(logrus.Fields{
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
 		"id":       id,
 		"name":     in.Name,
 		"codeName": in.CodeName,
 		"username": in.Username,
 	})
 
-	if !isAdmin(ctx) {
-		return errors.New("forbidden: admin only")
+	if !hasPermission(ctx, model.PermissionUserWrite) {
+		return errForbidden(model.PermissionUserWrite)
 	}
 
 	err := v.StructCtx(ctx, in)
@@ -170,21 +415,35 @@ func (u *UserUsecase) Update(ctx context.Context, id int64, in model.UpdateUserI
 
 	if existingUser == nil || (existingUser.DeletedAt != nil && !existingUser.DeletedAt.IsZero()) {
 		log.Error("User is deleted or does not exist")
-		return errors.New("user is deleted or does not exist")
+		return model.NewNotFoundError("user is deleted or does not exist")
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(in.Password), bcrypt.DefaultCost)
+	role, err := u.roleRepo.FindByName(ctx, in.Role)
 	if err != nil {
-		log.Error("Failed to hash password: ", err)
 		return err
 	}
 
+	// Changing a role needs the right to assign both the old and the new
+	// one, so an admin cannot be demoted by someone who holds less.
+	roleChanged := in.Role != existingUser.Role
+	if roleChanged {
+		currentRole, err := u.roleRepo.FindByName(ctx, existingUser.Role)
+		if err != nil {
+			return err
+		}
+
+		for _, r := range []*model.Role{currentRole, role} {
+			if err := checkRoleAssignment(ctx, r); err != nil {
+				return err
+			}
+		}
+	}
+
 	user := model.User{
 		Id:        id,
 		Name:      in.Name,
 		CodeName:  in.CodeName,
 		Username:  in.Username,
-		Password:  string(hashedPassword),
 		Role:      in.Role,
 		UpdatedAt: time.Now(),
 	}
@@ -195,16 +454,24 @@ func (u *UserUsecase) Update(ctx context.Context, id int64, in model.UpdateUserI
 		return err
 	}
 
+	// Sessions carry the permissions of the old role, end them.
+	if roleChanged {
+		if err := u.tokenRepo.RevokeUserRefreshTokens(ctx, id); err != nil {
+			log.Error("Failed to revoke refresh tokens: ", err)
+			return err
+		}
+	}
+
 	return nil
 }
 
 func (u *UserUsecase) Delete(ctx context.Context, id int64) error {
-	log := logrus.WithFields(logrus.Fields{
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
 		"id": id,
 	})
 
-	if !isAdmin(ctx) {
-		return errors.New("forbidden: admin only")
+	if !hasPermission(ctx, model.PermissionUserWrite) {
+		return errForbidden(model.PermissionUserWrite)
 	}
 
 	user, err := u.userRepo.FindByID(ctx, id)
@@ -215,6 +482,10 @@ func (u *UserUsecase) Delete(ctx context.Context, id int64) error {
 
 	if user == nil {
 		log.Error("User not found")
+		return model.NewNotFoundError("user not found")
+	}
+
+	if err := u.checkManageUser(ctx, user); err != nil {
 		return err
 	}
 
@@ -227,6 +498,240 @@ func (u *UserUsecase) Delete(ctx context.Context, id int64) error {
 		return err
 	}
 
+	if err := u.tokenRepo.RevokeUserRefreshTokens(ctx, id); err != nil {
+		log.Error("Failed to revoke refresh tokens: ", err)
+		return err
+	}
+
+	if err := u.apiKeyRepo.RevokeByCreator(ctx, id); err != nil {
+		log.Error("Failed to revoke api keys: ", err)
+		return err
+	}
+
 	log.Info("Successfully deleted user with ID: ", id)
 	return nil
 }
+
+// ChangePassword lets the signed in user pick a new password after proving
+// they know the current one. Other sessions are signed out.
+func (u *UserUsecase) ChangePassword(ctx context.Context, in model.ChangePasswordInput) error {
+	claims, ok := ctx.Value(model.BearerAuthKey).(*model.CustomClaims)
+	if !ok || claims == nil {
+		return model.NewUnauthorizedError("unauthorized")
+	}
+
+	if claims.APIKeyID != 0 {
+		return model.NewForbiddenError("passwords cannot be changed with an api key")
+	}
+
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
+		"user_id": claims.UserID,
+	})
+
+	if err := v.StructCtx(ctx, in); err != nil {
+		return err
+	}
+
+	user, err := u.userRepo.FindByID(ctx, claims.UserID)
+	if err != nil || user == nil {
+		log.Error("Failed to fetch user: ", err)
+		return model.NewNotFoundError("user not found")
+	}
+
+	if !helper.CheckPasswordHash(in.OldPassword, user.Password) {
+		return model.NewFieldError("old_password", "", "old password is incorrect")
+	}
+
+	if in.OldPassword == in.NewPassword {
+		return model.NewFieldError("new_password", "", "new password must be different from the old password")
+	}
+
+	if err := u.setPassword(ctx, user.Id, in.NewPassword); err != nil {
+		log.Error("Failed to change password: ", err)
+		return err
+	}
+
+	log.Info("Password changed")
+	return nil
+}
+
+// IssuePasswordReset creates a one-time token an admin hands to the user so
+// they can choose a new password. Earlier unused tokens stop working.
+func (u *UserUsecase) IssuePasswordReset(ctx context.Context, userID int64) (*model.PasswordReset, error) {
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
+		"user_id": userID,
+	})
+
+	if !hasPermission(ctx, model.PermissionUserWrite) {
+		return nil, errForbidden(model.PermissionUserWrite)
+	}
+
+	user, err := u.userRepo.FindByID(ctx, userID)
+	if err != nil || user == nil || user.DeletedAt != nil {
+		return nil, model.NewNotFoundError("user not found")
+	}
+
+	// The token lets whoever holds it take the account over.
+	if err := u.checkManageUser(ctx, user); err != nil {
+		return nil, err
+	}
+
+	token, err := helper.RandomToken(32)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	reset := model.PasswordResetToken{
+		UserID:    user.Id,
+		TokenHash: helper.HashToken(token),
+		ExpiresAt: time.Now().Add(config.PasswordResetTokenTTL()),
+	}
+	if claims, ok := ctx.Value(model.BearerAuthKey).(*model.CustomClaims); ok && claims != nil {
+		reset.CreatedBy = &claims.UserID
+	}
+
+	if _, err := u.passwordResetRepo.Create(ctx, reset); err != nil {
+		log.Error("Failed to create password reset token: ", err)
+		return nil, err
+	}
+
+	// A forced reset also ends the user's current sessions.
+	if err := u.tokenRepo.RevokeUserRefreshTokens(ctx, user.Id); err != nil {
+		log.Error("Failed to revoke refresh tokens: ", err)
+		return nil, err
+	}
+
+	log.Info("Password reset issued")
+	return &model.PasswordReset{
+		Token:     token,
+		ExpiresAt: reset.ExpiresAt,
+	}, nil
+}
+
+func (u *UserUsecase) ResetPassword(ctx context.Context, in model.ResetPasswordInput) error {
+	if err := v.StructCtx(ctx, in); err != nil {
+		return err
+	}
+
+	errInvalid := model.NewFieldError("token", "", "reset token is invalid or expired")
+
+	reset, err := u.passwordResetRepo.FindByHash(ctx, helper.HashToken(in.Token))
+	if err != nil {
+		logrus.WithContext(ctx).Error("Failed to fetch password reset token: ", err)
+		return err
+	}
+
+	if reset == nil || reset.UsedAt != nil || time.Now().After(reset.ExpiresAt) {
+		return errInvalid
+	}
+
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
+		"user_id": reset.UserID,
+	})
+
+	// Check the policy before burning the token so a weak password can be
+	// retried with the same token.
+	if err := helper.ValidatePassword(in.NewPassword); err != nil {
+		return model.NewFieldError("new_password", "password", err.Error())
+	}
+
+	used, err := u.passwordResetRepo.MarkUsed(ctx, reset.ID)
+	if err != nil {
+		log.Error("Failed to mark password reset token used: ", err)
+		return err
+	}
+	if !used {
+		return errInvalid
+	}
+
+	if err := u.setPassword(ctx, reset.UserID, in.NewPassword); err != nil {
+		log.Error("Failed to reset password: ", err)
+		return err
+	}
+
+	log.Info("Password reset completed")
+	return nil
+}
+
+func (u *UserUsecase) setPassword(ctx context.Context, userID int64, password string) error {
+	if err := helper.ValidatePassword(password); err != nil {
+		return model.NewFieldError("new_password", "password", err.Error())
+	}
+
+	hashed, err := helper.HashRequestPassword(password)
+	if err != nil {
+		return err
+	}
+
+	if err := u.userRepo.UpdatePassword(ctx, userID, hashed); err != nil {
+		return err
+	}
+
+	return u.tokenRepo.RevokeUserRefreshTokens(ctx, userID)
+}
+
+// Unlock clears the failed login count and lockout of a user's account.
+func (u *UserUsecase) Unlock(ctx context.Context, id int64) error {
+	if !hasPermission(ctx, model.PermissionUserWrite) {
+		return errForbidden(model.PermissionUserWrite)
+	}
+
+	user, err := u.userRepo.FindByID(ctx, id)
+	if err != nil || user == nil {
+		return model.NewNotFoundError("user not found")
+	}
+
+	if err := u.checkManageUser(ctx, user); err != nil {
+		return err
+	}
+
+	if err := u.loginThrottle.Unlock(ctx, user.Username); err != nil {
+		logrus.WithContext(ctx).WithField("id", id).Error("Failed to unlock user: ", err)
+		return err
+	}
+
+	logrus.WithContext(ctx).WithField("id", id).Info("User unlocked")
+	return nil
+}
+
+func (u *UserUsecase) FindTrash(ctx context.Context, page model.PageRequest) ([]*model.User, *model.PageMeta, error) {
+	if !hasPermission(ctx, model.PermissionUserWrite) {
+		return nil, nil, errForbidden(model.PermissionUserWrite)
+	}
+
+	users, meta, err := u.userRepo.FindTrash(ctx, page)
+	if err != nil {
+		logrus.WithContext(ctx).Error("Failed to fetch deleted users: ", err)
+		return nil, nil, err
+	}
+
+	return users, meta, nil
+}
+
+func (u *UserUsecase) Restore(ctx context.Context, id int64) error {
+	if !hasPermission(ctx, model.PermissionUserWrite) {
+		return errForbidden(model.PermissionUserWrite)
+	}
+
+	if err := u.userRepo.Restore(ctx, id); err != nil {
+		logrus.WithContext(ctx).WithField("id", id).Error("Failed to restore user: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (u *UserUsecase) Purge(ctx context.Context, id int64) error {
+	if !hasPermission(ctx, model.PermissionTrashPurge) {
+		return errForbidden(model.PermissionTrashPurge)
+	}
+
+	if err := u.userRepo.Purge(ctx, id); err != nil {
+		logrus.WithContext(ctx).WithField("id", id).Error("Failed to purge user: ", err)
+		return err
+	}
+
+	logrus.WithContext(ctx).WithField("id", id).Info("Purged user")
+	return nil
+}
@@ -5,8 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/config"
 	"github.com/tubagusmf/log-troubleshoot-be/internal/helper"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/metrics"
 	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
 )
 
@@ -24,18 +28,25 @@ type WhatsAppConsumerUsecase struct {
 	projectRepo      model.IProjectRepository
 	locationRepo     model.ILocationRepository
 	troubleshootRepo model.ITroubleshootLogRepository
-	sheetRepo        model.ISpreadsheetRepository
+	troubleshoot     model.ITroubleshootLogUsecase
+	mediaJobRepo     model.IWhatsAppMediaJobRepository
 }
 
 func NewWhatsAppConsumerUsecase(
 	userRepo model.IUserRepository,
+	projectRepo model.IProjectRepository,
+	locationRepo model.ILocationRepository,
 	troubleshootRepo model.ITroubleshootLogRepository,
-	sheetRepo model.ISpreadsheetRepository,
+	troubleshoot model.ITroubleshootLogUsecase,
+	mediaJobRepo model.IWhatsAppMediaJobRepository,
 ) *WhatsAppConsumerUsecase {
 	return &WhatsAppConsumerUsecase{
 		userRepo:         userRepo,
+		projectRepo:      projectRepo,
+		locationRepo:     locationRepo,
 		troubleshootRepo: troubleshootRepo,
-		sheetRepo:        sheetRepo,
+		troubleshoot:     troubleshoot,
+		mediaJobRepo:     mediaJobRepo,
 	}
 }
 
@@ -75,37 +86,51 @@ func (u *WhatsAppConsumerUsecase) Consume(
 	payload model.WhatsAppWebhookRequest,
 ) error {
 
+	metrics.WhatsAppReceived.Inc()
+
+	// The gateway files reports into whichever project a message names. Its
+	// API key's scope comes from the memberships of whoever issued it, which
+	// say nothing about the group it relays, so the report is handled with
+	// access to every project.
+	ctx = model.WithGlobalScope(ctx)
+
 	parsed := helper.ParseWhatsAppReport(payload.Message)
 
 	if parsed.Project == "" || parsed.Issue == "" {
-		return errors.New("invalid report format")
+		metrics.WhatsAppRejected.WithLabelValues(metrics.RejectInvalidFormat).Inc()
+		return model.NewValidationError("invalid report format")
 	}
 
-	user, err := u.userRepo.FindByCodeName(ctx, parsed.CodeName)
-	if err != nil {
-		return fmt.Errorf("user not found: %w", err)
+	if maxItems := config.WhatsAppMediaMaxItems(); len(payload.Media) > maxItems {
+		metrics.WhatsAppRejected.WithLabelValues(metrics.RejectTooManyMedia).Inc()
+		return model.NewFieldError("media", "max", fmt.Sprintf("at most %d media items are accepted", maxItems))
 	}
-	if user == nil {
-		return errors.New("user is nil")
+
+	metrics.WhatsAppParsed.Inc()
+
+	user, err := u.userRepo.FindByCodeName(ctx, parsed.CodeName)
+	if err != nil || user == nil {
+		metrics.WhatsAppRejected.WithLabelValues(metrics.RejectUnknownUser).Inc()
+		return notFoundUnlessFailed(err, "user %q not found", parsed.CodeName)
 	}
 
 	project, err := u.projectRepo.FindByName(ctx, parsed.Project)
-	if err != nil {
-		return fmt.Errorf("project not found: %w", err)
-	}
-	if project == nil {
-		return errors.New("project is nil")
+	if err != nil || project == nil {
+		metrics.WhatsAppRejected.WithLabelValues(metrics.RejectUnknownProject).Inc()
+		return notFoundUnlessFailed(err, "project %q not found", parsed.Project)
 	}
 
 	location, err := u.locationRepo.FindByName(ctx, parsed.Station)
-	if err != nil {
-		return fmt.Errorf("location not found: %w", err)
-	}
-	if location == nil {
-		return errors.New("location is nil")
+	if err != nil || location == nil {
+		metrics.WhatsAppRejected.WithLabelValues(metrics.RejectUnknownLocation).Inc()
+		return notFoundUnlessFailed(err, "location %q not found", parsed.Station)
 	}
 
+	now := time.Now()
+
 	log := model.TroubleshootLog{
+		TroubleDate:     now,
+		TroubleTime:     now,
 		UserID:          &user.Id,
 		ProjectID:       &project.Id,
 		LocationID:      &location.Id,
@@ -115,12 +140,55 @@ func (u *WhatsAppConsumerUsecase) Consume(
 		Status:          "OPEN",
 		WhatsappSender:  payload.Sender,
 		WhatsappMessage: payload.Message,
+		// Appended to the spreadsheet by the sheet sync job.
+		SheetSyncPending: true,
 	}
 
-	fmt.Println("User:", user)
-	fmt.Println("Project:", project)
-	fmt.Println("Location:", location)
+	logrus.WithContext(ctx).WithFields(logrus.Fields{
+		"sender_hash": helper.HashSender(payload.Sender),
+		"user_id":     user.Id,
+		"project_id":  project.Id,
+		"location_id": location.Id,
+	}).Debug("Resolved WhatsApp report")
 
-	_, err = u.troubleshootRepo.Create(ctx, log)
+	created, err := u.troubleshootRepo.Create(ctx, log)
+	if err != nil {
+		metrics.WhatsAppRejected.WithLabelValues(metrics.RejectStoreFailed).Inc()
+		return err
+	}
+
+	metrics.WhatsAppTickets.Inc()
+
+	if err := u.troubleshoot.DetectRecurrence(ctx, created); err != nil {
+		logrus.WithContext(ctx).WithField("troubleshoot_log_id", created.ID).Warn("Failed to detect recurrence: ", err)
+	}
+
+	// The media is fetched and attached by the WhatsApp media job. The
+	// ticket is saved either way, a lost photo should not drop the report.
+	jobs := make([]*model.WhatsAppMediaJob, 0, len(payload.Media))
+	for i, media := range payload.Media {
+		jobs = append(jobs, &model.WhatsAppMediaJob{
+			TroubleshootLogID: created.ID,
+			MediaIndex:        i,
+			URL:               media.URL,
+			Base64:            media.Base64,
+			FileName:          media.FileName,
+			MimeType:          media.MimeType,
+		})
+	}
+
+	if err := u.mediaJobRepo.Create(ctx, jobs); err != nil {
+		logrus.WithContext(ctx).WithField("troubleshoot_log_id", created.ID).Error("Failed to queue WhatsApp media: ", err)
+	}
+
+	return nil
+}
+
+// notFoundUnlessFailed reports a record the message refers to as not found,
+// naming it, unless the lookup itself failed.
+func notFoundUnlessFailed(err error, format string, args ...interface{}) error {
+	if err == nil || errors.Is(err, model.ErrNotFound) {
+		return model.NewNotFoundError(format, args...)
+	}
 	return err
 }
@@ -0,0 +1,239 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/config"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/helper"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/metrics"
+	"github.com/tubagusmf/log-troubleshoot-be/internal/model"
+)
+
+const (
+	// mediaFetchBatch is how many queued media items one pass processes at
+	// most.
+	mediaFetchBatch = 20
+
+	// mediaFetchLease is how long a claimed job is left to the instance that
+	// claimed it before another instance takes it over. It covers the
+	// download timeout and the upload.
+	mediaFetchLease = 5 * time.Minute
+
+	// mediaFetchMaxAttempts is how often a job that keeps failing is tried.
+	mediaFetchMaxAttempts = 5
+)
+
+// WhatsAppMediaUsecase downloads the media queued by the WhatsApp consumer
+// and attaches it to the ticket in the background, so the webhook does not
+// wait on downloads, storage or thumbnails. Every instance runs it and the
+// claim keeps two of them from processing the same job.
+type WhatsAppMediaUsecase struct {
+	jobRepo     model.IWhatsAppMediaJobRepository
+	attachments model.IAttachmentUsecase
+	mediaClient *http.Client
+	interval    time.Duration
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewWhatsAppMediaUsecase(
+	jobRepo model.IWhatsAppMediaJobRepository,
+	attachments model.IAttachmentUsecase,
+) *WhatsAppMediaUsecase {
+	return &WhatsAppMediaUsecase{
+		jobRepo:     jobRepo,
+		attachments: attachments,
+		mediaClient: helper.NewPublicHTTPClient(30*time.Second, config.WhatsAppMediaAllowedHosts()),
+		interval:    config.WhatsAppMediaFetchInterval(),
+	}
+}
+
+// Start processes the queue right away and then every interval until Stop.
+func (w *WhatsAppMediaUsecase) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	w.done = make(chan struct{})
+
+	go func() {
+		defer close(w.done)
+
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			w.process(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop waits for the job being processed, the rest stay queued for the
+// next start.
+func (w *WhatsAppMediaUsecase) Stop() {
+	if w.cancel == nil {
+		return
+	}
+
+	w.cancel()
+	<-w.done
+}
+
+// process works through the oldest queued jobs until stop is cancelled. A
+// failed job does not end the pass, each item comes from its own URL.
+func (w *WhatsAppMediaUsecase) process(stop context.Context) {
+	ctx := context.Background()
+	log := logrus.WithContext(ctx).WithField("job", "whatsapp_media")
+
+	jobs, err := w.jobRepo.FindPending(ctx, mediaFetchBatch)
+	if err != nil {
+		log.Error("Failed to load queued WhatsApp media: ", err)
+		return
+	}
+
+	for _, job := range jobs {
+		if stop.Err() != nil {
+			return
+		}
+
+		claimed, err := w.jobRepo.Claim(ctx, job.ID, time.Now().Add(-mediaFetchLease))
+		if err != nil {
+			log.WithField("whatsapp_media_job_id", job.ID).Error("Failed to claim WhatsApp media: ", err)
+			return
+		}
+		if !claimed {
+			continue
+		}
+
+		w.finish(ctx, job, w.attach(ctx, job))
+	}
+}
+
+// finish records the outcome of a job. Domain errors, such as media that is
+// too large or of a type that is not allowed, will not go away on a retry,
+// neither will a ticket that was deleted meanwhile.
+func (w *WhatsAppMediaUsecase) finish(ctx context.Context, job *model.WhatsAppMediaJob, err error) {
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
+		"job":                   "whatsapp_media",
+		"whatsapp_media_job_id": job.ID,
+		"troubleshoot_log_id":   job.TroubleshootLogID,
+		"media_index":           job.MediaIndex,
+	})
+
+	if err == nil {
+		metrics.WhatsAppMedia.WithLabelValues("attached").Inc()
+		if err := w.jobRepo.Complete(ctx, job.ID); err != nil {
+			log.Error("Failed to remove finished WhatsApp media job: ", err)
+		}
+		return
+	}
+
+	var domainErr *model.DomainError
+	if errors.As(err, &domainErr) || job.Attempts+1 >= mediaFetchMaxAttempts {
+		metrics.WhatsAppMedia.WithLabelValues("failed").Inc()
+		log.Warn("Failed to attach WhatsApp media, giving up: ", err)
+		if err := w.jobRepo.Fail(ctx, job.ID, err.Error()); err != nil {
+			log.Error("Failed to mark WhatsApp media job failed: ", err)
+		}
+		return
+	}
+
+	metrics.WhatsAppMedia.WithLabelValues("retry").Inc()
+	log.Warn("Failed to attach WhatsApp media, will retry: ", err)
+	if err := w.jobRepo.Retry(ctx, job.ID, err.Error()); err != nil {
+		log.Error("Failed to release WhatsApp media job: ", err)
+	}
+}
+
+func (w *WhatsAppMediaUsecase) attach(ctx context.Context, job *model.WhatsAppMediaJob) error {
+	media := job.Media()
+
+	data, err := w.readMedia(ctx, media)
+	if err != nil {
+		return err
+	}
+
+	fileName := media.FileName
+	if fileName == "" {
+		fileName = fmt.Sprintf("whatsapp-%d", job.MediaIndex+1)
+		if exts, _ := mime.ExtensionsByType(media.MimeType); len(exts) > 0 {
+			fileName += exts[0]
+		}
+	}
+
+	_, err = w.attachments.Upload(ctx, model.UploadAttachmentInput{
+		TroubleshootLogID: job.TroubleshootLogID,
+		FileName:          fileName,
+		Source:            model.AttachmentSourceWhatsApp,
+		Data:              data,
+	})
+
+	return err
+}
+
+func (w *WhatsAppMediaUsecase) readMedia(ctx context.Context, media model.WhatsAppMedia) ([]byte, error) {
+	maxSize := config.AttachmentMaxSize()
+
+	if media.Base64 != "" {
+		encoded := media.Base64
+		// Accept data URIs such as "data:image/jpeg;base64,...".
+		if i := strings.Index(encoded, "base64,"); i >= 0 && strings.HasPrefix(encoded, "data:") {
+			encoded = encoded[i+len("base64,"):]
+		}
+
+		if int64(base64.StdEncoding.DecodedLen(len(encoded))) > maxSize+2 {
+			return nil, model.NewValidationError("media is too large")
+		}
+
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, model.NewValidationError("media is not valid base64: %v", err)
+		}
+
+		return data, nil
+	}
+
+	if media.URL == "" {
+		return nil, model.NewValidationError("media has no url or base64 content")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, media.URL, nil)
+	if err != nil {
+		return nil, model.NewValidationError("invalid media url: %v", err)
+	}
+
+	resp, err := w.mediaClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download media: status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(data)) > maxSize {
+		return nil, model.NewValidationError("media is too large")
+	}
+
+	return data, nil
+}
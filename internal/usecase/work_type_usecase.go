@@ -2,7 +2,6 @@ package usecase
 
 import (
 	"context"
-	"errors"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -19,22 +18,22 @@ func NewWorkTypeUsecase(repo model.IWorkTypeRepository) model.IWorkTypeUsecase {
 	}
 }
 
-func (w *WorkTypeUsecase) FindAll(ctx context.Context, workType model.WorkType) ([]*model.WorkType, error) {
-	log := logrus.WithFields(logrus.Fields{
+func (w *WorkTypeUsecase) FindAll(ctx context.Context, workType model.WorkType, page model.PageRequest) ([]*model.WorkType, *model.PageMeta, error) {
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
 		"filter": workType,
 	})
 
-	data, err := w.workTypeRepo.FindAll(ctx, workType)
+	data, meta, err := w.workTypeRepo.FindAll(ctx, workType, page)
 	if err != nil {
 		log.Error("Failed to fetch work types: ", err)
-		return nil, err
+		return nil, nil, err
 	}
 
-	return data, nil
+	return data, meta, nil
 }
 
 func (w *WorkTypeUsecase) FindByID(ctx context.Context, id int64) (*model.WorkType, error) {
-	log := logrus.WithFields(logrus.Fields{
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
 		"id": id,
 	})
 
@@ -46,19 +45,19 @@ func (w *WorkTypeUsecase) FindByID(ctx context.Context, id int64) (*model.WorkTy
 
 	if workType == nil {
 		log.Error("Work type not found")
-		return nil, errors.New("work type not found")
+		return nil, model.NewNotFoundError("work type not found")
 	}
 
 	return workType, nil
 }
 
 func (w *WorkTypeUsecase) Create(ctx context.Context, in model.CreateWorkTypeInput) (*model.WorkType, error) {
-	log := logrus.WithFields(logrus.Fields{
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
 		"in": in,
 	})
 
-	if !isAdmin(ctx) {
-		return nil, errors.New("forbidden: admin only")
+	if !hasPermission(ctx, model.PermissionMasterDataWrite) {
+		return nil, errForbidden(model.PermissionMasterDataWrite)
 	}
 
 	if err := v.StructCtx(ctx, in); err != nil {
@@ -80,20 +79,20 @@ func (w *WorkTypeUsecase) Create(ctx context.Context, in model.CreateWorkTypeInp
 
 	if created == nil {
 		log.Error("Work type not created")
-		return nil, errors.New("work type not created")
+		return nil, model.NewInternalError("work type not created")
 	}
 
 	return created, nil
 }
 
 func (w *WorkTypeUsecase) Update(ctx context.Context, id int64, in model.UpdateWorkTypeInput) error {
-	log := logrus.WithFields(logrus.Fields{
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
 		"id": id,
 		"in": in,
 	})
 
-	if !isAdmin(ctx) {
-		return errors.New("forbidden: admin only")
+	if !hasPermission(ctx, model.PermissionMasterDataWrite) {
+		return errForbidden(model.PermissionMasterDataWrite)
 	}
 
 	if err := v.StructCtx(ctx, in); err != nil {
@@ -109,7 +108,7 @@ func (w *WorkTypeUsecase) Update(ctx context.Context, id int64, in model.UpdateW
 
 	if existing == nil || (existing.DeletedAt != nil && !existing.DeletedAt.IsZero()) {
 		log.Error("Work type is deleted or does not exist")
-		return errors.New("work type is deleted or does not exist")
+		return model.NewNotFoundError("work type is deleted or does not exist")
 	}
 
 	workType := model.WorkType{
@@ -127,32 +126,71 @@ func (w *WorkTypeUsecase) Update(ctx context.Context, id int64, in model.UpdateW
 	return nil
 }
 
-func (w *WorkTypeUsecase) Delete(ctx context.Context, id int64) error {
-	log := logrus.WithFields(logrus.Fields{
+// Delete refuses while open tickets still use the work type, unless replaceWith
+// names another work type to move them to.
+func (w *WorkTypeUsecase) Delete(ctx context.Context, id int64, replaceWith *int64) error {
+	log := logrus.WithContext(ctx).WithFields(logrus.Fields{
 		"id": id,
 	})
 
-	if !isAdmin(ctx) {
-		return errors.New("forbidden: admin only")
+	if !hasPermission(ctx, model.PermissionMasterDataWrite) {
+		return errForbidden(model.PermissionMasterDataWrite)
 	}
 
-	workType, err := w.workTypeRepo.FindByID(ctx, id)
+	moved, err := w.workTypeRepo.Delete(ctx, id, replaceWith)
 	if err != nil {
-		log.Error("Failed to fetch work type: ", err)
+		log.Error("Failed to delete work type: ", err)
 		return err
 	}
 
-	if workType == nil {
-		log.Error("Work type not found")
-		return errors.New("work type not found")
+	if replaceWith != nil {
+		log.WithFields(logrus.Fields{
+			"replace_with": *replaceWith,
+			"tickets":      moved,
+		}).Info("Reassigned open tickets before delete")
+	}
+
+	log.Info("Successfully deleted work type with ID: ", id)
+	return nil
+}
+
+func (w *WorkTypeUsecase) FindTrash(ctx context.Context, page model.PageRequest) ([]*model.WorkType, *model.PageMeta, error) {
+	if !hasPermission(ctx, model.PermissionMasterDataWrite) {
+		return nil, nil, errForbidden(model.PermissionMasterDataWrite)
 	}
 
-	err = w.workTypeRepo.Delete(ctx, id)
+	workTypes, meta, err := w.workTypeRepo.FindTrash(ctx, page)
 	if err != nil {
-		log.Error("Failed to delete work type: ", err)
+		logrus.WithContext(ctx).Error("Failed to fetch deleted work types: ", err)
+		return nil, nil, err
+	}
+
+	return workTypes, meta, nil
+}
+
+func (w *WorkTypeUsecase) Restore(ctx context.Context, id int64) error {
+	if !hasPermission(ctx, model.PermissionMasterDataWrite) {
+		return errForbidden(model.PermissionMasterDataWrite)
+	}
+
+	if err := w.workTypeRepo.Restore(ctx, id); err != nil {
+		logrus.WithContext(ctx).WithField("id", id).Error("Failed to restore work type: ", err)
 		return err
 	}
 
-	log.Info("Successfully deleted work type with ID: ", id)
+	return nil
+}
+
+func (w *WorkTypeUsecase) Purge(ctx context.Context, id int64) error {
+	if !hasPermission(ctx, model.PermissionTrashPurge) {
+		return errForbidden(model.PermissionTrashPurge)
+	}
+
+	if err := w.workTypeRepo.Purge(ctx, id); err != nil {
+		logrus.WithContext(ctx).WithField("id", id).Error("Failed to purge work type: ", err)
+		return err
+	}
+
+	logrus.WithContext(ctx).WithField("id", id).Info("Purged work type")
 	return nil
 }